@@ -1,6 +1,6 @@
 // Copyright (c) 2022 Braden Nicholson
 
-import {reactive} from "vue";
+import {reactive, toRaw} from "vue";
 import {Nexus, Target} from "@/views/terminal/nexus";
 import type {
     Attribute,
@@ -96,7 +96,16 @@ const remote = reactive<Remote>({
 
 const state = reactive({
     ready: false,
-    ws: {} as WebSocket
+    ws: {} as WebSocket,
+    // The last mutation received, used to resume after reconnecting
+    position: {epoch: 0, sequence: 0} as Position,
+    // The highest sequence received on the current connection and whether its subscription was acknowledged
+    received: 0,
+    subscribed: false,
+    // Reconnecting waits longer after each failed attempt, closing stops it
+    attempts: 0,
+    retry: 0,
+    closing: false
 })
 
 interface Message {
@@ -105,10 +114,17 @@ interface Message {
     status: string;
     operation: string;
     body: any;
+    sequence: number;
+}
+
+interface Position {
+    epoch: number;
+    sequence: number;
 }
 
 
 function connect(): void {
+    state.closing = false
     state.ws = new WebSocket(connectionString())
     state.ws.onopen = onOpen
     state.ws.onclose = onClose
@@ -117,6 +133,9 @@ function connect(): void {
 }
 
 function disconnect(): void {
+    state.closing = true
+    clearTimeout(state.retry)
+    state.retry = 0
     if (state.ready) {
         state.ws.close(1001, "Disconnecting")
         remote.connected = false
@@ -124,21 +143,43 @@ function disconnect(): void {
     }
 }
 
+// reconnect connects again after a delay that doubles with each failed attempt, up to a minute
+function reconnect(): void {
+    if (state.closing || state.retry) return
+    let delay = Math.min(1000 * 2 ** state.attempts, 60000)
+    state.attempts++
+    remote.connecting = true
+    state.retry = setTimeout(() => {
+        state.retry = 0
+        connect()
+    }, delay)
+}
+
 export {
     connect,
     disconnect
 }
 
 function onOpen(_: Event) {
+    // Nothing is sent until the connection subscribes, the remote follows every element and resumes from the
+    // last mutation it received if the server still has the ones that followed
+    state.ws.send(JSON.stringify({id: "remote", operation: "subscribe", body: {resume: state.position}}))
+    state.received = 0
+    state.subscribed = false
+    state.attempts = 0
     state.ready = true
     remote.connected = true
     remote.connecting = false
 }
 
-function onClose(_: CloseEvent) {
+function onClose(event: CloseEvent) {
+    // A connection that was already replaced has nothing left to do
+    if (event.target !== toRaw(state.ws)) return
     state.ready = false
     remote.connected = false
     remote.connecting = false
+    // The server closes endpoints that fall behind, they resubscribe once they reconnect
+    reconnect()
 }
 
 function onMessage(event: MessageEvent) {
@@ -149,6 +190,19 @@ function onMessage(event: MessageEvent) {
         return
     }
     if (msg.status !== "success") return;
+    if (msg.operation === "ack" && msg.body.operation === "subscribe") {
+        let result = msg.body.result as Position
+        // Mutations can arrive ahead of the acknowledgement, the position never moves back past them
+        state.position = {epoch: result.epoch, sequence: Math.max(result.sequence, state.received)}
+        state.subscribed = true
+        return
+    }
+    if (msg.sequence > state.received) {
+        state.received = msg.sequence
+        if (state.subscribed) {
+            state.position.sequence = state.received
+        }
+    }
     let operation: string = msg.operation
     let target: Target = operation as Target
     handleMessage(target, msg.body)
@@ -185,7 +239,7 @@ function handleMessage(target: Target, data: any) {
     let dx = 0;
     switch (target) {
         case Target.Close:
-            setTimeout(connect, 5000)
+            reconnect()
             return
 
         case Target.Metadata:
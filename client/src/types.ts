@@ -8,8 +8,19 @@ export interface Trigger {
     id: string;
     name: string;
     type: string;
+    module: string;
     description: string;
     lastTrigger: string;
+    schedule: Schedule;
+    nextFire: string;
+}
+
+export interface Schedule {
+    kind: string;
+    expression: string;
+    interval: number;
+    offset: number;
+    policy: string;
 }
 
 
@@ -47,9 +58,61 @@ export interface SubRoutine {
     lastRun: string;
     triggerId: string;
     macros: Macro[];
+    scenes: Scene[];
+    steps: Step[];
+    policy: string;
+    retries: number;
     description: string;
 }
 
+export interface Step {
+    kind: string;
+    target: string;
+    delay: number;
+    condition?: RuleCondition;
+    timeout: number;
+    then: Step[];
+    else: Step[];
+    policy: string;
+    retries: number;
+}
+
+export interface RuleCondition {
+    entity: string;
+    key: string;
+    mode: string;
+    value: string;
+}
+
+export interface StepResult {
+    path: string;
+    kind: string;
+    target: string;
+    status: string;
+    attempts: number;
+    error: string;
+    started: string;
+    finished: string;
+}
+
+export interface SceneValue {
+    entity: string;
+    key: string;
+    value: string;
+}
+
+export interface Scene {
+    created: string;
+    updated: string;
+    id: string;
+    name: string;
+    description: string;
+    zone: string;
+    values: SceneValue[];
+    transition: number;
+    lastApplied: string;
+}
+
 export interface Macro {
     created: string;
     updated: string;
@@ -166,6 +229,17 @@ export interface ModuleVariable {
     name: string;
     description: string;
     default: string;
+    type: string;
+    required: boolean;
+    options?: string[];
+    pattern?: string;
+    min?: number;
+    max?: number;
+}
+
+export interface ModuleGrant {
+    capability: string;
+    targets?: string[];
 }
 
 export interface Module {
@@ -176,13 +250,16 @@ export interface Module {
     uuid: string
     config: string
     variables: string
+    grants: string
     path: string
     type: string
     enabled: boolean
     description: string
     version: string
+    previous: string
     author: string
     state: string
+    runtime: string
 }
 
 export interface Timing {
@@ -285,6 +362,9 @@ export interface Log {
     event: string
     time: string
     message: string
+    module: string
+    entity: string
+    correlation: string
     id: string
 }
 
@@ -359,6 +439,17 @@ export interface Attribute {
     key: string;
     type: string;
     order: number;
+    schema: AttributeSchema;
+    state: "pending" | "confirmed" | "failed" | "timeout";
+    timeout: number;
+}
+
+export interface AttributeSchema {
+    min: number;
+    max: number;
+    step: number;
+    enum: string[];
+    unit: string;
 }
 
 export interface AttributeLog {
@@ -494,3 +585,48 @@ declare interface Persistent {
 }
 
 
+
+export interface InvocationRequest {
+    entity: string;
+    key: string;
+    value: string;
+    error: string;
+    time: string;
+}
+
+export interface Invocation {
+    created: string;
+    updated: string;
+    id: string;
+    kind: string;
+    target: string;
+    name: string;
+    source: string;
+    sourceId: string;
+    parent: string;
+    status: string;
+    requests: InvocationRequest[];
+    steps: StepResult[];
+    started: string;
+    finished: string;
+    duration: number;
+    error: string;
+}
+
+export interface Action {
+    created: string;
+    updated: string;
+    id: string;
+    name: string;
+    triggerId: string;
+    kind: string;
+    entities: string[];
+    attribute: string;
+    request: string;
+    target: string;
+    method: string;
+    url: string;
+    headers: { [key: string]: string };
+    body: string;
+    lastRun: string;
+}
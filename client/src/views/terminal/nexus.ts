@@ -64,6 +64,8 @@ export class Nexus {
         this.state = NexusState.Connecting
         this.ws = new WebSocket(connectionString())
         this.ws.onopen = (event: Event) => {
+            // Nothing is sent until the connection subscribes, the terminal follows every element
+            this.ws.send(JSON.stringify({id: "terminal", operation: "subscribe", body: {}}))
             open()
             this.state = NexusState.Connected
         }
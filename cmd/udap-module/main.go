@@ -0,0 +1,209 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// udap-module packs module packages, and installs and rolls back modules through the api of a running system.
+// The api is reached at UDAP_URL (http://localhost:3020 by default) using the token in UDAP_TOKEN.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/plugin/archive"
+)
+
+// installTimeout is how long install waits for the system to build and load a module
+const installTimeout = time.Minute * 2
+
+const usage = `usage:
+  udap-module pack <dir> [output]    write the module in dir to a package
+  udap-module install <package>      install or upgrade a module from a package
+  udap-module rollback <module>      restore the version installed before the current one`
+
+type status struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Previous string `json:"previous"`
+	State    string `json:"state"`
+	Running  bool   `json:"running"`
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "pack":
+		out := ""
+		if len(os.Args) > 3 {
+			out = os.Args[3]
+		}
+		err = pack(os.Args[2], out)
+	case "install":
+		err = install(os.Args[2])
+	case "rollback":
+		err = rollback(os.Args[2])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// pack writes a module directory to <name>-<version>.tar.gz unless another output is given
+func pack(dir string, out string) error {
+	manifest, err := archive.ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		out = fmt.Sprintf("%s-%s.tar.gz", manifest.Name, manifest.Version)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = archive.Pack(dir, f)
+	if err != nil {
+		_ = os.Remove(out)
+		return err
+	}
+	fmt.Printf("Packed %s %s to %s\n", manifest.Name, manifest.Version, filepath.Clean(out))
+	return nil
+}
+
+// install uploads a package and waits for the system to report the outcome
+func install(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	body, err := request(http.MethodPost, "/modules/install", data)
+	if err != nil {
+		return err
+	}
+	manifest := domain.Manifest{}
+	err = json.Unmarshal(body, &manifest)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installing %s %s...\n", manifest.Name, manifest.Version)
+	deadline := time.Now().Add(installTimeout)
+	// A module that fails to load is briefly in the error state before it is reverted
+	failed := 0
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+		current, err := lookup(manifest.Name)
+		if err != nil {
+			continue
+		}
+		if current.Version != manifest.Version {
+			if current.Previous == manifest.Version {
+				return fmt.Errorf("%s %s failed to load, reverted to %s", manifest.Name, manifest.Version,
+					current.Version)
+			}
+			continue
+		}
+		switch current.State {
+		case "running":
+			fmt.Printf("Installed %s %s\n", manifest.Name, manifest.Version)
+			return nil
+		case "error":
+			failed++
+			if failed < 3 {
+				continue
+			}
+			return fmt.Errorf("%s %s was installed but failed to start", manifest.Name, manifest.Version)
+		case "idle":
+			if !current.Running {
+				fmt.Printf("Installed %s %s (not running)\n", manifest.Name, manifest.Version)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s %s did not finish installing within %s", manifest.Name, manifest.Version,
+		installTimeout)
+}
+
+// rollback restores the previous version of a module and waits for it to be installed
+func rollback(module string) error {
+	current, err := lookup(module)
+	if err != nil {
+		return err
+	}
+	_, err = request(http.MethodPost, fmt.Sprintf("/modules/%s/rollback", module), nil)
+	if err != nil {
+		return err
+	}
+	target, running := current.Previous, current.Running
+	fmt.Printf("Rolling back %s %s to %s...\n", module, current.Version, target)
+	deadline := time.Now().Add(installTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+		current, err = lookup(module)
+		if err != nil || current.Version != target {
+			continue
+		}
+		if !running || current.State == "running" {
+			fmt.Printf("Rolled back %s to %s\n", module, target)
+			return nil
+		}
+		if current.State == "error" {
+			return fmt.Errorf("%s %s failed to start", module, target)
+		}
+	}
+	return fmt.Errorf("%s did not finish rolling back within %s", module, installTimeout)
+}
+
+// lookup reads the installed version and state of a module
+func lookup(module string) (status, error) {
+	current := status{}
+	body, err := request(http.MethodGet, fmt.Sprintf("/modules/%s", module), nil)
+	if err != nil {
+		return current, err
+	}
+	err = json.Unmarshal(body, &current)
+	return current, err
+}
+
+// request makes an authenticated request to the api
+func request(method string, path string, data []byte) ([]byte, error) {
+	base := os.Getenv("UDAP_URL")
+	if base == "" {
+		base = "http://localhost:3020"
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(base, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("UDAP_TOKEN"))
+	if data != nil {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	client := http.Client{Timeout: time.Second * 30}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
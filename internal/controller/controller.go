@@ -3,6 +3,7 @@
 package controller
 
 import (
+	"udap/internal/core/bus"
 	"udap/internal/core/domain"
 	"udap/internal/core/ports"
 	"udap/internal/pulse"
@@ -23,7 +24,11 @@ type Controller struct {
 	Triggers      ports.TriggerService
 	SubRoutines   ports.SubRoutineService
 	Actions       ports.ActionService
-	RX            chan<- domain.Mutation
+	Rules         ports.RuleService
+	Holds         ports.HoldService
+	Scenes        ports.SceneService
+	Invocations   ports.InvocationService
+	Bus           *bus.Bus
 }
 
 type CoreModule interface {
@@ -31,94 +36,88 @@ type CoreModule interface {
 	EmitAll() error
 }
 
-func NewController(resp chan domain.Mutation) (*Controller, error) {
+func NewController(events *bus.Bus) (*Controller, error) {
 	c := &Controller{
-		RX: resp,
+		Bus: events,
 	}
 	return c, nil
 }
 
-func (c *Controller) EmitAll() error {
-
-	err := c.Entities.EmitAll()
-	if err != nil {
-		return err
-	}
-
-	err = c.Zones.EmitAll()
-	if err != nil {
-		return err
-	}
-
-	err = c.Attributes.EmitAll()
-	if err != nil {
-		return err
-	}
-
-	err = c.Modules.EmitAll()
-	if err != nil {
-		return err
-	}
-
-	err = c.Endpoints.EmitAll()
-	if err != nil {
-		return err
-	}
-
-	err = c.Users.EmitAll()
-	if err != nil {
-		return err
-	}
-
-	//err = c.Networks.EmitAll()
-	//if err != nil {
-	//	return err
-	//}
-
-	//err = c.Devices.EmitAll()
-	//if err != nil {
-	//	return err
-	//}
+// observable is a service whose elements endpoints can follow, operation is the operation of its mutations
+type observable struct {
+	operation  string
+	observable domain.Observable
+}
 
-	err = c.Notifications.EmitAll()
-	if err != nil {
-		return err
+// observables lists the services endpoints can follow, in the order their snapshots are sent
+func (c *Controller) observables() []observable {
+	return []observable{
+		{"entity", c.Entities},
+		{"zone", c.Zones},
+		{"attribute", c.Attributes},
+		{"module", c.Modules},
+		{"endpoint", c.Endpoints},
+		{"user", c.Users},
+		{"notification", c.Notifications},
+		{"log", c.Logs},
+		{"macro", c.Macros},
+		{"trigger", c.Triggers},
+		{"subroutine", c.SubRoutines},
+		{"action", c.Actions},
+		{"rule", c.Rules},
+		{"hold", c.Holds},
+		{"scene", c.Scenes},
+		{"invocation", c.Invocations},
 	}
+}
 
-	//err = c.Logs.EmitAll()
-	//if err != nil {
-	//	return err
-	//}
-
-	err = c.Macros.EmitAll()
-	if err != nil {
-		return err
+// Snapshot describes the current state of the elements of the given operations, or of every operation when none
+// are given
+func (c *Controller) Snapshot(operations []string) ([]domain.Mutation, error) {
+	wanted := func(operation string) bool {
+		if len(operations) == 0 {
+			return true
+		}
+		for _, o := range operations {
+			if o == operation {
+				return true
+			}
+		}
+		return false
 	}
-
-	err = c.Triggers.EmitAll()
-	if err != nil {
-		return err
+	var snapshot []domain.Mutation
+	for _, o := range c.observables() {
+		if o.observable == nil || !wanted(o.operation) {
+			continue
+		}
+		mutations, err := o.observable.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		snapshot = append(snapshot, mutations...)
 	}
-
-	err = c.SubRoutines.EmitAll()
-	if err != nil {
-		return err
+	if wanted("timing") {
+		timings := pulse.Timings.AllTimings()
+		for s, proc := range timings {
+			snapshot = append(snapshot, domain.Mutation{
+				Status:    "update",
+				Operation: "timing",
+				Body:      proc,
+				Id:        s,
+			})
+		}
 	}
+	return snapshot, nil
+}
 
-	err = c.Actions.EmitAll()
+// EmitAll publishes the current state of every element to the bus
+func (c *Controller) EmitAll() error {
+	snapshot, err := c.Snapshot(nil)
 	if err != nil {
 		return err
 	}
-
-	timings := pulse.Timings.AllTimings()
-	for s, proc := range timings {
-		c.RX <- domain.Mutation{
-			Status:    "update",
-			Operation: "timing",
-			Body:      proc,
-			Id:        s,
-		}
+	for _, mutation := range snapshot {
+		c.Bus.Publish(mutation)
 	}
-
 	return nil
 }
@@ -0,0 +1,146 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package bus delivers mutations from the services that publish them to the parts of the system that subscribe
+// to them. Each subscriber has its own bounded queue, so a slow subscriber never holds up a publisher or other
+// subscribers; what happens when a queue is full is decided by the subscriber's policy.
+package bus
+
+import (
+	"sort"
+	"sync"
+	"udap/internal/core/domain"
+)
+
+// Topic is the kind of a mutation, it matches the mutation's operation
+type Topic string
+
+const (
+	ATTRIBUTE    Topic = "attribute"
+	ATTRIBUTELOG Topic = "attributelog"
+	ENTITY       Topic = "entity"
+	MODULE       Topic = "module"
+	TRIGGER      Topic = "trigger"
+	ZONE         Topic = "zone"
+	DEVICE       Topic = "device"
+	NETWORK      Topic = "network"
+	ENDPOINT     Topic = "endpoint"
+	USER         Topic = "user"
+	LOG          Topic = "log"
+	TIMING       Topic = "timing"
+)
+
+// Policy decides what happens to a mutation published to a subscriber whose queue is full
+type Policy string
+
+const (
+	// DROP discards the oldest queued mutation to make room
+	DROP Policy = "drop-oldest"
+	// COALESCE replaces a queued mutation of the same topic and id, so only the latest state of each element
+	// is delivered. The oldest mutation is discarded if none can be replaced.
+	COALESCE Policy = "coalesce"
+	// BLOCK holds the publisher until the subscriber makes room
+	BLOCK Policy = "block"
+	// UNBOUNDED never drops a mutation or holds the publisher, the queue grows for as long as the subscriber falls
+	// behind. It suits subscribers that must see every change but may wait on the database to handle one.
+	UNBOUNDED Policy = "unbounded"
+)
+
+// defaultSize is the queue size of subscribers that do not choose one
+const defaultSize = 256
+
+// Options configure a subscription, a subscription with no topics receives every topic
+type Options struct {
+	Topics []Topic
+	Size   int
+	Policy Policy
+}
+
+// Stats reports the activity of a subscription
+type Stats struct {
+	Name      string  `json:"name"`
+	Topics    []Topic `json:"topics"`
+	Policy    Policy  `json:"policy"`
+	Size      int     `json:"size"`
+	Queued    int     `json:"queued"`
+	Delivered uint64  `json:"delivered"`
+	Dropped   uint64  `json:"dropped"`
+	Coalesced uint64  `json:"coalesced"`
+}
+
+// Bus routes published mutations to the queues of the subscriptions that want them
+type Bus struct {
+	subscriptions map[*Subscription]bool
+	mutex         sync.RWMutex
+}
+
+// New creates an empty bus
+func New() *Bus {
+	return &Bus{
+		subscriptions: map[*Subscription]bool{},
+	}
+}
+
+// Publish queues a mutation for each subscriber of its topic. Publish only waits for subscribers with the
+// BLOCK policy.
+func (b *Bus) Publish(mutation domain.Mutation) {
+	topic := Topic(mutation.Operation)
+	b.mutex.RLock()
+	var subscribed []*Subscription
+	for subscription := range b.subscriptions {
+		if subscription.wants(topic) {
+			subscribed = append(subscribed, subscription)
+		}
+	}
+	b.mutex.RUnlock()
+	for _, subscription := range subscribed {
+		subscription.push(mutation)
+	}
+}
+
+// Subscribe creates a subscription, mutations are received from its channel until it is closed
+func (b *Bus) Subscribe(name string, options Options) *Subscription {
+	if options.Size <= 0 {
+		options.Size = defaultSize
+	}
+	if options.Policy == "" {
+		options.Policy = DROP
+	}
+	s := &Subscription{
+		bus:     b,
+		name:    name,
+		options: options,
+		topics:  map[Topic]bool{},
+		index:   map[string]int{},
+		out:     make(chan domain.Mutation),
+		done:    make(chan struct{}),
+	}
+	s.ready = sync.NewCond(&s.mutex)
+	for _, topic := range options.Topics {
+		s.topics[topic] = true
+	}
+	b.mutex.Lock()
+	b.subscriptions[s] = true
+	b.mutex.Unlock()
+	go s.deliver()
+	return s
+}
+
+// Stats reports the activity of every subscription, ordered by name
+func (b *Bus) Stats() []Stats {
+	b.mutex.RLock()
+	stats := make([]Stats, 0, len(b.subscriptions))
+	for subscription := range b.subscriptions {
+		stats = append(stats, subscription.Stats())
+	}
+	b.mutex.RUnlock()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+func (b *Bus) remove(s *Subscription) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.subscriptions, s)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package bus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"udap/internal/core/domain"
+)
+
+func mutation(topic Topic, id string, body any) domain.Mutation {
+	return domain.Mutation{Status: "update", Operation: string(topic), Id: id, Body: body}
+}
+
+// drain receives queued mutations until none arrive for a moment
+func drain(s *Subscription) []domain.Mutation {
+	var received []domain.Mutation
+	for {
+		select {
+		case m := <-s.C():
+			received = append(received, m)
+		case <-time.After(time.Millisecond * 50):
+			return received
+		}
+	}
+}
+
+func TestBus_Topics(t *testing.T) {
+	b := New()
+	attributes := b.Subscribe("attributes", Options{Topics: []Topic{ATTRIBUTE}})
+	all := b.Subscribe("all", Options{})
+	defer attributes.Close()
+	defer all.Close()
+
+	b.Publish(mutation(ATTRIBUTE, "a", 1))
+	b.Publish(mutation(ENTITY, "e", 1))
+
+	if got := drain(attributes); len(got) != 1 || got[0].Id != "a" {
+		t.Errorf("attribute subscriber received %v", got)
+	}
+	if got := drain(all); len(got) != 2 {
+		t.Errorf("subscriber to every topic received %d mutations, want 2", len(got))
+	}
+}
+
+func TestBus_Policies(t *testing.T) {
+	b := New()
+	dropping := b.Subscribe("drop", Options{Size: 4, Policy: DROP})
+	coalescing := b.Subscribe("coalesce", Options{Size: 4, Policy: COALESCE})
+	defer dropping.Close()
+	defer coalescing.Close()
+
+	// Neither subscriber is reading, so publishing must not wait on them
+	published := 0
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		b.Publish(mutation(ATTRIBUTE, fmt.Sprintf("%d", i%2), i))
+		published++
+	}
+	if time.Since(start) > time.Millisecond*100 {
+		t.Errorf("publishing waited on subscribers that were not reading")
+	}
+
+	got := drain(dropping)
+	stats := dropping.Stats()
+	if uint64(len(got))+stats.Dropped != uint64(published) || got[len(got)-1].Body != 19 {
+		t.Errorf("drop policy delivered %d and dropped %d of %d, last %v", len(got), stats.Dropped, published,
+			got[len(got)-1].Body)
+	}
+
+	got = drain(coalescing)
+	stats = coalescing.Stats()
+	if stats.Coalesced == 0 || got[len(got)-1].Body != 19 || len(got) > 3 {
+		t.Errorf("coalesce policy delivered %v, %d coalesced", got, stats.Coalesced)
+	}
+}
+
+func TestBus_Unbounded(t *testing.T) {
+	b := New()
+	unbounded := b.Subscribe("unbounded", Options{Size: 1, Policy: UNBOUNDED})
+	defer unbounded.Close()
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		b.Publish(mutation(ATTRIBUTE, "a", i))
+	}
+	if time.Since(start) > time.Millisecond*100 {
+		t.Errorf("publishing waited on an unbounded subscriber that was not reading")
+	}
+	got := drain(unbounded)
+	if len(got) != 20 || got[0].Body != 0 || got[19].Body != 19 || unbounded.Stats().Dropped != 0 {
+		t.Errorf("unbounded subscriber received %d of 20", len(got))
+	}
+}
+
+func TestBus_Block(t *testing.T) {
+	b := New()
+	blocking := b.Subscribe("block", Options{Size: 1, Policy: BLOCK})
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 10; i++ {
+			b.Publish(mutation(ATTRIBUTE, "a", i))
+		}
+		done <- true
+	}()
+	select {
+	case <-done:
+		t.Fatalf("publishing to a full blocking subscriber should wait")
+	case <-time.After(time.Millisecond * 50):
+	}
+	got := drain(blocking)
+	<-done
+	got = append(got, drain(blocking)...)
+	if len(got) != 10 || blocking.Stats().Dropped != 0 {
+		t.Errorf("blocking subscriber received %d of 10", len(got))
+	}
+	blocking.Close()
+	if _, ok := <-blocking.C(); ok {
+		t.Errorf("closing a subscription should close its channel")
+	}
+	if len(b.Stats()) != 0 {
+		t.Errorf("closed subscriptions should be removed from the bus")
+	}
+}
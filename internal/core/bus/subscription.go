@@ -0,0 +1,144 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"udap/internal/core/domain"
+)
+
+// entry is a queued mutation, the key identifies the element it describes for coalescing
+type entry struct {
+	mutation domain.Mutation
+	key      string
+}
+
+// Subscription is a subscriber's queue of mutations
+type Subscription struct {
+	bus     *Bus
+	name    string
+	options Options
+	topics  map[Topic]bool
+	// queue holds the mutations waiting to be delivered, index finds the queued entry of a key by its position
+	// counted from the first mutation ever queued, which is base positions before the head of the queue
+	queue     []entry
+	index     map[string]int
+	base      int
+	closed    bool
+	out       chan domain.Mutation
+	done      chan struct{}
+	delivered uint64
+	dropped   uint64
+	coalesced uint64
+	ready     *sync.Cond
+	mutex     sync.Mutex
+}
+
+// C provides the channel mutations are delivered on, it is closed when the subscription is closed
+func (s *Subscription) C() <-chan domain.Mutation {
+	return s.out
+}
+
+// Close stops the subscription, queued mutations are discarded
+func (s *Subscription) Close() {
+	s.bus.remove(s)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+	s.ready.Broadcast()
+}
+
+// Stats reports the activity of the subscription
+func (s *Subscription) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return Stats{
+		Name:      s.name,
+		Topics:    s.options.Topics,
+		Policy:    s.options.Policy,
+		Size:      s.options.Size,
+		Queued:    len(s.queue),
+		Delivered: atomic.LoadUint64(&s.delivered),
+		Dropped:   s.dropped,
+		Coalesced: s.coalesced,
+	}
+}
+
+func (s *Subscription) wants(topic Topic) bool {
+	return len(s.topics) == 0 || s.topics[topic]
+}
+
+// push queues a mutation according to the subscription's policy
+func (s *Subscription) push(mutation domain.Mutation) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	key := mutation.Operation + ":" + mutation.Id
+	if s.options.Policy == COALESCE {
+		if position, ok := s.index[key]; ok {
+			s.queue[position-s.base].mutation = mutation
+			s.coalesced++
+			return
+		}
+	}
+	for len(s.queue) >= s.options.Size && s.options.Policy != UNBOUNDED {
+		if s.options.Policy == BLOCK {
+			s.ready.Wait()
+			if s.closed {
+				return
+			}
+			continue
+		}
+		s.pop()
+		s.dropped++
+	}
+	s.queue = append(s.queue, entry{mutation: mutation, key: key})
+	if s.options.Policy == COALESCE {
+		s.index[key] = s.base + len(s.queue) - 1
+	}
+	s.ready.Broadcast()
+}
+
+// pop removes the mutation at the head of the queue, the mutex must be held
+func (s *Subscription) pop() domain.Mutation {
+	head := s.queue[0]
+	s.queue[0] = entry{}
+	s.queue = s.queue[1:]
+	if position, ok := s.index[head.key]; ok && position == s.base {
+		delete(s.index, head.key)
+	}
+	s.base++
+	return head.mutation
+}
+
+// deliver sends queued mutations to the subscriber one at a time until the subscription is closed
+func (s *Subscription) deliver() {
+	defer close(s.out)
+	for {
+		s.mutex.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.ready.Wait()
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			return
+		}
+		mutation := s.pop()
+		// Wake publishers waiting for room
+		s.ready.Broadcast()
+		s.mutex.Unlock()
+		select {
+		case s.out <- mutation:
+			atomic.AddUint64(&s.delivered, 1)
+		case <-s.done:
+			return
+		}
+	}
+}
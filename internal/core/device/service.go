@@ -19,17 +19,23 @@ type deviceService struct {
 	generic.Watchable[domain.Device]
 }
 
-func (u *deviceService) EmitAll() error {
+func (u *deviceService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, device := range *all {
-		err = u.emit(&device)
-		if err != nil {
-			return err
-		}
+	for i := range *all {
+		(*all)[i].Utilization = u.utilization[(*all)[i].Id]
+	}
+	return u.Mutations(*all), nil
+}
+
+func (u *deviceService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -0,0 +1,66 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"udap/internal/core/domain/common"
+)
+
+// Action kinds, macros, subroutines and scenes use their own targets
+const (
+	ACTION  = "action"
+	REQUEST = "request"
+	NOTIFY  = "notify"
+	HTTP    = "http"
+)
+
+// Action is run whenever the trigger it is bound to fires. The custom key and value of a trigger can be
+// referenced as {{key}} and {{value}} in the request, body and url of an action.
+type Action struct {
+	common.Persistent
+	Name      string            `json:"name"`
+	TriggerId string            `json:"triggerId" gorm:"index"`
+	Kind      string            `json:"kind" gorm:"default:'request'"`
+	Entities  []string          `json:"entities" gorm:"serializer:json"`
+	Attribute string            `json:"attribute"`
+	Request   string            `json:"request"`
+	Target    string            `json:"target"` // Macro, subroutine or scene id
+	Method    string            `json:"method"`
+	Url       string            `json:"url"`
+	Headers   map[string]string `json:"headers" gorm:"serializer:json"`
+	Body      string            `json:"body"`
+	LastRun   time.Time         `json:"lastRun"`
+}
+
+// Expand substitutes the trigger's custom key and value into the provided text
+func (a *Action) Expand(text string, key string, value string) string {
+	return strings.NewReplacer("{{key}}", key, "{{value}}", value).Replace(text)
+}
+
+// Validate checks that the action declares everything its kind requires
+func (a *Action) Validate() error {
+	switch a.Kind {
+	case "", REQUEST:
+		if len(a.Entities) == 0 || a.Attribute == "" {
+			return fmt.Errorf("request action requires entities and an attribute")
+		}
+	case MACRO, SUBROUTINE, SCENE:
+		if a.Target == "" {
+			return fmt.Errorf("%s action requires a target", a.Kind)
+		}
+	case NOTIFY:
+		if a.Body == "" {
+			return fmt.Errorf("notify action requires a body")
+		}
+	case HTTP:
+		if a.Url == "" {
+			return fmt.Errorf("http action requires a url")
+		}
+	default:
+		return fmt.Errorf("unknown action kind '%s'", a.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import "testing"
+
+func TestAction_Expand(t *testing.T) {
+	a := Action{}
+	got := a.Expand("set {{key}} to {{value}}", "scene", "evening")
+	if want := "set scene to evening"; got != want {
+		t.Errorf("Expand() = %s, want %s", got, want)
+	}
+}
+
+func TestAction_Validate(t *testing.T) {
+	tests := []struct {
+		action Action
+		valid  bool
+	}{
+		{Action{Entities: []string{"a"}, Attribute: "on"}, true},
+		{Action{Kind: REQUEST}, false},
+		{Action{Kind: MACRO, Target: "m"}, true},
+		{Action{Kind: HTTP}, false},
+		{Action{Kind: "unknown"}, false},
+	}
+	for _, tt := range tests {
+		err := tt.action.Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%+v) error = %v, want valid %v", tt.action, err, tt.valid)
+		}
+	}
+}
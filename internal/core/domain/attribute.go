@@ -3,7 +3,10 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 	"udap/internal/core/domain/common"
 )
@@ -18,16 +21,43 @@ type AttributeLog struct {
 
 type Attribute struct {
 	common.Persistent
-	Value     string         `json:"value"`
-	Updated   time.Time      `json:"lastUpdated"`
-	Request   string         `json:"request"`
-	Requested time.Time      `json:"requested"`
-	Entity    string         `json:"entity"`
-	Serial    string         `json:"serial"`
-	Key       string         `json:"key"`
-	Type      string         `json:"type"`
-	Order     int            `json:"order"`
-	Channel   chan Attribute `json:"-" gorm:"-"`
+	Value     string          `json:"value"`
+	Updated   time.Time       `json:"lastUpdated"`
+	Request   string          `json:"request"`
+	Requested time.Time       `json:"requested"`
+	Entity    string          `json:"entity"`
+	Serial    string          `json:"serial"`
+	Key       string          `json:"key"`
+	Type      string          `json:"type"`
+	Order     int             `json:"order"`
+	Schema    AttributeSchema `json:"schema" gorm:"serializer:json"`
+	State     string          `json:"state" gorm:"default:'confirmed'"`
+	Timeout   time.Duration   `json:"timeout"`
+	Channel   chan Attribute  `json:"-" gorm:"-"`
+}
+
+// Request lifecycle states
+const (
+	PENDING   = "pending"
+	CONFIRMED = "confirmed"
+	FAILED    = "failed"
+	TIMEOUT   = "timeout"
+)
+
+// DefaultRequestTimeout is used when an attribute does not declare its own timeout
+const DefaultRequestTimeout = time.Second * 5
+
+// RequestTimeout returns the time a module has to confirm a request
+func (a *Attribute) RequestTimeout() time.Duration {
+	if a.Timeout <= 0 {
+		return DefaultRequestTimeout
+	}
+	return a.Timeout
+}
+
+// Pending reports whether the attribute is waiting for a module to confirm a request
+func (a *Attribute) Pending() bool {
+	return a.State == PENDING
 }
 
 const (
@@ -35,8 +65,97 @@ const (
 	BUFFER = "buffer"
 	TOGGLE = "toggle"
 	RANGE  = "range"
+	COLOR  = "color"
+	ENUM   = "enum"
+)
+
+// AttributeSchema describes the values an attribute will accept
+type AttributeSchema struct {
+	Min  float64  `json:"min"`
+	Max  float64  `json:"max"`
+	Step float64  `json:"step"`
+	Enum []string `json:"enum"`
+	Unit string   `json:"unit"`
+}
+
+// Bounded reports whether the schema defines a numeric range, bounds are only enforced when max exceeds min
+func (s AttributeSchema) Bounded() bool {
+	return s.Max > s.Min
+}
+
+// IsZero reports whether the schema declares no constraints at all
+func (s AttributeSchema) IsZero() bool {
+	return !s.Bounded() && s.Step == 0 && len(s.Enum) == 0 && s.Unit == ""
+}
+
+// RangeSchema creates a schema for numeric values between min and max
+func RangeSchema(min float64, max float64, step float64, unit string) AttributeSchema {
+	return AttributeSchema{
+		Min:  min,
+		Max:  max,
+		Step: step,
+		Unit: unit,
+	}
+}
+
+// EnumSchema creates a schema that only accepts the provided members
+func EnumSchema(members ...string) AttributeSchema {
+	return AttributeSchema{
+		Enum: members,
+	}
+}
+
+// DefaultSchema returns the schema used when a module does not declare one
+func DefaultSchema(variant string) AttributeSchema {
+	switch variant {
+	case COLOR:
+		return AttributeSchema{Unit: "hex"}
+	default:
+		return AttributeSchema{}
+	}
+}
+
+// DefaultSchemas returns the default schema of each known attribute type
+func DefaultSchemas() map[string]AttributeSchema {
+	schemas := map[string]AttributeSchema{}
+	for _, variant := range []string{MEDIA, BUFFER, TOGGLE, RANGE, COLOR, ENUM} {
+		schemas[variant] = DefaultSchema(variant)
+	}
+	return schemas
+}
+
+var (
+	// ErrQueueFull is returned when a module has too many undelivered requests
+	ErrQueueFull = errors.New("module request queue is full")
+	// ErrNotConsuming is returned when a module does not accept a request before its deadline
+	ErrNotConsuming = errors.New("module is not consuming requests")
 )
 
+// DispatchQueue describes the requests waiting to be delivered to a module
+type DispatchQueue struct {
+	Module    string    `json:"module"`
+	Entities  []string  `json:"entities"`
+	Depth     int       `json:"depth"`
+	Capacity  int       `json:"capacity"`
+	Delivered int       `json:"delivered"`
+	Coalesced int       `json:"coalesced"`
+	Failed    int       `json:"failed"`
+	Sending   bool      `json:"sending"`
+	LastSent  time.Time `json:"lastSent"`
+}
+
+// ValidationError is returned when a value does not satisfy an attribute's type or schema
+type ValidationError struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+func (v *ValidationError) Error() string {
+	return fmt.Sprintf("invalid value '%s' for attribute '%s': %s", v.Value, v.Key, v.Reason)
+}
+
 func NewToggleAttribute(entity string) Attribute {
 	attribute := Attribute{
 		Key:       "on",
@@ -47,6 +166,7 @@ func NewToggleAttribute(entity string) Attribute {
 		Updated:   time.Now(),
 		Requested: time.Time{},
 		Order:     0,
+		Schema:    DefaultSchema(TOGGLE),
 		Channel:   make(chan Attribute),
 	}
 	return attribute
@@ -62,6 +182,7 @@ func NewDimAttribute(entity string) Attribute {
 		Updated:   time.Now(),
 		Requested: time.Time{},
 		Order:     0,
+		Schema:    RangeSchema(0, 100, 1, "%"),
 		Channel:   make(chan Attribute),
 	}
 	return attribute
@@ -77,11 +198,91 @@ func NewAttribute(key string, variant string, entity string) Attribute {
 		Updated:   time.Time{},
 		Requested: time.Time{},
 		Order:     0,
+		Schema:    DefaultSchema(variant),
 		Channel:   make(chan Attribute),
 	}
 	return attribute
 }
 
+// Validate confirms that a value can be assigned to the attribute given its type and schema
+func (a *Attribute) Validate(value string) error {
+	invalid := func(format string, args ...any) error {
+		return &ValidationError{
+			Entity: a.Entity,
+			Key:    a.Key,
+			Value:  value,
+			Reason: fmt.Sprintf(format, args...),
+		}
+	}
+
+	switch a.Type {
+	case TOGGLE:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return invalid("expected a boolean")
+		}
+	case RANGE:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return invalid("expected a number")
+		}
+		if a.Schema.Bounded() && (parsed < a.Schema.Min || parsed > a.Schema.Max) {
+			return invalid("must be between %g and %g", a.Schema.Min, a.Schema.Max)
+		}
+		if a.Schema.Step > 0 {
+			steps := (parsed - a.Schema.Min) / a.Schema.Step
+			if diff := steps - float64(int64(steps+0.5)); diff > 1e-9 || diff < -1e-9 {
+				return invalid("must be a multiple of %g", a.Schema.Step)
+			}
+		}
+	case COLOR:
+		if !isHexColor(value) {
+			return invalid("expected a color in the form #rrggbb")
+		}
+	case ENUM:
+		if len(a.Schema.Enum) == 0 {
+			return invalid("no members are defined")
+		}
+	}
+
+	if len(a.Schema.Enum) > 0 {
+		for _, member := range a.Schema.Enum {
+			if member == value {
+				return nil
+			}
+		}
+		return invalid("must be one of [%s]", strings.Join(a.Schema.Enum, ", "))
+	}
+
+	return nil
+}
+
+func isHexColor(value string) bool {
+	if len(value) != 7 || value[0] != '#' {
+		return false
+	}
+	_, err := strconv.ParseUint(value[1:], 16, 32)
+	return err == nil
+}
+
+// ParseInt returns the attribute value as an int, or an error if it cannot be parsed
+func (a *Attribute) ParseInt() (int, error) {
+	parsed, err := strconv.ParseInt(a.Value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int(parsed), nil
+}
+
+// ParseFloat returns the attribute value as a float, or an error if it cannot be parsed
+func (a *Attribute) ParseFloat() (float64, error) {
+	return strconv.ParseFloat(a.Value, 64)
+}
+
+// ParseBool returns the attribute value as a bool, or an error if it cannot be parsed
+func (a *Attribute) ParseBool() (bool, error) {
+	return strconv.ParseBool(a.Value)
+}
+
 func (a *Attribute) AsInt() int {
 	parsed, err := strconv.ParseInt(a.Value, 10, 32)
 	if err != nil {
@@ -41,3 +41,60 @@ func TestAttribute_AsBool(t *testing.T) {
 		t.Errorf("failed to convert string to bool")
 	}
 }
+
+func TestAttribute_ValidateToggle(t *testing.T) {
+	a := NewToggleAttribute("entity")
+	if err := a.Validate("true"); err != nil {
+		t.Errorf("valid toggle rejected: %s", err)
+	}
+	if err := a.Validate("maybe"); err == nil {
+		t.Errorf("invalid toggle accepted")
+	}
+}
+
+func TestAttribute_ValidateRange(t *testing.T) {
+	a := NewDimAttribute("entity")
+	if err := a.Validate("50"); err != nil {
+		t.Errorf("valid range rejected: %s", err)
+	}
+	if err := a.Validate("101"); err == nil {
+		t.Errorf("out of bounds range accepted")
+	}
+	if err := a.Validate("50.5"); err == nil {
+		t.Errorf("off-step range accepted")
+	}
+	err := a.Validate("-1")
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestAttribute_ValidateEnum(t *testing.T) {
+	a := NewAttribute("mode", ENUM, "entity")
+	a.Schema = EnumSchema("heat", "cool")
+	if err := a.Validate("heat"); err != nil {
+		t.Errorf("valid member rejected: %s", err)
+	}
+	if err := a.Validate("off"); err == nil {
+		t.Errorf("invalid member accepted")
+	}
+}
+
+func TestAttribute_ValidateColor(t *testing.T) {
+	a := NewAttribute("color", COLOR, "entity")
+	if err := a.Validate("#ff8800"); err != nil {
+		t.Errorf("valid color rejected: %s", err)
+	}
+	if err := a.Validate("orange"); err == nil {
+		t.Errorf("invalid color accepted")
+	}
+}
+
+func TestAttribute_ParseInt(t *testing.T) {
+	a := Attribute{
+		Value: "applesauce",
+	}
+	if _, err := a.ParseInt(); err == nil {
+		t.Errorf("expected parse failure")
+	}
+}
@@ -3,6 +3,8 @@
 package domain
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"math/rand"
@@ -21,6 +23,42 @@ type Endpoint struct {
 	Key           string          `json:"key"`
 }
 
+// EndpointStats reports the traffic of an enrolled endpoint's connection
+type EndpointStats struct {
+	Id       string    `json:"id"`
+	Name     string    `json:"name"`
+	Queued   int       `json:"queued"`
+	Sent     uint64    `json:"sent"`
+	Dropped  uint64    `json:"dropped"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// ErrReplaced is returned when unenrolling a connection the endpoint has since replaced by reconnecting
+var ErrReplaced = errors.New("endpoint has reconnected")
+
+// Command is a message sent by an endpoint over its websocket. The id is chosen by the endpoint and returned in the
+// command's Acknowledgement.
+type Command struct {
+	Id        string          `json:"id"`
+	Operation string          `json:"operation"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// Acknowledgement answers a command, error is empty when the command succeeded. Commands that produce something
+// return it as the result.
+type Acknowledgement struct {
+	Operation string `json:"operation"`
+	Error     string `json:"error,omitempty"`
+	Result    any    `json:"result,omitempty"`
+}
+
+// Position identifies a point in the mutations sent to endpoints. Sequences restart along with the server, so
+// positions are only comparable within the same epoch.
+type Position struct {
+	Epoch    int64  `json:"epoch"`
+	Sequence uint64 `json:"sequence"`
+}
+
 func randomString() string {
 	template := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	var out string
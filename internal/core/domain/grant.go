@@ -0,0 +1,45 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import "path"
+
+// Capabilities a module may be granted beyond managing its own entities and attributes
+const (
+	CONTROL   = "control"   // Request, set and update the attributes of entities owned by other modules
+	FIRE      = "fire"      // Fire triggers the module did not register
+	ENDPOINTS = "endpoints" // Read endpoints, such as their push subscriptions
+)
+
+// Grant extends a module's access, it is declared in the module's config and enforced by the host
+type Grant struct {
+	Capability string `json:"capability"`
+	// Patterns matched against entity or module names for control, and trigger names for fire, the grant
+	// applies to every target when none are given
+	Targets []string `json:"targets,omitempty"`
+}
+
+// Covers reports whether the grant applies to any of the targets
+func (g Grant) Covers(targets ...string) bool {
+	if len(g.Targets) == 0 {
+		return true
+	}
+	for _, pattern := range g.Targets {
+		for _, target := range targets {
+			if matched, _ := path.Match(pattern, target); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Granted reports whether any of the grants gives the capability over one of the targets
+func Granted(grants []Grant, capability string, targets ...string) bool {
+	for _, grant := range grants {
+		if grant.Capability == capability && grant.Covers(targets...) {
+			return true
+		}
+	}
+	return false
+}
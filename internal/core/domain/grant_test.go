@@ -0,0 +1,28 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import "testing"
+
+func TestGranted(t *testing.T) {
+	grants := []Grant{
+		{Capability: CONTROL, Targets: []string{"sentry*", "weather"}},
+		{Capability: FIRE},
+	}
+	tests := []struct {
+		capability string
+		targets    []string
+		want       bool
+	}{
+		{CONTROL, []string{"sentryA", "sentry"}, true},
+		{CONTROL, []string{"light", "weather"}, true},
+		{CONTROL, []string{"light", "hs100"}, false},
+		{FIRE, []string{"motion"}, true},
+		{ENDPOINTS, []string{"a"}, false},
+	}
+	for _, tt := range tests {
+		if got := Granted(grants, tt.capability, tt.targets...); got != tt.want {
+			t.Errorf("Granted(%s, %v) = %v, want %v", tt.capability, tt.targets, got, tt.want)
+		}
+	}
+}
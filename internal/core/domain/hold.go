@@ -0,0 +1,31 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"time"
+	"udap/internal/core/domain/common"
+)
+
+// Hold records the value an attribute had before a macro changed it, so it can be restored when the hold expires
+type Hold struct {
+	common.Persistent
+	Origin    string    `json:"origin"` // The subroutine that placed the hold
+	MacroId   string    `json:"macroId"`
+	Attribute string    `json:"attribute"`
+	Entity    string    `json:"entity"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`   // The value restored once the hold expires
+	Applied   string    `json:"applied"` // The value requested by the macro
+	Expires   time.Time `json:"expires"`
+}
+
+// Overridden reports whether the attribute was changed to something other than what the hold applied, either by a
+// request or by the device reporting a change made outside udap. Changes that predate the hold's last request are
+// ignored, since they may still be in flight.
+func (h *Hold) Overridden(attribute Attribute) bool {
+	if !attribute.Requested.Before(h.UpdatedAt) && attribute.Request != h.Applied {
+		return true
+	}
+	return attribute.Updated.After(h.UpdatedAt) && attribute.Value != h.Applied
+}
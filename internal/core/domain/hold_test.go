@@ -0,0 +1,32 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHold_Overridden(t *testing.T) {
+	placed := time.Now()
+	h := Hold{Applied: "true"}
+	h.UpdatedAt = placed
+	before := placed.Add(-time.Second)
+	after := placed.Add(time.Second)
+
+	tests := []struct {
+		name      string
+		attribute Attribute
+		want      bool
+	}{
+		{"applied", Attribute{Request: "true", Requested: before, Value: "true", Updated: after}, false},
+		{"in flight", Attribute{Request: "false", Requested: before, Value: "false", Updated: before}, false},
+		{"requested", Attribute{Request: "false", Requested: after, Value: "true", Updated: before}, true},
+		{"reported", Attribute{Request: "true", Requested: before, Value: "false", Updated: after}, true},
+	}
+	for _, tt := range tests {
+		if got := h.Overridden(tt.attribute); got != tt.want {
+			t.Errorf("%s: Overridden() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"time"
+	"udap/internal/core/domain/common"
+)
+
+// Invocation sources, modules use MODULE and internal callers SYSTEM
+const (
+	USER     = "user"
+	ENDPOINT = "endpoint"
+	RULE     = "rule"
+	SCHEDULE = "schedule"
+)
+
+// Invocation states, failures use FAILED
+const (
+	RUNNING   = "running"
+	SUCCEEDED = "succeeded"
+	SKIPPED   = "skipped"
+	ABORTED   = "aborted"
+)
+
+// Source describes what caused an invocation. Parent is the id of the invocation that caused it, if any.
+type Source struct {
+	Kind   string `json:"kind"`
+	Id     string `json:"id"`
+	Parent string `json:"parent"`
+}
+
+// Within returns the source used for anything run as part of the provided invocation
+func Within(invocation *Invocation) Source {
+	return Source{
+		Kind:   invocation.Kind,
+		Id:     invocation.Target,
+		Parent: invocation.Id,
+	}
+}
+
+// InvocationRequest is an attribute request made as part of an invocation
+type InvocationRequest struct {
+	Entity string    `json:"entity"`
+	Key    string    `json:"key"`
+	Value  string    `json:"value"`
+	Error  string    `json:"error"`
+	Time   time.Time `json:"time"`
+}
+
+// StepResult records the outcome of a single step of a subroutine invocation
+type StepResult struct {
+	Path     string    `json:"path"` // Position of the step, such as 2 or 2.then.0
+	Kind     string    `json:"kind"`
+	Target   string    `json:"target"`
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+}
+
+// Invocation is the audit record of a trigger, subroutine, macro or scene being run
+type Invocation struct {
+	common.Persistent
+	Kind     string              `json:"kind"`
+	Target   string              `json:"target"`
+	Name     string              `json:"name"`
+	Source   string              `json:"source"`
+	SourceId string              `json:"sourceId"`
+	Parent   string              `json:"parent" gorm:"index"`
+	Status   string              `json:"status"`
+	Requests []InvocationRequest `json:"requests" gorm:"serializer:json"`
+	Steps    []StepResult        `json:"steps" gorm:"serializer:json"` // Only recorded for subroutines
+	Started  time.Time           `json:"started" gorm:"index"`
+	Finished time.Time           `json:"finished"`
+	Duration time.Duration       `json:"duration"`
+	Error    string              `json:"error"`
+}
+
+// InvocationFilter narrows a query of the invocation history, zero values are ignored
+type InvocationFilter struct {
+	Kind   string    `json:"kind"`
+	Target string    `json:"target"`
+	Source string    `json:"source"`
+	Parent string    `json:"parent"`
+	Failed bool      `json:"failed"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+	Offset int       `json:"offset"`
+	Limit  int       `json:"limit"`
+}
+
+// InvocationPage is a single page of query results
+type InvocationPage struct {
+	Invocations []Invocation `json:"invocations"`
+	Total       int64        `json:"total"`
+	Offset      int          `json:"offset"`
+	Limit       int          `json:"limit"`
+}
@@ -7,11 +7,42 @@ import (
 	"udap/internal/core/domain/common"
 )
 
+// Log levels
+const (
+	INFO  = "info"
+	WARN  = "warn"
+	ERROR = "error"
+)
+
 type Log struct {
 	common.Persistent
-	Group   string    `json:"group"`
-	Event   string    `json:"event"`
-	Time    time.Time `json:"time"`
-	Message string    `json:"message"`
-	Level   string    `json:"level"`
+	Group       string    `json:"group"`
+	Event       string    `json:"event"`
+	Time        time.Time `json:"time" gorm:"index"`
+	Message     string    `json:"message"`
+	Level       string    `json:"level" gorm:"index"`
+	Module      string    `json:"module" gorm:"index"`
+	Entity      string    `json:"entity"`
+	Correlation string    `json:"correlation" gorm:"index"` // Ties together logs from a single operation
+}
+
+// LogFilter narrows a query of the log store, zero values are ignored
+type LogFilter struct {
+	Level       string    `json:"level"`
+	Module      string    `json:"module"`
+	Entity      string    `json:"entity"`
+	Correlation string    `json:"correlation"`
+	Search      string    `json:"search"`
+	Since       time.Time `json:"since"`
+	Until       time.Time `json:"until"`
+	Offset      int       `json:"offset"`
+	Limit       int       `json:"limit"`
+}
+
+// LogPage is a single page of query results
+type LogPage struct {
+	Logs   []Log `json:"logs"`
+	Total  int64 `json:"total"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
 }
@@ -9,14 +9,21 @@ import (
 	"udap/internal/core/domain/common"
 )
 
+const (
+	PLUGIN  = "plugin"
+	PROCESS = "process"
+)
+
 type ModuleConfig struct {
 	Name        string        `json:"name"`
 	Type        string        `json:"type"` // Module, Daemon, etc.
 	Description string        `json:"description"`
 	Interval    time.Duration `json:"interval"`
+	Jitter      time.Duration `json:"jitter"`
 	Version     string        `json:"version"`
 	Author      string        `json:"author"`
 	Variables   string        `json:"variables"`
+	Grants      string        `json:"grants"`
 }
 
 type Module struct {
@@ -27,14 +34,18 @@ type Module struct {
 	Type        string        `json:"type"`
 	Description string        `json:"description"`
 	Interval    time.Duration `json:"interval"`
+	Jitter      time.Duration `json:"jitter"`
 	Version     string        `json:"version"`
+	Previous    string        `json:"previous"` // The version kept for rollback, if any
 	Author      string        `json:"author"`
 	Variables   string        `json:"variables"`
+	Grants      string        `json:"grants"`
 	Channel     chan Module   `json:"-" gorm:"-"`
 	Config      string        `json:"config" gorm:"default:'{}'"`
 	State       string        `json:"state"`
 	Running     bool          `json:"running" gorm:"default:false"`
 	Enabled     bool          `json:"enabled" gorm:"default:true"`
+	Runtime     string        `json:"runtime" gorm:"default:'plugin'"` // plugin or process
 	Recover     int           `json:"recover"`
 }
 
@@ -49,5 +60,51 @@ func (m *Module) CompiledPath() string {
 	if m.UUID == "" {
 		return "invalid"
 	}
+	if m.Runtime == PROCESS {
+		return strings.Replace(m.Path, ".go", fmt.Sprintf("-%s.bin", m.UUID), 1)
+	}
 	return strings.Replace(m.Path, ".go", fmt.Sprintf("-%s.so", m.UUID), 1)
 }
+
+const (
+	HEALTHY    = "healthy"
+	DEGRADED   = "degraded"
+	RESTARTING = "restarting"
+)
+
+// ModuleHealth summarizes how a module has behaved since the system started
+type ModuleHealth struct {
+	Module     string          `json:"module"`
+	Status     string          `json:"status"` // healthy, degraded, restarting or failed
+	Updates    int             `json:"updates"`
+	Errors     int             `json:"errors"`
+	ErrorRate  float64         `json:"errorRate"` // Over the most recent updates
+	Missed     int             `json:"missed"`    // Consecutive missed update deadlines
+	Panics     int             `json:"panics"`
+	Restarts   int             `json:"restarts"`
+	LastUpdate time.Time       `json:"lastUpdate"`
+	LastError  string          `json:"lastError"`
+	History    []ModuleRestart `json:"history"`
+}
+
+type ModuleRestart struct {
+	Time    time.Time     `json:"time"`
+	Reason  string        `json:"reason"`
+	Attempt int           `json:"attempt"`
+	Delay   time.Duration `json:"delay"`
+	Error   string        `json:"error"`
+}
+
+// ModuleSchedule describes the update cadence of a running module
+type ModuleSchedule struct {
+	Module      string        `json:"module"`
+	Interval    time.Duration `json:"interval"`
+	Jitter      time.Duration `json:"jitter"`
+	Next        time.Time     `json:"next"`
+	LastRun     time.Time     `json:"lastRun"`
+	Lateness    time.Duration `json:"lateness"` // How late the most recent update started
+	MaxLateness time.Duration `json:"maxLateness"`
+	Runs        int           `json:"runs"`
+	Skipped     int           `json:"skipped"` // Updates skipped because the previous update was still running
+	Running     bool          `json:"running"`
+}
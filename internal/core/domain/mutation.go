@@ -9,6 +9,77 @@ type Mutation struct {
 	Id        string `json:"id"`
 }
 
+// MutationFilter selects the mutations delivered to a module. Each non-empty list must contain the
+// corresponding property of a mutation for it to match.
+type MutationFilter struct {
+	Operations []string `json:"operations"` // attribute, entity, module, etc.
+	Entities   []string `json:"entities"`   // Entity ids
+	Modules    []string `json:"modules"`    // Names of the modules that own the entities
+	Keys       []string `json:"keys"`       // Attribute keys
+}
+
+// Matches reports whether a mutation with the given operation, entity, module and attribute key passes the filter
+func (f MutationFilter) Matches(operation string, entity string, module string, key string) bool {
+	return contains(f.Operations, operation) && contains(f.Entities, entity) && contains(f.Modules, module) &&
+		contains(f.Keys, key)
+}
+
+// Subscription selects the mutations delivered to an endpoint. When operations are listed, a mutation must have one
+// of them. When zones or entities are listed, entity and attribute mutations must concern one of the entities or an
+// entity within one of the zones, and zone mutations must be of one of the zones. Other mutations are selected by
+// their operation alone.
+type Subscription struct {
+	Operations []string `json:"operations"` // attribute, entity, zone, etc.
+	Zones      []string `json:"zones"`      // Zone ids
+	Entities   []string `json:"entities"`   // Entity ids
+}
+
+// Matches reports whether a mutation passes the subscription, members holds the ids of the entities within the
+// subscribed zones
+func (s Subscription) Matches(mutation Mutation, members map[string]bool) bool {
+	if !contains(s.Operations, mutation.Operation) {
+		return false
+	}
+	if len(s.Zones) == 0 && len(s.Entities) == 0 {
+		return true
+	}
+	switch body := mutation.Body.(type) {
+	case Zone:
+		return contains(s.Zones, body.Id)
+	case Entity:
+		return s.concerns(body.Id, members)
+	case Attribute:
+		return s.concerns(body.Entity, members)
+	}
+	return true
+}
+
+// concerns reports whether an entity is one of the subscribed entities or within a subscribed zone
+func (s Subscription) concerns(entity string, members map[string]bool) bool {
+	if members[entity] {
+		return true
+	}
+	for _, id := range s.Entities {
+		if id == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether the value is in the list, an empty list contains every value
+func contains(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 type Observer struct {
 }
 
@@ -18,5 +89,7 @@ func (o *Observer) emit() {
 
 type Observable interface {
 	Watch(chan<- Mutation)
+	// Snapshot describes the current state of the elements a client needs before it can follow mutations
+	Snapshot() ([]Mutation, error)
 	EmitAll() error
 }
@@ -0,0 +1,75 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import "testing"
+
+func TestMutationFilter_Matches(t *testing.T) {
+	filter := MutationFilter{
+		Operations: []string{"attribute"},
+		Keys:       []string{"on", "dim"},
+	}
+	tests := []struct {
+		operation string
+		entity    string
+		module    string
+		key       string
+		want      bool
+	}{
+		{"attribute", "a", "hs100", "on", true},
+		{"attribute", "b", "govee", "dim", true},
+		{"attribute", "a", "hs100", "hue", false},
+		{"entity", "a", "hs100", "", false},
+	}
+	for _, tt := range tests {
+		if got := filter.Matches(tt.operation, tt.entity, tt.module, tt.key); got != tt.want {
+			t.Errorf("Matches(%s, %s, %s, %s) = %v, want %v", tt.operation, tt.entity, tt.module, tt.key, got,
+				tt.want)
+		}
+	}
+	if !(MutationFilter{}).Matches("zone", "", "", "") {
+		t.Errorf("an empty filter should match every mutation")
+	}
+}
+
+func TestSubscription_Matches(t *testing.T) {
+	kitchen := Zone{Name: "kitchen"}
+	kitchen.Id = "z1"
+	lamp := Entity{Name: "lamp"}
+	lamp.Id = "e1"
+	fan := Entity{Name: "fan"}
+	fan.Id = "e2"
+	members := map[string]bool{"e1": true}
+
+	subscription := Subscription{
+		Operations: []string{"zone", "entity", "attribute", "module"},
+		Zones:      []string{"z1"},
+	}
+	tests := []struct {
+		name     string
+		mutation Mutation
+		want     bool
+	}{
+		{"subscribed zone", Mutation{Operation: "zone", Body: kitchen}, true},
+		{"other zone", Mutation{Operation: "zone", Body: Zone{}}, false},
+		{"entity in zone", Mutation{Operation: "entity", Body: lamp}, true},
+		{"entity outside zone", Mutation{Operation: "entity", Body: fan}, false},
+		{"attribute in zone", Mutation{Operation: "attribute", Body: Attribute{Entity: "e1"}}, true},
+		{"attribute outside zone", Mutation{Operation: "attribute", Body: Attribute{Entity: "e2"}}, false},
+		{"operation without entities", Mutation{Operation: "module", Body: Module{}}, true},
+		{"operation not subscribed", Mutation{Operation: "trigger", Body: Trigger{}}, false},
+	}
+	for _, tt := range tests {
+		if got := subscription.Matches(tt.mutation, members); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	subscription = Subscription{Entities: []string{"e2"}}
+	if !subscription.Matches(Mutation{Operation: "attribute", Body: Attribute{Entity: "e2"}}, nil) {
+		t.Errorf("a subscribed entity's attributes should match")
+	}
+	if !(Subscription{}).Matches(Mutation{Operation: "timing"}, nil) {
+		t.Errorf("an empty subscription should match every mutation")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModuleAPI is the revision of the module api provided by this system, packages requiring a later revision
+// are refused
+const ModuleAPI = 1
+
+var moduleName = regexp.MustCompile("^[a-z0-9][a-z0-9_-]*$")
+
+// Manifest describes a packaged module, it is stored as manifest.json at the root of the package archive
+type Manifest struct {
+	Name         string       `json:"name"`
+	Version      string       `json:"version"`
+	Api          int          `json:"api"` // The module api revision the module was written against
+	Description  string       `json:"description"`
+	Author       string       `json:"author"`
+	Variables    []Variable   `json:"variables"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Dependency names a module that must be installed for a package to be installed
+type Dependency struct {
+	Module  string `json:"module"`
+	Version string `json:"version"` // The minimum version required, any version if empty
+}
+
+// Validate checks that the manifest is complete and can be installed by this system
+func (m Manifest) Validate() error {
+	if !moduleName.MatchString(m.Name) {
+		return fmt.Errorf("module name '%s' is invalid", m.Name)
+	}
+	_, err := ParseVersion(m.Version)
+	if err != nil {
+		return err
+	}
+	if m.Api < 1 || m.Api > ModuleAPI {
+		return fmt.Errorf("module '%s' requires api revision %d, this system provides %d", m.Name, m.Api,
+			ModuleAPI)
+	}
+	for _, variable := range m.Variables {
+		if variable.Default == "" {
+			continue
+		}
+		err = variable.Validate(variable.Default)
+		if err != nil {
+			return fmt.Errorf("default is invalid: %s", err.Error())
+		}
+	}
+	for _, dependency := range m.Dependencies {
+		if dependency.Module == "" || dependency.Module == m.Name {
+			return fmt.Errorf("module '%s' has an invalid dependency", m.Name)
+		}
+		if dependency.Version == "" {
+			continue
+		}
+		_, err = ParseVersion(dependency.Version)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Satisfied reports whether an installed version meets the dependency
+func (d Dependency) Satisfied(version string) bool {
+	if d.Version == "" {
+		return true
+	}
+	cmp, err := CompareVersions(version, d.Version)
+	return err == nil && cmp >= 0
+}
+
+// ParseVersion reads a version of the form major.minor.patch, missing parts are zero and any pre-release or
+// build suffix is ignored
+func ParseVersion(version string) ([3]int, error) {
+	parsed := [3]int{}
+	trimmed := strings.TrimPrefix(version, "v")
+	trimmed = strings.SplitN(trimmed, "+", 2)[0]
+	trimmed = strings.SplitN(trimmed, "-", 2)[0]
+	parts := strings.Split(trimmed, ".")
+	if trimmed == "" || len(parts) > 3 {
+		return parsed, fmt.Errorf("version '%s' is invalid", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("version '%s' is invalid", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// CompareVersions returns -1, 0 or 1 as version a is older than, the same as, or newer than version b
+func CompareVersions(a string, b string) (int, error) {
+	va, err := ParseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range va {
+		if va[i] < vb[i] {
+			return -1, nil
+		}
+		if va[i] > vb[i] {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
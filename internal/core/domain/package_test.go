@@ -0,0 +1,57 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"1.7.4", "1.7.4", 0},
+		{"1.7.4", "1.10.0", -1},
+		{"v2", "1.9.9", 1},
+		{"1.0.0-beta", "1", 0},
+	}
+	for _, tt := range tests {
+		got, err := CompareVersions(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("CompareVersions(%s, %s) = %d (%v), want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	if _, err := ParseVersion("1.x"); err == nil {
+		t.Errorf("ParseVersion should refuse non-numeric versions")
+	}
+}
+
+func TestManifest_Validate(t *testing.T) {
+	manifest := Manifest{
+		Name:         "hs100",
+		Version:      "1.7.4",
+		Api:          ModuleAPI,
+		Variables:    []Variable{{Name: "interval", Type: DURATION, Default: "5s"}},
+		Dependencies: []Dependency{{Module: "vyos", Version: "1.0.0"}},
+	}
+	if err := manifest.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	invalid := []func(m *Manifest){
+		func(m *Manifest) { m.Name = "../hs100" },
+		func(m *Manifest) { m.Version = "" },
+		func(m *Manifest) { m.Api = ModuleAPI + 1 },
+		func(m *Manifest) { m.Variables = []Variable{{Name: "interval", Type: DURATION, Default: "5"}} },
+		func(m *Manifest) { m.Dependencies = []Dependency{{Module: "hs100"}} },
+	}
+	for i, change := range invalid {
+		m := manifest
+		change(&m)
+		if m.Validate() == nil {
+			t.Errorf("case %d: expected the manifest to be invalid", i)
+		}
+	}
+	if !(Dependency{Module: "vyos", Version: "1.0.5"}).Satisfied("1.2") {
+		t.Errorf("1.2 should satisfy a dependency on 1.0.5")
+	}
+}
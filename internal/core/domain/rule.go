@@ -0,0 +1,124 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"strconv"
+	"time"
+	"udap/internal/core/domain/common"
+)
+
+// Rule modes describe how an attribute change is matched
+const (
+	CHANGES = "changes"
+	ABOVE   = "above"
+	BELOW   = "below"
+	EQUALS  = "equals"
+	DIFFERS = "differs"
+)
+
+// Targets describe what is run when something fires
+const (
+	TRIGGER    = "trigger"
+	SUBROUTINE = "subroutine"
+	MACRO      = "macro"
+)
+
+// RuleCondition must hold on another attribute for its rule to run
+type RuleCondition struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Mode   string `json:"mode"`
+	Value  string `json:"value"`
+}
+
+type Rule struct {
+	common.Persistent
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Enabled     bool            `json:"enabled" gorm:"default:true"`
+	Entity      string          `json:"entity"`
+	Key         string          `json:"key"`
+	Mode        string          `json:"mode"`
+	Value       string          `json:"value"`
+	Conditions  []RuleCondition `json:"conditions" gorm:"serializer:json"`
+	After       string          `json:"after"`  // Time of day in the form 15:04
+	Before      string          `json:"before"` // Time of day in the form 15:04
+	Target      string          `json:"target"`
+	TargetId    string          `json:"targetId"`
+	LastFired   time.Time       `json:"lastFired"`
+}
+
+// compare tests a value against an operand using one of the rule modes, a crossing is not considered
+func compare(mode string, value string, operand string) bool {
+	switch mode {
+	case EQUALS:
+		return value == operand
+	case DIFFERS:
+		return value != operand
+	case ABOVE, BELOW:
+		a, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		b, err := strconv.ParseFloat(operand, 64)
+		if err != nil {
+			return false
+		}
+		if mode == ABOVE {
+			return a > b
+		}
+		return a < b
+	default:
+		return false
+	}
+}
+
+// Holds reports whether the condition is satisfied by the provided value
+func (c RuleCondition) Holds(value string) bool {
+	return compare(c.Mode, value, c.Value)
+}
+
+// Matches reports whether a change from previous to current should fire the rule.
+// Thresholds only match when they are crossed, and values only match when they are first reached.
+func (r *Rule) Matches(previous string, current string) bool {
+	if previous == current {
+		return false
+	}
+	if r.Mode == CHANGES {
+		return true
+	}
+	return compare(r.Mode, current, r.Value) && !compare(r.Mode, previous, r.Value)
+}
+
+// InWindow reports whether the provided time falls within the rule's time of day window
+func (r *Rule) InWindow(now time.Time) bool {
+	if r.After == "" && r.Before == "" {
+		return true
+	}
+	minutes := now.Hour()*60 + now.Minute()
+	after, err := parseTimeOfDay(r.After, 0)
+	if err != nil {
+		return false
+	}
+	before, err := parseTimeOfDay(r.Before, 24*60)
+	if err != nil {
+		return false
+	}
+	// Windows such as 22:00 to 06:00 wrap around midnight
+	if after > before {
+		return minutes >= after || minutes < before
+	}
+	return minutes >= after && minutes < before
+}
+
+func parseTimeOfDay(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRule_MatchesThreshold(t *testing.T) {
+	r := Rule{Mode: ABOVE, Value: "20"}
+	if !r.Matches("19", "21") {
+		t.Errorf("crossing the threshold did not match")
+	}
+	if r.Matches("21", "22") {
+		t.Errorf("staying above the threshold matched")
+	}
+	if r.Matches("21", "19") {
+		t.Errorf("falling below the threshold matched")
+	}
+}
+
+func TestRule_MatchesValue(t *testing.T) {
+	r := Rule{Mode: EQUALS, Value: "true"}
+	if !r.Matches("false", "true") {
+		t.Errorf("reaching the value did not match")
+	}
+	if r.Matches("true", "true") {
+		t.Errorf("an unchanged value matched")
+	}
+}
+
+func TestRule_InWindow(t *testing.T) {
+	r := Rule{After: "22:00", Before: "06:00"}
+	night := time.Date(2022, 1, 1, 23, 30, 0, 0, time.Local)
+	day := time.Date(2022, 1, 1, 12, 0, 0, 0, time.Local)
+	if !r.InWindow(night) {
+		t.Errorf("time within a wrapping window was rejected")
+	}
+	if r.InWindow(day) {
+		t.Errorf("time outside a wrapping window was accepted")
+	}
+}
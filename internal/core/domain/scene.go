@@ -0,0 +1,28 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"time"
+	"udap/internal/core/domain/common"
+)
+
+// DefaultSceneKeys are captured when a scene is created from a zone without specifying keys
+var DefaultSceneKeys = []string{"on", "dim", "hue", "cct"}
+
+// SceneValue is the value of a single attribute within a scene
+type SceneValue struct {
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+type Scene struct {
+	common.Persistent
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	ZoneId      string        `json:"zone"`
+	Values      []SceneValue  `json:"values" gorm:"serializer:json"`
+	Transition  time.Duration `json:"transition"`
+	LastApplied time.Time     `json:"lastApplied"`
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule kinds
+const (
+	CRON     = "cron"
+	INTERVAL = "interval"
+	SUNRISE  = "sunrise"
+	SUNSET   = "sunset"
+)
+
+// Missed run policies
+const (
+	CATCHUP = "catchup"
+	SKIP    = "skip"
+)
+
+// Schedule describes when a trigger should fire without an external caller
+type Schedule struct {
+	Kind       string        `json:"kind"`
+	Expression string        `json:"expression"` // Five field cron expression
+	Interval   time.Duration `json:"interval"`
+	Offset     time.Duration `json:"offset"` // Offset from sunrise or sunset
+	Policy     string        `json:"policy"`
+}
+
+// Location is used to compute solar schedules
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func (s *Schedule) IsZero() bool {
+	return s.Kind == ""
+}
+
+// Validate checks that the schedule can produce fire times
+func (s *Schedule) Validate() error {
+	switch s.Kind {
+	case "":
+		return nil
+	case CRON:
+		_, err := ParseCron(s.Expression)
+		if err != nil {
+			return err
+		}
+	case INTERVAL:
+		if s.Interval < time.Second {
+			return fmt.Errorf("interval must be at least one second")
+		}
+	case SUNRISE, SUNSET:
+	default:
+		return fmt.Errorf("unknown schedule kind '%s'", s.Kind)
+	}
+	switch s.Policy {
+	case "", CATCHUP, SKIP:
+		return nil
+	default:
+		return fmt.Errorf("unknown schedule policy '%s'", s.Policy)
+	}
+}
+
+// Next returns the first fire time after the provided time, last is the previous fire time if any
+func (s *Schedule) Next(after time.Time, last time.Time, location Location) (time.Time, error) {
+	switch s.Kind {
+	case CRON:
+		cron, err := ParseCron(s.Expression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cron.Next(after)
+	case INTERVAL:
+		if s.Interval < time.Second {
+			return time.Time{}, fmt.Errorf("interval must be at least one second")
+		}
+		// Intervals are anchored to the previous run so they do not drift
+		if last.IsZero() || last.After(after) {
+			return after.Add(s.Interval), nil
+		}
+		missed := after.Sub(last) / s.Interval
+		return last.Add((missed + 1) * s.Interval), nil
+	case SUNRISE, SUNSET:
+		day := after
+		// The solar event may not occur near the poles, so look ahead a bounded number of days
+		for i := 0; i < 366; i++ {
+			rise, set, ok := SolarEvents(day, location)
+			if ok {
+				event := rise
+				if s.Kind == SUNSET {
+					event = set
+				}
+				event = event.Add(s.Offset)
+				if event.After(after) {
+					return event, nil
+				}
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+		return time.Time{}, fmt.Errorf("no %s found within a year", s.Kind)
+	default:
+		return time.Time{}, fmt.Errorf("unknown schedule kind '%s'", s.Kind)
+	}
+}
+
+// Cron is a parsed five field cron expression (minute, hour, day of month, month, day of week)
+type Cron struct {
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	anyDay   bool
+	anyWeek  bool
+}
+
+var cronBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+// ParseCron parses a five field cron expression supporting lists, ranges and steps
+func ParseCron(expression string) (*Cron, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have five fields", expression)
+	}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronBounds[i][0], cronBounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression '%s': %s", expression, err.Error())
+		}
+		sets[i] = set
+	}
+	// Sunday may also be written as 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &Cron{
+		minutes:  sets[0],
+		hours:    sets[1],
+		days:     sets[2],
+		months:   sets[3],
+		weekdays: sets[4],
+		anyDay:   strings.HasPrefix(fields[2], "*"),
+		anyWeek:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, min int, max int) (uint64, error) {
+	// Day of week accepts 7 as sunday
+	if max == 6 {
+		max = 7
+	}
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			value, err := strconv.Atoi(part[index+1:])
+			if err != nil || value < 1 {
+				return 0, fmt.Errorf("invalid step '%s'", part)
+			}
+			step = value
+			part = part[:index]
+		}
+		low, high := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			a, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid range '%s'", part)
+			}
+			b, err := strconv.Atoi(bounds[1])
+			if err != nil {
+				return 0, fmt.Errorf("invalid range '%s'", part)
+			}
+			low, high = a, b
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value '%s'", part)
+			}
+			low, high = value, value
+			if step > 1 {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("'%s' is out of range %d-%d", part, min, max)
+		}
+		for i := low; i <= high; i += step {
+			set |= 1 << uint(i)
+		}
+	}
+	return set, nil
+}
+
+func (c *Cron) matchesDay(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	week := c.weekdays&(1<<uint(t.Weekday())) != 0
+	// When both day fields are restricted either may match, as in standard cron
+	if !c.anyDay && !c.anyWeek {
+		return day || week
+	}
+	return day && week
+}
+
+// Next returns the first matching minute after the provided time
+func (c *Cron) Next(after time.Time) (time.Time, error) {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// Five years covers every valid expression, including february 29th
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cron expression never fires")
+}
+
+// SolarEvents computes the sunrise and sunset for the day of the provided time using the NOAA approximation.
+// The returned times are in the location of the provided time, ok is false when the sun does not rise or set.
+func SolarEvents(day time.Time, location Location) (sunrise time.Time, sunset time.Time, ok bool) {
+	const zenith = 90.833
+	rad := math.Pi / 180
+
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	// Fractional year in radians
+	gamma := 2 * math.Pi / 365 * float64(midnight.YearDay()-1)
+	equation := 229.18 * (0.000075 + 0.001868*math.Cos(gamma) - 0.032077*math.Sin(gamma) -
+		0.014615*math.Cos(2*gamma) - 0.040849*math.Sin(2*gamma))
+	declination := 0.006918 - 0.399912*math.Cos(gamma) + 0.070257*math.Sin(gamma) -
+		0.006758*math.Cos(2*gamma) + 0.000907*math.Sin(2*gamma) -
+		0.002697*math.Cos(3*gamma) + 0.00148*math.Sin(3*gamma)
+
+	latitude := location.Latitude * rad
+	cosine := math.Cos(zenith*rad)/(math.Cos(latitude)*math.Cos(declination)) - math.Tan(latitude)*math.Tan(declination)
+	if cosine < -1 || cosine > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	angle := math.Acos(cosine) / rad
+
+	// Minutes after midnight UTC
+	rise := 720 - 4*(location.Longitude+angle) - equation
+	set := 720 - 4*(location.Longitude-angle) - equation
+
+	utc := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	sunrise = utc.Add(time.Duration(rise * float64(time.Minute))).In(day.Location())
+	sunset = utc.Add(time.Duration(set * float64(time.Minute))).In(day.Location())
+	return sunrise, sunset, true
+}
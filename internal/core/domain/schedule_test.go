@@ -0,0 +1,78 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCron_Next(t *testing.T) {
+	tests := []struct {
+		expression string
+		after      time.Time
+		want       time.Time
+	}{
+		{"30 18 * * *", time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC), time.Date(2022, 6, 1, 18, 30, 0, 0, time.UTC)},
+		{"30 18 * * *", time.Date(2022, 6, 1, 18, 30, 0, 0, time.UTC), time.Date(2022, 6, 2, 18, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2022, 6, 1, 12, 16, 0, 0, time.UTC), time.Date(2022, 6, 1, 12, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2022, 6, 3, 10, 0, 0, 0, time.UTC), time.Date(2022, 6, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expression)
+		if err != nil {
+			t.Fatalf("ParseCron(%s) error = %v", tt.expression, err)
+		}
+		got, err := cron.Next(tt.after)
+		if err != nil {
+			t.Fatalf("Next(%s) error = %v", tt.expression, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Next(%s) = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * * 13 *", "*/0 * * * *"} {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("ParseCron(%q) accepted an invalid expression", expression)
+		}
+	}
+}
+
+func TestSchedule_NextInterval(t *testing.T) {
+	s := Schedule{Kind: INTERVAL, Interval: time.Hour}
+	last := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	got, err := s.Next(last.Add(150*time.Minute), last, Location{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := last.Add(3 * time.Hour); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestSolarEvents(t *testing.T) {
+	// Greenwich around the june solstice rises near 03:43 and sets near 20:21 UTC
+	rise, set, ok := SolarEvents(time.Date(2022, 6, 21, 0, 0, 0, 0, time.UTC), Location{Latitude: 51.48, Longitude: 0})
+	if !ok {
+		t.Fatalf("SolarEvents() reported no sunrise")
+	}
+	if want := time.Date(2022, 6, 21, 3, 43, 0, 0, time.UTC); !near(rise, want) {
+		t.Errorf("sunrise = %v, want about %v", rise, want)
+	}
+	if want := time.Date(2022, 6, 21, 20, 21, 0, 0, time.UTC); !near(set, want) {
+		t.Errorf("sunset = %v, want about %v", set, want)
+	}
+	_, _, ok = SolarEvents(time.Date(2022, 6, 21, 0, 0, 0, 0, time.UTC), Location{Latitude: 80, Longitude: 0})
+	if ok {
+		t.Errorf("SolarEvents() reported a sunrise during polar day")
+	}
+}
+
+func near(a time.Time, b time.Time) bool {
+	delta := a.Sub(b)
+	return delta < 5*time.Minute && delta > -5*time.Minute
+}
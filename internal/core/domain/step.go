@@ -0,0 +1,82 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Step kinds, macros use the MACRO target
+const (
+	SCENE  = "scene"
+	DELAY  = "delay"
+	WAIT   = "wait"
+	BRANCH = "branch"
+)
+
+// Failure policies
+const (
+	CONTINUE = "continue"
+	ABORT    = "abort"
+	RETRY    = "retry"
+)
+
+// DefaultWaitTimeout is used when a wait step does not declare its own timeout
+const DefaultWaitTimeout = time.Second * 30
+
+// DefaultRetries is used when the retry policy does not declare a number of attempts
+const DefaultRetries = 3
+
+// Step is a single item in the sequence of a subroutine
+type Step struct {
+	Kind      string         `json:"kind"`
+	Target    string         `json:"target"` // Macro or scene id
+	Delay     time.Duration  `json:"delay"`  // Time to wait before the step runs
+	Condition *RuleCondition `json:"condition"`
+	Timeout   time.Duration  `json:"timeout"`
+	Then      []Step         `json:"then"`
+	Else      []Step         `json:"else"`
+	Policy    string         `json:"policy"` // Overrides the subroutine policy when set
+	Retries   int            `json:"retries"`
+}
+
+// Validate checks that the step and any branches it contains can be run
+func (s *Step) Validate() error {
+	switch s.Kind {
+	case MACRO, SCENE:
+		if s.Target == "" {
+			return fmt.Errorf("%s step requires a target", s.Kind)
+		}
+	case DELAY:
+		if s.Delay <= 0 {
+			return fmt.Errorf("delay step requires a delay")
+		}
+	case WAIT, BRANCH:
+		if s.Condition == nil {
+			return fmt.Errorf("%s step requires a condition", s.Kind)
+		}
+	default:
+		return fmt.Errorf("unknown step kind '%s'", s.Kind)
+	}
+	switch s.Policy {
+	case "", CONTINUE, ABORT, RETRY:
+	default:
+		return fmt.Errorf("unknown failure policy '%s'", s.Policy)
+	}
+	for _, step := range append(append([]Step{}, s.Then...), s.Else...) {
+		err := step.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitTimeout returns how long a wait step waits for its condition
+func (s *Step) WaitTimeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultWaitTimeout
+	}
+	return s.Timeout
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 	"udap/internal/core/domain/common"
 )
@@ -11,7 +12,43 @@ type SubRoutine struct {
 	Icon        string        `json:"icon" gorm:"default:'􁏀'"`
 	Group       string        `json:"group"`
 	Macros      []Macro       `json:"macros" gorm:"many2many:subroutine_macros;"`
+	Scenes      []Scene       `json:"scenes" gorm:"many2many:subroutine_scenes;"`
 	Description string        `json:"description"`
+	Steps       []Step        `json:"steps" gorm:"serializer:json"`
+	Policy      string        `json:"policy" gorm:"default:'abort'"` // Failure policy for steps
+	Retries     int           `json:"retries"`
 	RevertAfter time.Duration `json:"revertAfter"`
 	LastRun     time.Time     `json:"lastRun"`
 }
+
+// Sequence returns the ordered steps of the subroutine. Subroutines without steps run their macros and then
+// their scenes.
+func (s *SubRoutine) Sequence() []Step {
+	if len(s.Steps) > 0 {
+		return s.Steps
+	}
+	var steps []Step
+	for _, macro := range s.Macros {
+		steps = append(steps, Step{Kind: MACRO, Target: macro.Id})
+	}
+	for _, scene := range s.Scenes {
+		steps = append(steps, Step{Kind: SCENE, Target: scene.Id})
+	}
+	return steps
+}
+
+// Validate checks every step of the subroutine
+func (s *SubRoutine) Validate() error {
+	for _, step := range s.Steps {
+		err := step.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	switch s.Policy {
+	case "", CONTINUE, ABORT, RETRY:
+		return nil
+	default:
+		return fmt.Errorf("unknown failure policy '%s'", s.Policy)
+	}
+}
@@ -15,10 +15,19 @@ const (
 
 type Trigger struct {
 	common.Persistent
-	Name        string    `json:"name"`
-	Type        string    `json:"type"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Module is the name of the module that registered a module trigger, only it may fire the trigger
+	Module      string    `json:"module"`
 	Description string    `json:"description"`
 	LastTrigger time.Time `json:"lastTrigger"`
+	Schedule    Schedule  `json:"schedule" gorm:"serializer:json"`
+	NextFire    time.Time `json:"nextFire"`
+}
+
+// Scheduled reports whether the trigger fires on its own schedule
+func (t *Trigger) Scheduled() bool {
+	return !t.Schedule.IsZero()
 }
 
 func NewTrigger(name string, description string, triggerType string) Trigger {
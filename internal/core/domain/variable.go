@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	STRING   = "string"
+	INT      = "int"
+	BOOL     = "bool"
+	DURATION = "duration"
+	URL      = "url"
+	SECRET   = "secret"
+)
+
+// REDACTED replaces the value of secret variables in module mutations
+const REDACTED = "********"
+
+// Variable describes a configuration value a module expects to be set
+type Variable struct {
+	Name        string   `json:"name"`
+	Default     string   `json:"default"`
+	Description string   `json:"description"`
+	Type        string   `json:"type"` // string, int, bool, duration, enum, url or secret, string if empty
+	Required    bool     `json:"required"`
+	Options     []string `json:"options,omitempty"` // The accepted values of an enum
+	Pattern     string   `json:"pattern,omitempty"` // A regular expression the value must match
+	Min         float64  `json:"min,omitempty"`     // Bounds of an int, only checked when max is greater than min
+	Max         float64  `json:"max,omitempty"`
+}
+
+// Secret reports whether the variable's value must be encrypted at rest
+func (v Variable) Secret() bool {
+	return v.Type == SECRET
+}
+
+// Validate checks a value against the variable's type and constraints
+func (v Variable) Validate(value string) error {
+	if value == "" {
+		if v.Required {
+			return fmt.Errorf("variable '%s' is required", v.Name)
+		}
+		return nil
+	}
+	switch v.Type {
+	case "", STRING, SECRET:
+	case INT:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("variable '%s' must be an integer", v.Name)
+		}
+		if v.Max > v.Min && (float64(n) < v.Min || float64(n) > v.Max) {
+			return fmt.Errorf("variable '%s' must be between %g and %g", v.Name, v.Min, v.Max)
+		}
+	case BOOL:
+		_, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("variable '%s' must be true or false", v.Name)
+		}
+	case DURATION:
+		_, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("variable '%s' must be a duration, such as '30s'", v.Name)
+		}
+	case ENUM:
+		found := false
+		for _, option := range v.Options {
+			if option == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("variable '%s' must be one of %v", v.Name, v.Options)
+		}
+	case URL:
+		_, err := url.ParseRequestURI(value)
+		if err != nil {
+			return fmt.Errorf("variable '%s' must be an absolute url", v.Name)
+		}
+	default:
+		return fmt.Errorf("variable '%s' has unknown type '%s'", v.Name, v.Type)
+	}
+	if v.Pattern != "" {
+		matched, err := regexp.MatchString(v.Pattern, value)
+		if err != nil {
+			return fmt.Errorf("variable '%s' has an invalid pattern: %s", v.Name, err.Error())
+		}
+		if !matched {
+			return fmt.Errorf("variable '%s' must match '%s'", v.Name, v.Pattern)
+		}
+	}
+	return nil
+}
+
+// Declared parses the variables the module declared when it was loaded
+func (m *Module) Declared() []Variable {
+	var variables []Variable
+	err := json.Unmarshal([]byte(m.Variables), &variables)
+	if err != nil {
+		return []Variable{}
+	}
+	return variables
+}
+
+// Variable finds a declared variable by name
+func (m *Module) Variable(name string) (Variable, bool) {
+	for _, variable := range m.Declared() {
+		if variable.Name == name {
+			return variable, true
+		}
+	}
+	return Variable{}, false
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package domain
+
+import "testing"
+
+func TestVariable_Validate(t *testing.T) {
+	tests := []struct {
+		variable Variable
+		value    string
+		valid    bool
+	}{
+		{Variable{Name: "a"}, "", true},
+		{Variable{Name: "a", Required: true}, "", false},
+		{Variable{Name: "a", Type: INT, Min: 1, Max: 10}, "5", true},
+		{Variable{Name: "a", Type: INT, Min: 1, Max: 10}, "11", false},
+		{Variable{Name: "a", Type: INT}, "five", false},
+		{Variable{Name: "a", Type: BOOL}, "true", true},
+		{Variable{Name: "a", Type: DURATION}, "30s", true},
+		{Variable{Name: "a", Type: DURATION}, "30", false},
+		{Variable{Name: "a", Type: ENUM, Options: []string{"heat", "cool"}}, "cool", true},
+		{Variable{Name: "a", Type: ENUM, Options: []string{"heat", "cool"}}, "off", false},
+		{Variable{Name: "a", Type: URL}, "https://example.com", true},
+		{Variable{Name: "a", Type: URL}, "example", false},
+		{Variable{Name: "a", Pattern: "^10\\."}, "10.0.1.1", true},
+		{Variable{Name: "a", Pattern: "^10\\."}, "192.168.1.1", false},
+		{Variable{Name: "a", Type: "color"}, "red", false},
+	}
+	for _, tt := range tests {
+		err := tt.variable.Validate(tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("Validate(%s) on %+v = %v, want valid %v", tt.value, tt.variable, err, tt.valid)
+		}
+	}
+}
@@ -10,7 +10,7 @@ import (
 
 type PersistentType interface {
 	domain.User | domain.Module | domain.Entity | domain.Device | domain.Attribute | domain.Endpoint | domain.
-		Network | domain.Zone | domain.Notification | domain.Macro | domain.Trigger | domain.SubRoutine | domain.AttributeLog | Mock
+		Network | domain.Zone | domain.Notification | domain.Macro | domain.Trigger | domain.SubRoutine | domain.AttributeLog | domain.Rule | domain.Hold | domain.Scene | domain.Invocation | domain.Action | Mock
 }
 
 type Store[T any] struct {
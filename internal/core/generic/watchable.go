@@ -5,67 +5,86 @@ package generic
 import (
 	"reflect"
 	"strings"
-	"sync"
-	"time"
+	"udap/internal/core/bus"
 	"udap/internal/core/domain"
-	"udap/internal/log"
 )
 
+// watchQueue is the number of mutations queued for each channel passed to Watch
+const watchQueue = 64
+
 type Identifiable interface {
 	GetId() string
 }
 
+// Watchable publishes changes to elements on the bus it is given, the topic is the lowercase name of the
+// element's type. Nothing is published until the service is given a bus.
 type Watchable[T Identifiable] struct {
-	channels []chan<- domain.Mutation
-	channel  chan<- domain.Mutation
-	mutex    sync.RWMutex
+	events *bus.Bus
 }
 
-func (w *Watchable[T]) Emit(element T) error {
+// UseBus sets the bus changes are published to
+func (w *Watchable[T]) UseBus(events *bus.Bus) {
+	w.events = events
+}
 
-	w.mutex.Lock()
-	if w.channels == nil {
-		w.channels = make([]chan<- domain.Mutation, 0)
-	}
-	w.mutex.Unlock()
-	classification := strings.ToLower(reflect.TypeOf(element).Name())
+func topic[T Identifiable]() bus.Topic {
+	var element T
+	return bus.Topic(strings.ToLower(reflect.TypeOf(element).Name()))
+}
 
-	eId := element.GetId()
+// Emit publishes an element's current state
+func (w *Watchable[T]) Emit(element T) error {
+	if w.events == nil {
+		return nil
+	}
+	w.events.Publish(w.Mutation(element))
+	return nil
+}
 
-	payload := domain.Mutation{
+// Mutation describes an element's current state as Emit would publish it
+func (w *Watchable[T]) Mutation(element T) domain.Mutation {
+	return domain.Mutation{
 		Status:    "update",
-		Operation: classification,
+		Operation: string(topic[T]()),
 		Body:      element,
-		Id:        eId,
+		Id:        element.GetId(),
 	}
+}
 
-	w.mutex.RLock()
-	for _, channel := range w.channels {
+// Mutations describes the current state of each element, services use it to build their snapshots
+func (w *Watchable[T]) Mutations(elements []T) []domain.Mutation {
+	mutations := make([]domain.Mutation, 0, len(elements))
+	for _, element := range elements {
+		mutations = append(mutations, w.Mutation(element))
+	}
+	return mutations
+}
 
-		// Set a timer to cancel sending after 100 milliseconds
-		timer := time.NewTimer(time.Millisecond * 500)
-		select {
-		// Attempt to push the payload to the channel
-		case channel <- payload:
-			// Cancel the timer if payload is sent
-			timer.Stop()
-			continue
-		case <-timer.C:
-			log.Event("emit failed for '%s'", payload)
-			continue
-		}
+// Publish publishes a snapshot to the bus
+func (w *Watchable[T]) Publish(snapshot []domain.Mutation) {
+	if w.events == nil {
+		return
+	}
+	for _, mutation := range snapshot {
+		w.events.Publish(mutation)
 	}
-	w.mutex.RUnlock()
-	return nil
 }
 
+// Watch forwards the mutations of the element's topic to a channel. The oldest mutations are dropped if the
+// channel falls behind, subscribe to the bus directly to choose another policy.
 func (w *Watchable[T]) Watch(ref chan<- domain.Mutation) {
-
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
-	if w.channels == nil {
-		w.channels = make([]chan<- domain.Mutation, 0)
+	if w.events == nil {
+		return
 	}
-
-	w.channels = append(w.channels, ref)
+	name := topic[T]()
+	subscription := w.events.Subscribe("watch."+string(name), bus.Options{
+		Topics: []bus.Topic{name},
+		Size:   watchQueue,
+		Policy: bus.DROP,
+	})
+	go func() {
+		for mutation := range subscription.C() {
+			ref <- mutation
+		}
+	}()
 }
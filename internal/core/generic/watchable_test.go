@@ -2,6 +2,12 @@
 
 package generic
 
+import (
+	"testing"
+	"time"
+	"udap/internal/core/bus"
+)
+
 type Basic struct {
 	Variable string
 }
@@ -47,3 +53,30 @@ type BasicService struct {
 // 	}
 //
 // }
+
+func TestWatchable_UseBus(t *testing.T) {
+	bs := BasicService{}
+	// Without a bus there is nowhere to publish to
+	err := bs.Emit(Basic{Variable: "unpublished"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	events := bus.New()
+	subscription := events.Subscribe("test", bus.Options{Size: 1, Policy: bus.BLOCK})
+	defer subscription.Close()
+	bs.UseBus(events)
+
+	err = bs.Emit(Basic{Variable: "testString"})
+	if err != nil {
+		t.Error(err)
+	}
+	select {
+	case r := <-subscription.C():
+		if r.Id != "thisIsAUniqueId" || r.Operation != "basic" {
+			t.Errorf("published %+v", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("the mutation was not published to the service's bus")
+	}
+}
@@ -10,7 +10,13 @@ import (
 func MigrateModels(db *gorm.DB) error {
 	err := db.AutoMigrate(domain.Attribute{}, domain.Entity{}, domain.Module{}, domain.Device{}, domain.Endpoint{},
 		domain.User{}, domain.Network{}, domain.Zone{}, domain.Notification{}, domain.Macro{}, domain.Trigger{},
-		domain.SubRoutine{}, domain.Action{}, domain.AttributeLog{})
+		domain.SubRoutine{}, domain.Action{}, domain.AttributeLog{}, domain.Rule{}, domain.Hold{}, domain.Scene{},
+		domain.Invocation{}, domain.Log{})
+	if err != nil {
+		return err
+	}
+	// Full text search over log messages
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_logs_message_search ON logs USING gin (to_tsvector('english', message))").Error
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+	"udap/internal/log"
+)
+
+type actionOperator struct {
+	ctrl   *controller.Controller
+	client *http.Client
+}
+
+func NewActionOperator(ctrl *controller.Controller) ports.ActionOperator {
+	return &actionOperator{
+		ctrl: ctrl,
+		client: &http.Client{
+			Timeout: time.Second * 10,
+		},
+	}
+}
+
+// Execute runs the action, passing the trigger's custom key and value through as parameters
+func (a *actionOperator) Execute(action domain.Action, key string, value string, source domain.Source) error {
+	invocation, err := a.ctrl.Invocations.Begin(domain.ACTION, action.Id, action.Name, source)
+	if err != nil {
+		return err
+	}
+	err = a.execute(action, key, value, invocation)
+	finish(a.ctrl, invocation, err)
+	return err
+}
+
+func (a *actionOperator) execute(action domain.Action, key string, value string,
+	invocation *domain.Invocation) error {
+	switch action.Kind {
+	case "", domain.REQUEST:
+		return a.request(action, key, value, invocation)
+	case domain.MACRO:
+		return a.ctrl.Macros.Run(action.Target, domain.Within(invocation))
+	case domain.SUBROUTINE:
+		return a.ctrl.SubRoutines.Run(action.Target, domain.Within(invocation))
+	case domain.SCENE:
+		return a.ctrl.Scenes.Apply(action.Target, domain.Within(invocation))
+	case domain.NOTIFY:
+		return a.ctrl.Notifications.Create(&domain.Notification{
+			Title:  action.Name,
+			Module: "actions",
+			Body:   action.Expand(action.Body, key, value),
+		})
+	case domain.HTTP:
+		return a.call(action, key, value)
+	default:
+		return fmt.Errorf("unknown action kind '%s'", action.Kind)
+	}
+}
+
+// request sets the action's attribute on each of its entities. Actions without a request value use the
+// custom value of the trigger.
+func (a *actionOperator) request(action domain.Action, key string, value string,
+	invocation *domain.Invocation) error {
+	request := action.Expand(action.Request, key, value)
+	if request == "" {
+		request = value
+	}
+	var failed error
+	for _, entity := range action.Entities {
+		err := a.ctrl.Attributes.Request(entity, action.Attribute, request)
+		if err != nil {
+			failed = err
+		}
+		err = a.ctrl.Invocations.Request(invocation.Id, entity, action.Attribute, request, err)
+		if err != nil {
+			log.Err(err)
+		}
+	}
+	return failed
+}
+
+// call makes the action's outbound http request, responses outside the 2xx range are errors
+func (a *actionOperator) call(action domain.Action, key string, value string) error {
+	method := action.Method
+	if method == "" {
+		method = http.MethodGet
+		if action.Body != "" {
+			method = http.MethodPost
+		}
+	}
+
+	// Parameters substituted into the url are escaped
+	target := strings.NewReplacer("{{key}}", url.QueryEscape(key), "{{value}}",
+		url.QueryEscape(value)).Replace(action.Url)
+
+	body := strings.NewReader(action.Expand(action.Body, key, value))
+	request, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return err
+	}
+	for header, content := range action.Headers {
+		request.Header.Set(header, action.Expand(content, key, value))
+	}
+
+	response, err := a.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("action '%s' received status %d from %s", action.Name, response.StatusCode, action.Url)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@
 package operators
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -11,16 +12,20 @@ import (
 )
 
 type attributeOperator struct {
-	hooks   map[string]chan domain.Attribute
-	request chan domain.Attribute
-	mutex   sync.RWMutex
+	hooks    map[string]chan domain.Attribute
+	entities map[string]string
+	queues   map[chan domain.Attribute]*dispatchQueue
+	request  chan domain.Attribute
+	mutex    sync.RWMutex
 }
 
 func NewAttributeOperator() ports.AttributeOperator {
 	return &attributeOperator{
-		hooks:   map[string]chan domain.Attribute{},
-		mutex:   sync.RWMutex{},
-		request: make(chan domain.Attribute, 8),
+		hooks:    map[string]chan domain.Attribute{},
+		entities: map[string]string{},
+		queues:   map[chan domain.Attribute]*dispatchQueue{},
+		mutex:    sync.RWMutex{},
+		request:  make(chan domain.Attribute, 8),
 	}
 }
 
@@ -28,43 +33,119 @@ func (a *attributeOperator) Register(attribute *domain.Attribute) error {
 	if attribute.Id == "" {
 		return fmt.Errorf("invalid attribute id")
 	}
+	if attribute.Channel == nil {
+		return fmt.Errorf("attribute channel is not set")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
 
+	previous, ok := a.hooks[attribute.Id]
 	a.hooks[attribute.Id] = attribute.Channel
+	a.entities[attribute.Id] = attribute.Entity
+
+	if _, ok := a.queues[attribute.Channel]; !ok {
+		a.queues[attribute.Channel] = newDispatchQueue(attribute.Channel)
+	}
+	// Close the queue of a channel that has been replaced, such as after a module is reloaded
+	if ok && previous != attribute.Channel {
+		for _, channel := range a.hooks {
+			if channel == previous {
+				return nil
+			}
+		}
+		a.queues[previous].close()
+		delete(a.queues, previous)
+	}
+
 	return nil
 }
 
 func (a *attributeOperator) Request(attribute *domain.Attribute, s string) error {
+	// Make sure a module is listening for this attribute
+	a.mutex.RLock()
+	_, ok := a.hooks[attribute.Id]
+	a.mutex.RUnlock()
+	if !ok {
+		attribute.State = domain.FAILED
+		return fmt.Errorf("channel is not set")
+	}
+
 	attribute.Requested = time.Now()
 	attribute.Request = s
+	// Hold the request as pending until the module confirms it
+	attribute.State = domain.PENDING
 
-	// Find the correct channel
+	return nil
+}
+
+// Dispatch queues the request for the module and waits until it is accepted or the context expires
+func (a *attributeOperator) Dispatch(ctx context.Context, attribute *domain.Attribute) error {
+	a.mutex.RLock()
 	channel, ok := a.hooks[attribute.Id]
-	if !ok {
+	queue := a.queues[channel]
+	a.mutex.RUnlock()
+	if !ok || queue == nil {
+		attribute.State = domain.FAILED
 		return fmt.Errorf("channel is not set")
 	}
 
-	// Send the request to the module for processing
-	channel <- *attribute
+	response, err := queue.push(ctx, *attribute)
+	if err != nil {
+		attribute.State = domain.FAILED
+		return err
+	}
+
+	select {
+	case err = <-response:
+	case <-ctx.Done():
+		queue.withdraw(attribute.Id, response)
+		err = domain.ErrNotConsuming
+	}
+	if err != nil {
+		attribute.State = domain.FAILED
+		return err
+	}
 
 	return nil
 }
 
-func (a *attributeOperator) Set(attribute *domain.Attribute, s string) error {
-	// If the attribute handler is not set, return an error
+// Queues reports the state of each module's request queue
+func (a *attributeOperator) Queues() []domain.DispatchQueue {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	var queues []domain.DispatchQueue
+	for channel, queue := range a.queues {
+		stats := queue.stats()
+		entities := map[string]bool{}
+		for id, hook := range a.hooks {
+			if hook == channel && !entities[a.entities[id]] {
+				entities[a.entities[id]] = true
+				stats.Entities = append(stats.Entities, a.entities[id])
+			}
+		}
+		queues = append(queues, stats)
+	}
+	return queues
+}
 
-	attribute.Request = s
+func (a *attributeOperator) Set(attribute *domain.Attribute, s string) error {
 	attribute.Value = s
-
+	// A pending request is only resolved once the module reports the requested value
+	if attribute.Pending() && attribute.Request != s {
+		return nil
+	}
+	attribute.Request = s
+	attribute.State = domain.CONFIRMED
 	return nil
 }
 
 func (a *attributeOperator) Update(attribute *domain.Attribute, val string, stamp time.Time) error {
-	//// If a request has been made in the last five seconds, and has been unresolved, ignore this update
-	//if attribute.Requested.Before(stamp) && attribute.Request != val && time.Since(attribute.Requested) < 300*time.Millisecond {
-	//	return nil
-	//}
+	// If a request is unresolved, ignore stale updates that were observed before the request was made
+	if attribute.Pending() && attribute.Request != val && stamp.Before(attribute.Requested) {
+		return nil
+	}
 	// Set the value
-
 	attribute.Updated = stamp
 	err := a.Set(attribute, val)
 	if err != nil {
@@ -73,3 +154,18 @@ func (a *attributeOperator) Update(attribute *domain.Attribute, val string, stam
 	// Return no errors
 	return nil
 }
+
+// Expire marks a pending request as timed out if it was not confirmed in time
+func (a *attributeOperator) Expire(attribute *domain.Attribute, requested time.Time) bool {
+	if !attribute.Pending() {
+		return false
+	}
+	// The stored timestamp may have lost precision in the database, so only a newer request is considered different
+	delta := attribute.Requested.Sub(requested)
+	if delta > time.Millisecond || delta < -time.Millisecond {
+		return false
+	}
+	attribute.State = domain.TIMEOUT
+	attribute.Request = attribute.Value
+	return true
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"udap/internal/core/domain"
+)
+
+// Commands endpoints can send over their websockets, in addition to SUBSCRIBE and UNSUBSCRIBE
+const (
+	ATTRIBUTEREQUEST = "attribute.request"
+	MACRORUN         = "macro.run"
+	SUBROUTINERUN    = "subroutine.run"
+	TRIGGERINVOKE    = "trigger.invoke"
+	ZONECREATE       = "zone.create"
+	ZONEUPDATE       = "zone.update"
+	ZONEDELETE       = "zone.delete"
+	ZONERESTORE      = "zone.restore"
+	ZONEPIN          = "zone.pin"
+	ZONEUNPIN        = "zone.unpin"
+	ZONEADDENTITY    = "zone.entities.add"
+	ZONEREMOVEENTITY = "zone.entities.remove"
+)
+
+// ACK is the operation of the response to a command, its status is "error" if the command failed
+const ACK = "ack"
+
+// arguments holds the arguments of commands that act on an existing element
+type arguments struct {
+	Id     string `json:"id"`
+	Entity string `json:"entity"`
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+}
+
+// Execute runs a command sent by an endpoint and acknowledges it. Subscriptions change in the order their commands
+// arrive and are acknowledged once their snapshot has been sent. Other commands run concurrently, so a slow command
+// does not hold up the rest of the connection.
+func (m *endpointOperator) Execute(endpoint string, command domain.Command) {
+	if c, ok := m.connection(endpoint); ok {
+		c.seen()
+	}
+	switch command.Operation {
+	case SUBSCRIBE, UNSUBSCRIBE:
+		result, err := m.execute(endpoint, command)
+		m.acknowledge(endpoint, command, result, err)
+	default:
+		go func() {
+			result, err := m.execute(endpoint, command)
+			m.acknowledge(endpoint, command, result, err)
+		}()
+	}
+}
+
+func (m *endpointOperator) acknowledge(endpoint string, command domain.Command, result any, err error) {
+	status := "success"
+	ack := domain.Acknowledgement{
+		Operation: command.Operation,
+		Result:    result,
+	}
+	if err != nil {
+		status = "error"
+		ack.Error = err.Error()
+	}
+	c, ok := m.connection(endpoint)
+	if !ok {
+		// The endpoint disconnected before its command finished
+		return
+	}
+	c.deliver(Response{
+		Endpoint:  endpoint,
+		Id:        command.Id,
+		Status:    status,
+		Operation: ACK,
+		Body:      ack,
+	})
+}
+
+// execute runs a command with the same services the REST routes use, commands are attributed to the endpoint.
+// Subscribing returns the position the subscription started at, which the endpoint can later resume from.
+func (m *endpointOperator) execute(endpoint string, command domain.Command) (any, error) {
+	source := domain.Source{
+		Kind: domain.ENDPOINT,
+		Id:   endpoint,
+	}
+
+	switch command.Operation {
+	case SUBSCRIBE:
+		request := struct {
+			domain.Subscription
+			Resume domain.Position `json:"resume"`
+		}{}
+		err := decode(command.Body, &request)
+		if err != nil {
+			return nil, err
+		}
+		result, err := m.subscribe(endpoint, command.Id, request.Subscription, request.Resume)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	case UNSUBSCRIBE:
+		return nil, m.Unsubscribe(endpoint, command.Id)
+	case ZONECREATE, ZONEUPDATE:
+		zone := domain.Zone{}
+		err := decode(command.Body, &zone)
+		if err != nil {
+			return nil, err
+		}
+		if command.Operation == ZONECREATE {
+			return nil, m.controller.Zones.Create(&zone)
+		}
+		return nil, m.controller.Zones.Update(&zone)
+	}
+
+	args := arguments{}
+	err := decode(command.Body, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch command.Operation {
+	case ATTRIBUTEREQUEST:
+		if args.Entity == "" || args.Key == "" {
+			return nil, fmt.Errorf("entity and key must be provided")
+		}
+		return nil, m.controller.Attributes.RequestContext(context.Background(), args.Entity, args.Key, args.Value)
+	case MACRORUN, SUBROUTINERUN, TRIGGERINVOKE, ZONEDELETE, ZONERESTORE, ZONEPIN, ZONEUNPIN:
+		if args.Id == "" {
+			return nil, fmt.Errorf("id must be provided")
+		}
+	case ZONEADDENTITY, ZONEREMOVEENTITY:
+		if args.Id == "" || args.Entity == "" {
+			return nil, fmt.Errorf("id and entity must be provided")
+		}
+	default:
+		return nil, fmt.Errorf("unknown command '%s'", command.Operation)
+	}
+
+	switch command.Operation {
+	case MACRORUN:
+		return nil, m.controller.Macros.Run(args.Id, source)
+	case SUBROUTINERUN:
+		return nil, m.controller.SubRoutines.Run(args.Id, source)
+	case TRIGGERINVOKE:
+		trigger, err := m.controller.Triggers.FindById(args.Id)
+		if err != nil {
+			return nil, err
+		}
+		return nil, m.controller.Triggers.TriggerFrom(trigger.Name, source)
+	case ZONEDELETE:
+		return nil, m.controller.Zones.Delete(args.Id)
+	case ZONERESTORE:
+		return nil, m.controller.Zones.Restore(args.Id)
+	case ZONEPIN:
+		return nil, m.controller.Zones.Pin(args.Id)
+	case ZONEUNPIN:
+		return nil, m.controller.Zones.Unpin(args.Id)
+	case ZONEADDENTITY:
+		return nil, m.controller.Zones.AddEntity(args.Id, args.Entity)
+	default:
+		return nil, m.controller.Zones.RemoveEntity(args.Id, args.Entity)
+	}
+}
+
+// decode reads the body of a command, commands without a body leave the target empty
+func decode(body json.RawMessage, target any) error {
+	if len(body) == 0 {
+		return nil
+	}
+	err := json.Unmarshal(body, target)
+	if err != nil {
+		return fmt.Errorf("invalid command body: %s", err.Error())
+	}
+	return nil
+}
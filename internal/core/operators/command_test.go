@@ -0,0 +1,50 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"encoding/json"
+	"testing"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type runMacros struct {
+	ports.MacroService
+	ran    string
+	source domain.Source
+}
+
+func (m *runMacros) Run(id string, source domain.Source) error {
+	m.ran = id
+	m.source = source
+	return nil
+}
+
+func TestEndpointOperator_Execute(t *testing.T) {
+	macros := &runMacros{}
+	op := &endpointOperator{
+		controller: &controller.Controller{Macros: macros},
+	}
+
+	tests := []struct {
+		command domain.Command
+		failed  bool
+	}{
+		{domain.Command{Id: "1", Operation: MACRORUN, Body: json.RawMessage(`{"id":"m1"}`)}, false},
+		{domain.Command{Id: "2", Operation: MACRORUN, Body: json.RawMessage(`{}`)}, true},
+		{domain.Command{Id: "3", Operation: MACRORUN, Body: json.RawMessage(`[`)}, true},
+		{domain.Command{Id: "4", Operation: "macro.explode"}, true},
+	}
+	for _, tt := range tests {
+		_, err := op.execute("e1", tt.command)
+		if (err != nil) != tt.failed {
+			t.Errorf("command %s returned %v, want failure %v", tt.command.Id, err, tt.failed)
+		}
+	}
+
+	if macros.ran != "m1" || macros.source.Kind != domain.ENDPOINT || macros.source.Id != "e1" {
+		t.Errorf("macro command ran %s from %+v", macros.ran, macros.source)
+	}
+}
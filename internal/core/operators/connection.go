@@ -0,0 +1,146 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"github.com/gorilla/websocket"
+	"sync"
+	"sync/atomic"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/log"
+)
+
+const (
+	// connectionQueue is the number of messages an endpoint can fall behind by before it is disconnected, it leaves
+	// room for a full replay
+	connectionQueue = replaySize * 2
+	// writeWait is how long a write to an endpoint may take
+	writeWait = time.Second * 10
+	// pongWait is how long an endpoint may go without answering a ping
+	pongWait = time.Second * 60
+	// pingPeriod is how often endpoints are pinged, it must be shorter than pongWait
+	pingPeriod = pongWait * 9 / 10
+)
+
+// connection is the websocket of an enrolled endpoint. Messages are queued and written by the connection's own
+// writer, so a slow endpoint only holds up itself.
+type connection struct {
+	// The counters are accessed atomically, they come first to stay aligned on 32-bit platforms
+	sent    uint64
+	dropped uint64
+	// lastSeen is when the endpoint last answered a ping or sent a message, in unix nanoseconds
+	lastSeen int64
+	endpoint *domain.Endpoint
+	conn     *websocket.Conn
+	queue    chan Response
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newConnection(endpoint *domain.Endpoint) *connection {
+	c := &connection{
+		endpoint: endpoint,
+		conn:     endpoint.Connection,
+		queue:    make(chan Response, connectionQueue),
+		done:     make(chan struct{}),
+	}
+	c.seen()
+	// The endpoint's reader extends its deadline each time a ping is answered
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.seen()
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	go c.write()
+	return c
+}
+
+// seen records that the endpoint is still there
+func (c *connection) seen() {
+	atomic.StoreInt64(&c.lastSeen, time.Now().UnixNano())
+}
+
+// send queues a message without waiting. An endpoint whose queue is full is not keeping up, the message is dropped
+// and the endpoint is disconnected so it can resume once it reconnects.
+func (c *connection) send(response Response) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	select {
+	case c.queue <- response:
+	default:
+		atomic.AddUint64(&c.dropped, 1)
+		log.Event("Endpoint '%s' is not keeping up and was disconnected.", c.endpoint.Name)
+		c.close()
+	}
+}
+
+// deliver queues a message, waiting for room as long as a write may take. Snapshots and acknowledgements are
+// delivered so their size alone does not disconnect the endpoint.
+func (c *connection) deliver(response Response) {
+	timer := time.NewTimer(writeWait)
+	defer timer.Stop()
+	select {
+	case c.queue <- response:
+	case <-c.done:
+	case <-timer.C:
+		atomic.AddUint64(&c.dropped, 1)
+		log.Event("Endpoint '%s' is not keeping up and was disconnected.", c.endpoint.Name)
+		c.close()
+	}
+}
+
+// close stops the writer and closes the websocket, which ends the endpoint's reader so it unenrolls
+func (c *connection) close() {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
+
+// shutdown tells the endpoint the server is going away before closing the connection
+func (c *connection) shutdown() {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+	_ = c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeWait))
+	c.close()
+}
+
+// write sends queued messages and pings to the endpoint until the connection is closed or a write fails
+func (c *connection) write() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	defer c.close()
+	for {
+		select {
+		case response := <-c.queue:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			err := c.conn.WriteJSON(response)
+			if err != nil {
+				return
+			}
+			atomic.AddUint64(&c.sent, 1)
+		case <-ticker.C:
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait))
+			if err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// stats reports the traffic of the connection
+func (c *connection) stats() domain.EndpointStats {
+	return domain.EndpointStats{
+		Id:       c.endpoint.Id,
+		Name:     c.endpoint.Name,
+		Queued:   len(c.queue),
+		Sent:     atomic.LoadUint64(&c.sent),
+		Dropped:  atomic.LoadUint64(&c.dropped),
+		LastSeen: time.Unix(0, atomic.LoadInt64(&c.lastSeen)),
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// dial connects a websocket client to a test server, returning the client and the server's side of the connection
+func dial(t *testing.T) (*websocket.Conn, *websocket.Conn) {
+	accepted := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %s", err)
+			return
+		}
+		accepted <- conn
+	}))
+	t.Cleanup(server.Close)
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client, <-accepted
+}
+
+func TestConnection_Send(t *testing.T) {
+	client, conn := dial(t)
+	endpoint := &domain.Endpoint{Name: "panel", Connection: conn}
+	c := newConnection(endpoint)
+	defer c.close()
+
+	c.send(Response{Operation: "attribute", Sequence: 1})
+	c.send(Response{Operation: "attribute", Sequence: 2})
+
+	for want := uint64(1); want <= 2; want++ {
+		received := Response{}
+		err := client.ReadJSON(&received)
+		if err != nil || received.Sequence != want {
+			t.Fatalf("received %+v (%v), want sequence %d", received, err, want)
+		}
+	}
+	// The writer counts a message once it has been written
+	time.Sleep(time.Millisecond * 10)
+	if stats := c.stats(); stats.Sent != 2 || stats.Dropped != 0 || stats.Name != "panel" {
+		t.Errorf("stats are %+v", stats)
+	}
+}
+
+func TestConnection_SlowConsumer(t *testing.T) {
+	client, conn := dial(t)
+	// Without a writer nothing leaves the queue, like an endpoint that has stopped reading
+	c := &connection{
+		endpoint: &domain.Endpoint{Name: "panel", Connection: conn},
+		conn:     conn,
+		queue:    make(chan Response, 1),
+		done:     make(chan struct{}),
+	}
+
+	c.send(Response{Operation: "attribute"})
+	c.send(Response{Operation: "attribute"})
+
+	select {
+	case <-c.done:
+	default:
+		t.Fatalf("an endpoint with a full queue should be disconnected")
+	}
+	if stats := c.stats(); stats.Dropped != 1 || stats.Queued != 1 {
+		t.Errorf("stats are %+v", stats)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := client.ReadMessage(); err == nil {
+		t.Errorf("the disconnected endpoint's websocket should be closed")
+	}
+}
@@ -5,6 +5,9 @@ package operators
 import (
 	"fmt"
 	"github.com/gorilla/websocket"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 	"udap/internal/controller"
 	"udap/internal/core/domain"
@@ -14,14 +17,35 @@ import (
 )
 
 const (
-	ENROLL   = "enroll"
-	UNENROLL = "unenroll"
+	ENROLL      = "enroll"
+	UNENROLL    = "unenroll"
+	SUBSCRIBE   = "subscribe"
+	UNSUBSCRIBE = "unsubscribe"
+	// RELEASE sends the mutations held for a connection while its snapshot was queued
+	RELEASE = "release"
 )
 
 type endpointOperation struct {
 	operation string
 	endpoint  *domain.Endpoint
-	response  chan error
+	// target is the id of the endpoint a subscription operation applies to
+	target       string
+	subscription string
+	filter       *subscription
+	// resume is the position a subscription continues from, the listener reports the outcome in subscribed
+	resume     domain.Position
+	subscribed *subscribed
+	// connection is the connection whose held mutations are released
+	connection *connection
+	response   chan error
+}
+
+// subscribed is the result of a subscription, the position is where the subscription started or resumed
+type subscribed struct {
+	domain.Position
+	Resumed bool `json:"resumed"`
+	// connection is the connection the snapshot is queued to, its mutations are held until the snapshot is queued
+	connection *connection
 }
 
 func (e *endpointOperation) Respond(err error) {
@@ -32,7 +56,7 @@ func (e *endpointOperation) Respond(err error) {
 }
 
 func newOperation(operation string, endpoint *domain.Endpoint) (endpointOperation, chan error) {
-	response := make(chan error)
+	response := make(chan error, 1)
 	return endpointOperation{
 		response:  response,
 		operation: operation,
@@ -41,18 +65,43 @@ func newOperation(operation string, endpoint *domain.Endpoint) (endpointOperatio
 }
 
 type endpointOperator struct {
-	local         map[string]*domain.Endpoint
+	// connections holds the connection of each enrolled endpoint. Only the listener changes it, other goroutines
+	// read it with the mutex held.
+	connections map[string]*connection
+	mutex       sync.RWMutex
+	// subscriptions holds the subscriptions of each enrolled endpoint by the id the endpoint gave them
+	subscriptions map[string]map[string]*subscription
+	// holding counts the snapshots being queued to each connection, mutations are held in held until they are all
+	// queued so none reaches the endpoint ahead of an older snapshot
+	holding map[*connection]int
+	held    map[*connection][]Response
+	replay  *replay
+	// epoch distinguishes the sequences of this operator from those of earlier runs, see newEpoch
+	epoch int64
+	// lost is set when mutations were lost before the listener could sequence them, it is accessed atomically
+	lost          uint32
 	localChannel  chan endpointOperation
 	localTransmit chan Response
 	done          chan bool
 	controller    *controller.Controller
 }
 
+// newEpoch starts a new epoch in unix milliseconds. Endpoints read positions as javascript numbers, which are only
+// exact up to 2^53, so a finer epoch would be rounded and never match when an endpoint resumes.
+func newEpoch() int64 {
+	return time.Now().UnixMilli()
+}
+
 func NewEndpointOperator(controller *controller.Controller) ports.EndpointOperator {
 
 	op := &endpointOperator{
 		controller:    controller,
-		local:         map[string]*domain.Endpoint{},
+		connections:   map[string]*connection{},
+		subscriptions: map[string]map[string]*subscription{},
+		holding:       map[*connection]int{},
+		held:          map[*connection][]Response{},
+		replay:        newReplay(replaySize),
+		epoch:         newEpoch(),
 		done:          make(chan bool),
 		localChannel:  make(chan endpointOperation, 2),
 		localTransmit: make(chan Response, 8),
@@ -69,17 +118,38 @@ func NewEndpointOperator(controller *controller.Controller) ports.EndpointOperat
 }
 
 func (m *endpointOperator) handleShutdown() {
-	records := m.local
-	for _, endpoint := range records {
-		_ = endpoint.Connection.WriteMessage(websocket.CloseGoingAway, nil)
+	for _, c := range m.connections {
+		c.shutdown()
+	}
+}
+
+// connection finds the connection of an enrolled endpoint, it can be called from any goroutine
+func (m *endpointOperator) connection(id string) (*connection, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	c, ok := m.connections[id]
+	return c, ok
+}
+
+// subscribed reports whether any of an endpoint's subscriptions match a mutation. Every subscription sees the
+// mutation so the zones they follow stay up to date.
+func (m *endpointOperator) subscribed(id string, mutation domain.Mutation) bool {
+	matched := false
+	for _, s := range m.subscriptions[id] {
+		if s.matches(mutation) {
+			matched = true
+		}
 	}
+	return matched
 }
 
 func (m *endpointOperator) transmitSingle(transmission Response) error {
-	err := m.local[transmission.Endpoint].Connection.WriteJSON(transmission)
-	if err != nil {
-		return err
+	c, ok := m.connections[transmission.Endpoint]
+	if !ok {
+		// The endpoint unenrolled while the transmission was queued
+		return nil
 	}
+	c.send(transmission)
 	return nil
 }
 
@@ -87,38 +157,126 @@ func (m *endpointOperator) handleTransmit(transmission Response) error {
 	if transmission.Endpoint != "" {
 		return m.transmitSingle(transmission)
 	}
-	records := m.local
-	for _, endpoint := range records {
-		err := endpoint.Connection.WriteJSON(transmission)
-		if err != nil {
-			go func() {
-				err = m.Unenroll(endpoint)
-				if err != nil {
-					log.Err(err)
-				}
-			}()
+	if !ephemeral[transmission.Operation] {
+		m.replay.record(&transmission)
+	}
+	mutation := transmission.mutation()
+	for id, c := range m.connections {
+		if !m.subscribed(id, mutation) {
+			continue
 		}
+		if m.holding[c] > 0 {
+			m.hold(c, transmission)
+			continue
+		}
+		c.send(transmission)
 	}
 	return nil
 }
 
+// hold keeps a mutation until the connection's snapshots are queued. An endpoint that falls a full queue behind
+// meanwhile is not keeping up, it is disconnected like it would be by sending.
+func (m *endpointOperator) hold(c *connection, transmission Response) {
+	if len(m.held[c]) >= connectionQueue {
+		log.Event("Endpoint '%s' is not keeping up and was disconnected.", c.endpoint.Name)
+		c.close()
+		m.forget(c)
+		return
+	}
+	m.held[c] = append(m.held[c], transmission)
+}
+
+// release sends the mutations held for a connection once the last of its snapshots is queued
+func (m *endpointOperator) release(c *connection) {
+	if m.holding[c] > 1 {
+		m.holding[c]--
+		return
+	}
+	held := m.held[c]
+	m.forget(c)
+	for _, transmission := range held {
+		c.send(transmission)
+	}
+}
+
+// forget stops holding mutations for a connection
+func (m *endpointOperator) forget(c *connection) {
+	delete(m.holding, c)
+	delete(m.held, c)
+}
+
 func (m *endpointOperator) handleOperation(operation endpointOperation) error {
 	endpoint := operation.endpoint
 	switch operation.operation {
 	case ENROLL:
-		ref := m.local[endpoint.Id]
-		if ref != nil {
-			operation.Respond(fmt.Errorf("endpoint already enrolled"))
+		// An endpoint that reconnects before its previous connection was noticed to be gone replaces it
+		if previous, ok := m.connections[endpoint.Id]; ok {
+			previous.close()
+			m.forget(previous)
 		}
-		m.local[endpoint.Id] = operation.endpoint
+		m.mutex.Lock()
+		m.connections[endpoint.Id] = newConnection(endpoint)
+		m.mutex.Unlock()
+		m.subscriptions[endpoint.Id] = map[string]*subscription{}
 		operation.Respond(nil)
 		break
 	case UNENROLL:
-		ref := m.local[endpoint.Id]
-		if ref == nil {
+		c, ok := m.connections[endpoint.Id]
+		if !ok {
+			operation.Respond(fmt.Errorf("endpoint is not enrolled"))
+			break
+		}
+		if c.conn != endpoint.Connection {
+			operation.Respond(domain.ErrReplaced)
+			break
+		}
+		c.close()
+		m.forget(c)
+		m.mutex.Lock()
+		delete(m.connections, endpoint.Id)
+		m.mutex.Unlock()
+		delete(m.subscriptions, endpoint.Id)
+		operation.Respond(nil)
+		break
+	case SUBSCRIBE:
+		subscriptions, ok := m.subscriptions[operation.target]
+		if !ok {
 			operation.Respond(fmt.Errorf("endpoint is not enrolled"))
+			break
+		}
+		subscriptions[operation.subscription] = operation.filter
+		operation.subscribed.Position = domain.Position{
+			Epoch:    m.epoch,
+			Sequence: m.replay.sequence,
+		}
+		if operation.resume.Epoch == m.epoch {
+			operation.subscribed.Resumed = m.resume(operation.target, operation.filter, operation.resume.Sequence)
+		}
+		if !operation.subscribed.Resumed {
+			// Mutations are held from now until the snapshot is queued, the subscriber releases them
+			c := m.connections[operation.target]
+			m.holding[c]++
+			operation.subscribed.connection = c
+		}
+		operation.Respond(nil)
+		break
+	case RELEASE:
+		if _, ok := m.holding[operation.connection]; ok {
+			m.release(operation.connection)
 		}
-		delete(m.local, endpoint.Id)
+		operation.Respond(nil)
+		break
+	case UNSUBSCRIBE:
+		subscriptions, ok := m.subscriptions[operation.target]
+		if !ok {
+			operation.Respond(fmt.Errorf("endpoint is not enrolled"))
+			break
+		}
+		if _, ok = subscriptions[operation.subscription]; !ok {
+			operation.Respond(fmt.Errorf("subscription '%s' does not exist", operation.subscription))
+			break
+		}
+		delete(subscriptions, operation.subscription)
 		operation.Respond(nil)
 		break
 	default:
@@ -127,16 +285,38 @@ func (m *endpointOperator) handleOperation(operation endpointOperation) error {
 	return nil
 }
 
+// Resync tells the operator that mutations were lost before they reached it, it can be called from any goroutine.
+// Endpoints receive a snapshot in place of the lost mutations.
+func (m *endpointOperator) Resync() {
+	atomic.StoreUint32(&m.lost, 1)
+}
+
+// handleLoss is run by the listener before each operation and transmission. Once mutations were lost the replay
+// no longer holds everything that followed earlier positions, so it skips the loss and connected endpoints are
+// disconnected, they resubscribe when they reconnect and receive a snapshot.
+func (m *endpointOperator) handleLoss() {
+	if atomic.SwapUint32(&m.lost, 0) == 0 {
+		return
+	}
+	m.replay.skip()
+	for _, c := range m.connections {
+		c.close()
+	}
+	log.Event("Mutations were lost before reaching endpoints, endpoints will resubscribe.")
+}
+
 func (m *endpointOperator) listen() error {
 	for {
 		select {
 		case operation := <-m.localChannel:
+			m.handleLoss()
 			err := m.handleOperation(operation)
 			if err != nil {
 				log.Err(err)
 				continue
 			}
 		case transmission := <-m.localTransmit:
+			m.handleLoss()
 			err := m.handleTransmit(transmission)
 			if err != nil {
 				log.Err(err)
@@ -162,12 +342,6 @@ func (m *endpointOperator) enrollEndpoint(endpoint *domain.Endpoint) error {
 		return err
 	}
 
-	err = m.controller.EmitAll()
-	if err != nil {
-		log.Err(err)
-		return nil
-	}
-
 	log.Event("Endpoint '%s' enrolled.", endpoint.Name)
 	return nil
 }
@@ -182,6 +356,126 @@ func (m *endpointOperator) unenrollEndpoint(endpoint *domain.Endpoint) error {
 	return nil
 }
 
+// resume sends an endpoint the mutations matching a subscription that followed a sequence, it reports false without
+// sending anything if some of them are no longer kept
+func (m *endpointOperator) resume(endpoint string, filter *subscription, sequence uint64) bool {
+	missed, ok := m.replay.since(sequence)
+	if !ok {
+		return false
+	}
+	for _, transmission := range missed {
+		if !filter.matches(transmission.mutation()) {
+			continue
+		}
+		transmission.Endpoint = endpoint
+		err := m.transmitSingle(transmission)
+		if err != nil {
+			log.Err(err)
+		}
+	}
+	return true
+}
+
+// Subscribe adds or replaces a subscription of an enrolled endpoint, then sends the endpoint a snapshot of the
+// elements the subscription selects. Mutations matching the subscription are sent from then on.
+func (m *endpointOperator) Subscribe(endpoint string, id string, filter domain.Subscription) error {
+	_, err := m.subscribe(endpoint, id, filter, domain.Position{})
+	return err
+}
+
+// subscribe adds or replaces a subscription like Subscribe. If the endpoint resumes from a position whose following
+// mutations are still kept, those matching the subscription are sent in place of the snapshot.
+func (m *endpointOperator) subscribe(endpoint string, id string, filter domain.Subscription,
+	resume domain.Position) (subscribed, error) {
+	var zones []domain.Zone
+	for _, zone := range filter.Zones {
+		z, err := m.controller.Zones.FindById(zone)
+		if err != nil {
+			return subscribed{}, err
+		}
+		zones = append(zones, *z)
+	}
+
+	// The subscription is registered before the snapshot is taken so no mutation falls between them
+	operation := endpointOperation{
+		operation:    SUBSCRIBE,
+		target:       endpoint,
+		subscription: id,
+		filter:       newSubscription(filter, zones),
+		resume:       resume,
+		subscribed:   &subscribed{},
+		response:     make(chan error, 1),
+	}
+	m.localChannel <- operation
+	if err := <-operation.response; err != nil {
+		return subscribed{}, err
+	}
+	result := *operation.subscribed
+	if result.Resumed {
+		return result, nil
+	}
+	c := result.connection
+	defer m.releaseHeld(c)
+
+	snapshot, err := m.controller.Snapshot(filter.Operations)
+	if err != nil {
+		return result, err
+	}
+	// The registered subscription belongs to the listener, the snapshot is matched against a copy
+	matching := newSubscription(filter, zones)
+	for _, mutation := range snapshot {
+		if !matching.matches(mutation) {
+			continue
+		}
+		c.deliver(Response{
+			Endpoint:  endpoint,
+			Id:        mutation.Id,
+			Status:    "success",
+			Operation: mutation.Operation,
+			Body:      mutation.Body,
+			Sequence:  result.Sequence,
+		})
+	}
+	return result, nil
+}
+
+// releaseHeld asks the listener to send the mutations held for a connection while its snapshot was queued
+func (m *endpointOperator) releaseHeld(c *connection) {
+	operation := endpointOperation{
+		operation:  RELEASE,
+		connection: c,
+		response:   make(chan error, 1),
+	}
+	m.localChannel <- operation
+	<-operation.response
+}
+
+// Unsubscribe removes a subscription of an enrolled endpoint
+func (m *endpointOperator) Unsubscribe(endpoint string, id string) error {
+	operation := endpointOperation{
+		operation:    UNSUBSCRIBE,
+		target:       endpoint,
+		subscription: id,
+		response:     make(chan error, 1),
+	}
+	m.localChannel <- operation
+	return <-operation.response
+}
+
+// Stats reports the traffic of each enrolled endpoint, ordered by name
+func (m *endpointOperator) Stats() []domain.EndpointStats {
+	m.mutex.RLock()
+	stats := make([]domain.EndpointStats, 0, len(m.connections))
+	for _, c := range m.connections {
+		stats = append(stats, c.stats())
+	}
+	m.mutex.RUnlock()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
 func (m *endpointOperator) CloseAll() error {
 	m.done <- true
 	return nil
@@ -206,7 +500,10 @@ func (m *endpointOperator) SendAll(id string, operation string, payload any) err
 		return nil
 	case <-timer.C:
 		log.Event("transit transmission timed out")
-		// Exit quietly if the payload could not be sent
+		// Endpoints that missed the payload resubscribe, the timeout is not an error of the caller
+		if !ephemeral[operation] {
+			m.Resync()
+		}
 		return nil
 	}
 
@@ -231,12 +528,24 @@ type Metadata struct {
 	System common.System `json:"system"`
 }
 
+// Response is a message sent to endpoints. Mutations carry the sequence they were sent at, snapshots carry the
+// sequence their subscription started at.
 type Response struct {
 	Endpoint  string `json:"endpoint"`
 	Id        string `json:"id"`
 	Status    string `json:"status"`
 	Operation string `json:"operation"`
 	Body      any    `json:"body"`
+	Sequence  uint64 `json:"sequence"`
+}
+
+func (r Response) mutation() domain.Mutation {
+	return domain.Mutation{
+		Status:    r.Status,
+		Operation: r.Operation,
+		Body:      r.Body,
+		Id:        r.Id,
+	}
 }
 
 func (m *endpointOperator) sendMetadata(id string) error {
@@ -244,13 +553,17 @@ func (m *endpointOperator) sendMetadata(id string) error {
 	if err != nil {
 		return err
 	}
-	m.localTransmit <- Response{
+	c, ok := m.connection(id)
+	if !ok {
+		return fmt.Errorf("endpoint is not enrolled")
+	}
+	c.deliver(Response{
 		Endpoint:  id,
 		Id:        "",
 		Status:    "success",
 		Operation: "metadata",
 		Body:      Metadata{System: info},
-	}
+	})
 	return nil
 }
 
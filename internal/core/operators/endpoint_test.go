@@ -0,0 +1,106 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+	"udap/internal/core/ports"
+)
+
+func TestNewEpoch_JavaScriptSafe(t *testing.T) {
+	position := domain.Position{Epoch: newEpoch(), Sequence: replaySize}
+	encoded, err := json.Marshal(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Endpoints decode every number as a float64 before sending the position back to resume from
+	client := map[string]float64{}
+	err = json.Unmarshal(encoded, &client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resent, err := json.Marshal(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resume := domain.Position{}
+	err = json.Unmarshal(resent, &resume)
+	if err != nil {
+		t.Fatalf("the endpoint's position could not be read: %s", err)
+	}
+	if resume != position {
+		t.Errorf("position %+v came back from the endpoint as %+v", position, resume)
+	}
+}
+
+func TestEndpointOperator_Resync(t *testing.T) {
+	_, conn := dial(t)
+	c := newConnection(&domain.Endpoint{Name: "panel", Connection: conn})
+	defer c.close()
+	m := &endpointOperator{
+		connections: map[string]*connection{"e1": c},
+		replay:      newReplay(4),
+	}
+	m.replay.record(&Response{Operation: "attribute"})
+
+	m.handleLoss()
+	if _, ok := m.replay.since(0); !ok {
+		t.Fatalf("nothing was lost, the endpoint should be able to resume")
+	}
+
+	m.Resync()
+	m.handleLoss()
+	if _, ok := m.replay.since(1); ok {
+		t.Errorf("the endpoint should not resume across lost mutations")
+	}
+	select {
+	case <-c.done:
+	default:
+		t.Errorf("connected endpoints should be disconnected so they resubscribe")
+	}
+}
+
+// changingEntities changes an entity while its snapshot is being taken
+type changingEntities struct {
+	ports.EntityService
+	operator *endpointOperator
+}
+
+func (e *changingEntities) Snapshot() ([]domain.Mutation, error) {
+	_ = e.operator.SendAll("lamp", "entity", "new")
+	// Let the listener handle the change before the older snapshot is queued
+	time.Sleep(time.Millisecond * 20)
+	return []domain.Mutation{{Status: "update", Operation: "entity", Id: "lamp", Body: "old"}}, nil
+}
+
+func TestEndpointOperator_SnapshotBeforeMutations(t *testing.T) {
+	client, conn := dial(t)
+	entities := &changingEntities{}
+	m := NewEndpointOperator(&controller.Controller{Entities: entities}).(*endpointOperator)
+	entities.operator = m
+
+	operation, response := newOperation(ENROLL, &domain.Endpoint{Persistent: common.Persistent{Id: "e1"},
+		Name: "panel", Connection: conn})
+	m.localChannel <- operation
+	if err := <-response; err != nil {
+		t.Fatal(err)
+	}
+	err := m.Subscribe("e1", "s1", domain.Subscription{Operations: []string{"entity"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"old", "new"} {
+		received := Response{}
+		_ = client.SetReadDeadline(time.Now().Add(time.Second))
+		err = client.ReadJSON(&received)
+		if err != nil || received.Body != want {
+			t.Fatalf("received %+v (%v), want the %s state", received, err, want)
+		}
+	}
+}
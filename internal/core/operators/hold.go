@@ -0,0 +1,24 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type holdOperator struct {
+	ctrl *controller.Controller
+}
+
+func NewHoldOperator(ctrl *controller.Controller) ports.HoldOperator {
+	return &holdOperator{
+		ctrl: ctrl,
+	}
+}
+
+// Restore requests the value the attribute had before the hold was placed
+func (h *holdOperator) Restore(hold domain.Hold) error {
+	return h.ctrl.Attributes.Request(hold.Entity, hold.Key, hold.Value)
+}
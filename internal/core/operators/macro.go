@@ -3,119 +3,81 @@
 package operators
 
 import (
+	"fmt"
+	"strings"
 	"time"
 	"udap/internal/controller"
 	"udap/internal/core/domain"
 	"udap/internal/core/ports"
+	"udap/internal/log"
 )
 
 type macroOperator struct {
-	ctrl     *controller.Controller
-	launcher chan<- domain.Macro
-	holds    map[string]*time.Timer
-	revert   map[string]domain.Macro
+	ctrl *controller.Controller
 }
 
 func NewMacroOperator(ctrl *controller.Controller) ports.MacroOperator {
-	mo := &macroOperator{
-		ctrl:   ctrl,
-		holds:  map[string]*time.Timer{},
-		revert: map[string]domain.Macro{},
+	return &macroOperator{
+		ctrl: ctrl,
 	}
+}
 
-	//go func() {
-	//	select {
-	//	case <-launcher:
-	//
-	//	}
-	//
-	//	for macro, timer := range mo.holds {
-	//		mo.runRevert(macro)
-	//	}
-	//}()
-
-	return mo
+func (m *macroOperator) Run(macro domain.Macro, source domain.Source) error {
+	return m.RunAndRevert(macro, source, 0)
 }
 
-func (m *macroOperator) runRevert(macro domain.Macro) error {
-	zone, err := m.ctrl.Zones.FindById(macro.ZoneId)
+// RunAndRevert runs the macro and places a hold on each attribute it changes, so the previous values are
+// restored once the revert duration has passed. Holds are placed on behalf of the source, so a subroutine
+// that fires again extends its own holds.
+func (m *macroOperator) RunAndRevert(macro domain.Macro, source domain.Source, revert time.Duration) error {
+	invocation, err := m.ctrl.Invocations.Begin(domain.MACRO, macro.Id, macro.Name, source)
 	if err != nil {
 		return err
 	}
-	for _, entity := range zone.Entities {
-		err = m.ctrl.Attributes.Request(entity.Id, macro.Type, macro.Value)
-		if err != nil {
-			continue
-		}
-	}
-	return nil
+	err = m.run(macro, source, revert, invocation)
+	finish(m.ctrl, invocation, err)
+	return err
 }
 
-func (m *macroOperator) Run(macro domain.Macro) error {
+func (m *macroOperator) run(macro domain.Macro, source domain.Source, revert time.Duration,
+	invocation *domain.Invocation) error {
 	zone, err := m.ctrl.Zones.FindById(macro.ZoneId)
 	if err != nil {
 		return err
 	}
+
+	var failed []string
 	for _, entity := range zone.Entities {
-		err = m.ctrl.Attributes.Request(entity.Id, macro.Type, macro.Value)
+		err = m.request(macro, source, revert, invocation, entity.Id)
 		if err != nil {
-			continue
+			log.ErrF(err, "macro '%s' could not request '%s.%s'", macro.Name, entity.Name, macro.Type)
+			failed = append(failed, entity.Name)
 		}
 	}
-	//if m.holds[macro.Id] != nil {
-	//	m.holds[macro.Id].Stop()
-	//	delete(m.holds, macro.Id)
-	//	delete(m.revert, macro.Id)
-	//}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("macro '%s' failed for %d of %d entities: %s", macro.Name, len(failed),
+			len(zone.Entities), strings.Join(failed, ", "))
+	}
 
 	return nil
 }
 
-func (m *macroOperator) RunAndRevert(macro domain.Macro, baseline domain.Macro, revert time.Duration) error {
-	zone, err := m.ctrl.Zones.FindById(macro.ZoneId)
-	if err != nil {
-		return err
+// request applies the macro to a single entity. The hold is only placed once the request has been accepted, so a
+// failed request is never reverted.
+func (m *macroOperator) request(macro domain.Macro, source domain.Source, revert time.Duration,
+	invocation *domain.Invocation, entity string) error {
+	// The attribute is read before the request so the hold keeps the value it had before the macro
+	attr, err := m.ctrl.Attributes.FindByComposite(entity, macro.Type)
+	if err == nil {
+		err = m.ctrl.Attributes.Request(entity, macro.Type, macro.Value)
 	}
-
-	values := map[string]string{}
-
-	for _, entity := range zone.Entities {
-		var attr *domain.Attribute
-		attr, err = m.ctrl.Attributes.FindByComposite(entity.Id, macro.Type)
-		if err != nil {
-			return err
-		}
-		values[attr.Id] = attr.Value
-		err = m.ctrl.Attributes.Request(entity.Id, macro.Type, macro.Value)
-		if err != nil {
-			continue
-		}
+	record := m.ctrl.Invocations.Request(invocation.Id, entity, macro.Type, macro.Value, err)
+	if record != nil {
+		log.Err(record)
 	}
-
-	if revert == 0 {
-		return nil
+	if err != nil || revert <= 0 {
+		return err
 	}
-
-	////begin := time.Now()
-	////tag := fmt.Sprintf("macro.%s.%s", macro.Id, begin)
-	//if m.holds[macro.Id] != nil {
-	//	m.holds[macro.Id].Reset(revert * time.Minute)
-	//} else {
-	//	m.holds[macro.Id] = time.NewTimer(revert * time.Minute)
-	//	go func() {
-	//		_ = <-m.holds[macro.Id].C
-	//		if m.holds[macro.Id] != nil {
-	//			log.Event("REVERTING '%s'.", macro.Name)
-	//			err = m.runRevert(m.revert[macro.Id])
-	//			if err != nil {
-	//				return
-	//			}
-	//			delete(m.holds, macro.Id)
-	//			delete(m.revert, macro.Id)
-	//		}
-	//
-	//	}()
-	//}
-
-	return nil
+	return m.ctrl.Holds.Hold(source.Id, macro.Id, *attr, macro.Value, revert)
 }
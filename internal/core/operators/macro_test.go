@@ -0,0 +1,78 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type macroZones struct {
+	ports.ZoneService
+	zone domain.Zone
+}
+
+func (z *macroZones) FindById(string) (*domain.Zone, error) {
+	return &z.zone, nil
+}
+
+type macroAttributes struct {
+	ports.AttributeService
+	failing string
+}
+
+func (a *macroAttributes) FindByComposite(entity string, key string) (*domain.Attribute, error) {
+	return &domain.Attribute{Entity: entity, Key: key, Request: "false"}, nil
+}
+
+func (a *macroAttributes) Request(entity string, key string, value string) error {
+	if entity == a.failing {
+		return fmt.Errorf("module is not responding")
+	}
+	return nil
+}
+
+type macroInvocations struct {
+	ports.InvocationService
+}
+
+func (i *macroInvocations) Request(string, string, string, string, error) error {
+	return nil
+}
+
+type macroHolds struct {
+	ports.HoldService
+	held []string
+}
+
+func (h *macroHolds) Hold(_ string, _ string, attribute domain.Attribute, _ string, _ time.Duration) error {
+	h.held = append(h.held, attribute.Entity)
+	return nil
+}
+
+func TestMacroOperator_RunFailedRequest(t *testing.T) {
+	zones := &macroZones{}
+	zones.zone.Entities = []domain.Entity{{Name: "lamp"}, {Name: "fan"}}
+	zones.zone.Entities[0].Id = "lamp"
+	zones.zone.Entities[1].Id = "fan"
+	holds := &macroHolds{}
+	op := &macroOperator{ctrl: &controller.Controller{
+		Zones:       zones,
+		Attributes:  &macroAttributes{failing: "fan"},
+		Invocations: &macroInvocations{},
+		Holds:       holds,
+	}}
+
+	macro := domain.Macro{Name: "on", Type: "on", Value: "true"}
+	err := op.run(macro, domain.Source{}, time.Minute, &domain.Invocation{})
+	if err == nil {
+		t.Fatalf("a macro with a failed request should fail")
+	}
+	if len(holds.held) != 1 || holds.held[0] != "lamp" {
+		t.Errorf("holds were placed on %v, want only the lamp", holds.held)
+	}
+}
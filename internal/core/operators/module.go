@@ -18,19 +18,35 @@ import (
 	"udap/internal/core/ports"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
+	"udap/internal/plugin/scope"
 )
 
 const PATH = "./modules"
 
+// emitQueue is the number of mutations buffered for each subscribed module
+const emitQueue = 64
+
 type lifeCycle struct {
-	iface plugin.ModuleInterface
-	mutex sync.Mutex
+	iface    plugin.ModuleInterface
+	name     string
+	binary   string
+	disposed bool
+	// Mutations matching any of the filters are queued for the module
+	filters   []domain.MutationFilter
+	queue     chan domain.Mutation
+	done      chan struct{}
+	dropped   int
+	congested bool
+	mutex     sync.Mutex
 }
 
-func newLifeCycle(iface plugin.ModuleInterface) *lifeCycle {
+func newLifeCycle(iface plugin.ModuleInterface, name string, binary string) *lifeCycle {
 	return &lifeCycle{
-		iface: iface,
-		mutex: sync.Mutex{},
+		iface:  iface,
+		name:   name,
+		binary: binary,
+		mutex:  sync.Mutex{},
 	}
 }
 
@@ -48,13 +64,131 @@ func NewModuleOperator(ctrl *controller.Controller) ports.ModuleOperator {
 	}
 }
 
+// HandleEmit queues a mutation for each module subscribed to it. Modules that fall behind have mutations
+// dropped rather than holding up the system.
 func (m *moduleRuntime) HandleEmit(mutation domain.Mutation) error {
+	m.moduleMutex.RLock()
+	var subscribed []*lifeCycle
+	for _, runtime := range m.runtime {
+		runtime.mutex.Lock()
+		if runtime.queue != nil && !runtime.disposed {
+			subscribed = append(subscribed, runtime)
+		}
+		runtime.mutex.Unlock()
+	}
+	m.moduleMutex.RUnlock()
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	entity, module, key := m.describe(mutation)
+	for _, runtime := range subscribed {
+		runtime.mutex.Lock()
+		matched := false
+		for _, filter := range runtime.filters {
+			owner := ""
+			if len(filter.Modules) > 0 {
+				owner = module()
+			}
+			if filter.Matches(mutation.Operation, entity, owner, key) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			select {
+			case runtime.queue <- mutation:
+				runtime.congested = false
+			default:
+				runtime.dropped++
+				if !runtime.congested {
+					runtime.congested = true
+					log.Event("Module '%s' is not keeping up with its subscriptions, %d mutations dropped",
+						runtime.name, runtime.dropped)
+				}
+			}
+		}
+		runtime.mutex.Unlock()
+	}
 	return nil
 }
 
+// describe provides the entity, owning module and attribute key of a mutation's body. The module is looked up
+// only when a filter needs it.
+func (m *moduleRuntime) describe(mutation domain.Mutation) (string, func() string, string) {
+	switch body := mutation.Body.(type) {
+	case domain.Attribute:
+		var owner *string
+		return body.Entity, func() string {
+			if owner == nil {
+				name := ""
+				entity, err := m.ctrl.Entities.FindById(body.Entity)
+				if err == nil {
+					name = entity.Module
+				}
+				owner = &name
+			}
+			return *owner
+		}, body.Key
+	case domain.Entity:
+		return body.Id, func() string { return body.Module }, ""
+	case domain.Module:
+		return "", func() string { return body.Name }, ""
+	default:
+		return mutation.Id, func() string { return "" }, ""
+	}
+}
+
+// Subscribe adds a filter to the mutations delivered to a module through OnEmit
+func (m *moduleRuntime) Subscribe(uuid string, filter domain.MutationFilter) error {
+	runtime := m.lifeCycle(uuid)
+	if runtime == nil {
+		return fmt.Errorf("module lifecycle not found")
+	}
+	runtime.mutex.Lock()
+	defer runtime.mutex.Unlock()
+	runtime.filters = append(runtime.filters, filter)
+	if runtime.queue == nil {
+		runtime.queue = make(chan domain.Mutation, emitQueue)
+		runtime.done = make(chan struct{})
+		go m.deliver(uuid, runtime)
+	}
+	return nil
+}
+
+// deliver passes queued mutations to the module one at a time until the module is removed
+func (m *moduleRuntime) deliver(uuid string, runtime *lifeCycle) {
+	for {
+		select {
+		case mutation := <-runtime.queue:
+			err := m.emit(uuid, runtime.iface, mutation)
+			if err != nil {
+				log.Err(err)
+			}
+		case <-runtime.done:
+			return
+		}
+	}
+}
+
+func (m *moduleRuntime) emit(uuid string, iface plugin.ModuleInterface, mutation domain.Mutation) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = m.ctrl.Modules.Fault(uuid, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+	return iface.OnEmit(mutation)
+}
+
+func (m *moduleRuntime) lifeCycle(id string) *lifeCycle {
+	m.moduleMutex.RLock()
+	defer m.moduleMutex.RUnlock()
+	return m.runtime[id]
+}
+
 func (m *moduleRuntime) getModule(id string) (plugin.ModuleInterface, error) {
 
-	runtime := m.runtime[id]
+	runtime := m.lifeCycle(id)
 	if runtime == nil {
 		return nil, fmt.Errorf("module lifecycle not found")
 	}
@@ -74,7 +208,7 @@ func (m *moduleRuntime) getModule(id string) (plugin.ModuleInterface, error) {
 
 func (m *moduleRuntime) returnModule(id string, moduleInterface plugin.ModuleInterface) error {
 
-	runtime := m.runtime[id]
+	runtime := m.lifeCycle(id)
 	if runtime == nil {
 		return fmt.Errorf("module lifecycle not found")
 	}
@@ -91,17 +225,28 @@ func (m *moduleRuntime) returnModule(id string, moduleInterface plugin.ModuleInt
 	return nil
 }
 
-func (m *moduleRuntime) setModule(id string, moduleInterface plugin.ModuleInterface) error {
-	m.runtime[id] = newLifeCycle(moduleInterface)
+func (m *moduleRuntime) setModule(id string, name string, moduleInterface plugin.ModuleInterface,
+	binary string) error {
+	m.moduleMutex.Lock()
+	defer m.moduleMutex.Unlock()
+	m.runtime[id] = newLifeCycle(moduleInterface, name, binary)
 	return nil
 }
 
 func (m *moduleRuntime) removeModule(id string) error {
+	m.moduleMutex.Lock()
+	defer m.moduleMutex.Unlock()
 	runtime := m.runtime[id]
 	if runtime == nil {
 		return fmt.Errorf("module lifecycle not found")
 	}
 	delete(m.runtime, id)
+	// Stop delivering mutations
+	runtime.mutex.Lock()
+	if runtime.done != nil {
+		close(runtime.done)
+	}
+	runtime.mutex.Unlock()
 	return nil
 }
 
@@ -130,17 +275,20 @@ func generateSourcePath(module string) string {
 	return fmt.Sprintf("%s/%s/%s.go", PATH, module, module)
 }
 
-// generateBuildPath generates the path to a module's binary file
-func generateBuildPath(module string, uuid string) string {
+// generateBuildPath generates the path to a module's binary file, process modules are built as executables
+func generateBuildPath(module string, uuid string, runtime string) string {
+	if runtime == domain.PROCESS {
+		return fmt.Sprintf("%s/%s/%s-%s.bin", PATH, module, module, uuid)
+	}
 	return fmt.Sprintf("%s/%s/%s-%s.so", PATH, module, module, uuid)
 }
 
 // Build will compile a valid plugin file into a readable binary
-func (m *moduleRuntime) Build(module string, uuid string) error {
+func (m *moduleRuntime) Build(module string, uuid string, runtime string) error {
 	// Generate the source file path from the module name
 	sourcePath := generateSourcePath(module)
 	// Generate the output build file path
-	buildPath := generateBuildPath(module, uuid)
+	buildPath := generateBuildPath(module, uuid, runtime)
 	// Confirm that the source file exists
 	if _, err := os.Stat(sourcePath); err != nil {
 		return err
@@ -151,6 +299,10 @@ func (m *moduleRuntime) Build(module string, uuid string) error {
 	defer cancelFunc()
 	// Prepare the command arguments
 	args := []string{"build", "-v", "-buildmode=plugin", "-o", buildPath, sourcePath}
+	if runtime == domain.PROCESS {
+		// Process modules provide a main function that serves the module
+		args = []string{"build", "-v", "-o", buildPath, sourcePath}
+	}
 	// Initialize the command structure
 	cmd := exec.CommandContext(timeout, "go", args...)
 	// Run and get the stdout and stderr from the output
@@ -161,7 +313,7 @@ func (m *moduleRuntime) Build(module string, uuid string) error {
 	return nil
 }
 
-// Cleanup deletes all compiled binaries in the module folder (.so)
+// Cleanup deletes all compiled binaries in the module folder (.so, .bin)
 func (m *moduleRuntime) Cleanup(module string) error {
 	// Get the path to the target directory
 	directory := fmt.Sprintf("%s/%s", PATH, module)
@@ -174,8 +326,8 @@ func (m *moduleRuntime) Cleanup(module string) error {
 	var toDelete []string
 	// Go through each entry and find out if it is a binary
 	for _, entry := range dir {
-		// Check if the filename ends with the extension '.so'
-		if strings.HasSuffix(entry.Name(), ".so") {
+		// Check if the filename ends with the extension '.so' or '.bin'
+		if strings.HasSuffix(entry.Name(), ".so") || strings.HasSuffix(entry.Name(), ".bin") {
 			// Append to delete list with the path
 			toDelete = append(toDelete, entry.Name())
 		}
@@ -197,52 +349,108 @@ func (m *moduleRuntime) Cleanup(module string) error {
 
 // Load is used to find a pre-built plugin file, and load it into the local system.
 // The module reference should be saved to the repository after loading.
-func (m *moduleRuntime) Load(module string, uuid string) (domain.ModuleConfig, error) {
+func (m *moduleRuntime) Load(module string, uuid string, runtime string) (domain.ModuleConfig, error) {
 	// Create the binary file path
-	binary := generateBuildPath(module, uuid)
-	// Attempt to load the plugin binary
-	p, err := plugin.Load(binary)
+	binary := generateBuildPath(module, uuid, runtime)
+	// Attempt to load the plugin binary, or start the module's process
+	mod, err := m.open(module, uuid, binary, runtime)
 	if err != nil {
 		return domain.ModuleConfig{}, err
 	}
-	// Extract the plugin interface
-	mod := p.(plugin.ModuleInterface)
-	if mod == nil {
-		return domain.ModuleConfig{}, fmt.Errorf("cannot read module")
-	}
-	// Connect the module to the UDAP runtime
-	err = mod.Connect(m.ctrl, uuid)
+	// Connect the module to the UDAP runtime, the module can only reach the system through its scope
+	limits := scope.New(m.ctrl, module, uuid)
+	err = mod.Connect(limits.Controller(), uuid)
 	if err != nil {
+		abandon(mod)
 		return domain.ModuleConfig{}, err
 	}
 	// Run the setup method
 	setup, err := mod.Setup()
 	if err != nil {
+		abandon(mod)
 		return domain.ModuleConfig{}, err
 	}
+	// Apply the grants the module declared
+	limits.Grant(setup.Grants)
 	// Emplace the module into the local buffer
-	err = m.setModule(uuid, mod)
+	err = m.setModule(uuid, module, mod, binary)
 	if err != nil {
 		return domain.ModuleConfig{}, err
 	}
+	// Report module processes that stop on their own
+	if proxy, ok := mod.(*process.Proxy); ok {
+		go m.watch(uuid, proxy)
+	}
 
 	marshal, err := json.Marshal(setup.Variables)
 	if err != nil {
 		return domain.ModuleConfig{}, err
 	}
 
+	grants, err := json.Marshal(setup.Grants)
+	if err != nil {
+		return domain.ModuleConfig{}, err
+	}
+
 	conf := domain.ModuleConfig{
 		Name:        setup.Name,
 		Type:        setup.Type,
 		Description: setup.Description,
 		Interval:    setup.Interval,
+		Jitter:      setup.Jitter,
 		Version:     setup.Version,
 		Author:      setup.Author,
 		Variables:   string(marshal),
+		Grants:      string(grants),
 	}
 	return conf, nil
 }
 
+// open provides the interface of a built module. Plugin modules are loaded into this process, process modules
+// are started and connected through a proxy.
+func (m *moduleRuntime) open(module string, uuid string, binary string, runtime string) (plugin.ModuleInterface,
+	error) {
+	if runtime == domain.PROCESS {
+		return process.Start(module, uuid, binary)
+	}
+	p, err := plugin.Load(binary)
+	if err != nil {
+		return nil, err
+	}
+	// Extract the plugin interface
+	mod := p.(plugin.ModuleInterface)
+	if mod == nil {
+		return nil, fmt.Errorf("cannot read module")
+	}
+	return mod, nil
+}
+
+// abandon stops the process of a module that failed to load, plugins cannot be unloaded
+func abandon(mod plugin.ModuleInterface) {
+	if proxy, ok := mod.(*process.Proxy); ok {
+		_ = proxy.Dispose()
+	}
+}
+
+// watch reports a module process that exits without being disposed to the module supervisor
+func (m *moduleRuntime) watch(uuid string, proxy *process.Proxy) {
+	<-proxy.Exited()
+	runtime := m.lifeCycle(uuid)
+	if runtime == nil {
+		return
+	}
+	runtime.mutex.Lock()
+	disposed := runtime.disposed
+	runtime.mutex.Unlock()
+	if disposed {
+		return
+	}
+	err := m.ctrl.Modules.Fault(uuid, "module process exited")
+	if err != nil {
+		log.Err(err)
+	}
+}
+
 // Dispose is called at the end of the lifecycle, it attempts to halt activity.
 func (m *moduleRuntime) Dispose(module string, uuid string) error {
 	// Get the local module
@@ -251,15 +459,23 @@ func (m *moduleRuntime) Dispose(module string, uuid string) error {
 		return err
 	}
 
-	binaryPath := generateBuildPath(module, uuid)
+	runtime := m.lifeCycle(uuid)
+	binaryPath := runtime.binary
 	// Confirm that the binary file exists
 	if _, err = os.Stat(binaryPath); err != nil {
 		return err
 	}
+	runtime.mutex.Lock()
+	runtime.disposed = true
+	runtime.mutex.Unlock()
 	// Dispose of the local module
 	err = local.Dispose()
 	if err != nil {
-		return err
+		// Module processes are stopped even if they fail to dispose
+		if _, ok := local.(*process.Proxy); !ok {
+			return err
+		}
+		log.Err(err)
 	}
 	// Remove the file when the function exits
 	defer func() {
@@ -324,3 +540,12 @@ func (m *moduleRuntime) Update(uuid string) error {
 
 	return nil
 }
+
+// Configure passes a changed variable to a running module
+func (m *moduleRuntime) Configure(uuid string, key string, value string) error {
+	local, err := m.getModule(uuid)
+	if err != nil {
+		return err
+	}
+	return local.ConfigChanged(key, value)
+}
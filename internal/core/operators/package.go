@@ -0,0 +1,117 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"udap/internal/core/domain"
+	"udap/internal/plugin/archive"
+)
+
+// Packages are unpacked into staging before being installed, the version they replace is kept as the previous
+// version so it can be restored
+const (
+	STAGING  = PATH + "/.staging"
+	PREVIOUS = PATH + "/.previous"
+)
+
+// exists reports whether a file or directory exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Unpack extracts a module package into staging and returns its manifest
+func (m *moduleRuntime) Unpack(r io.Reader) (domain.Manifest, error) {
+	err := os.MkdirAll(STAGING, 0755)
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	temp, err := os.MkdirTemp(STAGING, "package-")
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	manifest, err := archive.Unpack(r, temp)
+	if err != nil {
+		_ = os.RemoveAll(temp)
+		return domain.Manifest{}, err
+	}
+	// Replace any package of the same module left in staging
+	staged := fmt.Sprintf("%s/%s", STAGING, manifest.Name)
+	err = os.RemoveAll(staged)
+	if err != nil {
+		_ = os.RemoveAll(temp)
+		return domain.Manifest{}, err
+	}
+	err = os.Rename(temp, staged)
+	if err != nil {
+		_ = os.RemoveAll(temp)
+		return domain.Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// Staged reads the manifest of a module's package in staging
+func (m *moduleRuntime) Staged(module string) (domain.Manifest, error) {
+	return archive.ReadManifest(fmt.Sprintf("%s/%s", STAGING, module))
+}
+
+// Discard removes a module's package from staging
+func (m *moduleRuntime) Discard(module string) error {
+	return os.RemoveAll(fmt.Sprintf("%s/%s", STAGING, module))
+}
+
+// Install moves a staged package into the modules directory, the installed version becomes the previous version
+func (m *moduleRuntime) Install(module string) error {
+	staged := fmt.Sprintf("%s/%s", STAGING, module)
+	if !exists(staged) {
+		return fmt.Errorf("module '%s' has no staged package", module)
+	}
+	current := fmt.Sprintf("%s/%s", PATH, module)
+	if exists(current) {
+		err := os.MkdirAll(PREVIOUS, 0755)
+		if err != nil {
+			return err
+		}
+		previous := fmt.Sprintf("%s/%s", PREVIOUS, module)
+		err = os.RemoveAll(previous)
+		if err != nil {
+			return err
+		}
+		err = os.Rename(current, previous)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(staged, current)
+}
+
+// Revert swaps the installed and previous versions of a module, reverting again restores the installed version
+func (m *moduleRuntime) Revert(module string) error {
+	current := fmt.Sprintf("%s/%s", PATH, module)
+	previous := fmt.Sprintf("%s/%s", PREVIOUS, module)
+	if !exists(previous) {
+		return fmt.Errorf("module '%s' has no previous version", module)
+	}
+	swap := fmt.Sprintf("%s/%s.swap", PREVIOUS, module)
+	err := os.RemoveAll(swap)
+	if err != nil {
+		return err
+	}
+	if exists(current) {
+		err = os.Rename(current, swap)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.Rename(previous, current)
+	if err != nil {
+		return err
+	}
+	if !exists(swap) {
+		return nil
+	}
+	return os.Rename(swap, previous)
+}
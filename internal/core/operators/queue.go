@@ -0,0 +1,207 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// QueueCapacity is the number of distinct attributes that may be waiting on a single module
+const QueueCapacity = 32
+
+type dispatch struct {
+	attribute domain.Attribute
+	ctx       context.Context
+	waiters   []waiter
+}
+
+// waiter is a caller waiting on a request, coalesced requests are sent with the context of the latest caller
+type waiter struct {
+	ctx      context.Context
+	response chan error
+}
+
+// dispatchQueue buffers requests for a single module channel, only the latest request per attribute is kept
+type dispatchQueue struct {
+	channel chan domain.Attribute
+	order   []string
+	pending map[string]*dispatch
+	signal  chan bool
+	done    chan bool
+	mutex   sync.Mutex
+
+	delivered int
+	coalesced int
+	failed    int
+	sending   bool
+	lastSent  time.Time
+}
+
+func newDispatchQueue(channel chan domain.Attribute) *dispatchQueue {
+	q := &dispatchQueue{
+		channel: channel,
+		order:   []string{},
+		pending: map[string]*dispatch{},
+		signal:  make(chan bool, 1),
+		done:    make(chan bool),
+	}
+	go q.listen()
+	return q
+}
+
+// push queues an attribute request, replacing any undelivered request for the same attribute
+func (q *dispatchQueue) push(ctx context.Context, attribute domain.Attribute) (chan error, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	response := make(chan error, 1)
+
+	if existing, ok := q.pending[attribute.Id]; ok {
+		existing.attribute = attribute
+		existing.ctx = ctx
+		existing.waiters = append(existing.waiters, waiter{ctx, response})
+		q.coalesced++
+		return response, nil
+	}
+
+	if len(q.order) >= QueueCapacity {
+		q.failed++
+		return nil, domain.ErrQueueFull
+	}
+
+	q.pending[attribute.Id] = &dispatch{
+		attribute: attribute,
+		ctx:       ctx,
+		waiters:   []waiter{{ctx, response}},
+	}
+	q.order = append(q.order, attribute.Id)
+
+	select {
+	case q.signal <- true:
+	default:
+	}
+
+	return response, nil
+}
+
+// withdraw removes a caller from an undelivered request, the request itself is only removed once no caller is left
+// waiting on it. It returns false if the request is already being sent.
+func (q *dispatchQueue) withdraw(id string, response chan error) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	withdrawn, ok := q.pending[id]
+	if !ok {
+		return false
+	}
+	for i, w := range withdrawn.waiters {
+		if w.response == response {
+			w.response <- domain.ErrNotConsuming
+			withdrawn.waiters = append(withdrawn.waiters[:i], withdrawn.waiters[i+1:]...)
+			break
+		}
+	}
+	if len(withdrawn.waiters) > 0 {
+		// The remaining callers are still waiting, the request is sent with the latest of their contexts
+		withdrawn.ctx = withdrawn.waiters[len(withdrawn.waiters)-1].ctx
+		return true
+	}
+	delete(q.pending, id)
+	for i, queued := range q.order {
+		if queued == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+	q.failed++
+	return true
+}
+
+func (q *dispatchQueue) pop() *dispatch {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.order) == 0 {
+		return nil
+	}
+	id := q.order[0]
+	q.order = q.order[1:]
+	next := q.pending[id]
+	delete(q.pending, id)
+	q.sending = true
+	return next
+}
+
+func (q *dispatchQueue) resolve(next *dispatch, err error) {
+	q.mutex.Lock()
+	q.sending = false
+	if err == nil {
+		q.delivered++
+		q.lastSent = time.Now()
+	} else {
+		q.failed++
+	}
+	q.mutex.Unlock()
+	for _, w := range next.waiters {
+		w.response <- err
+	}
+}
+
+// send delivers a request to the module, a closed module channel is reported as an error
+func (q *dispatchQueue) send(next *dispatch) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("module channel is closed")
+		}
+	}()
+	select {
+	case q.channel <- next.attribute:
+		return nil
+	case <-next.ctx.Done():
+		return domain.ErrNotConsuming
+	case <-q.done:
+		return fmt.Errorf("module queue was closed")
+	}
+}
+
+func (q *dispatchQueue) listen() {
+	for {
+		select {
+		case <-q.signal:
+			for next := q.pop(); next != nil; next = q.pop() {
+				q.resolve(next, q.send(next))
+			}
+		case <-q.done:
+			return
+		}
+	}
+}
+
+func (q *dispatchQueue) close() {
+	close(q.done)
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, id := range q.order {
+		for _, w := range q.pending[id].waiters {
+			w.response <- fmt.Errorf("module queue was closed")
+		}
+	}
+	q.order = []string{}
+	q.pending = map[string]*dispatch{}
+}
+
+func (q *dispatchQueue) stats() domain.DispatchQueue {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return domain.DispatchQueue{
+		Depth:     len(q.order),
+		Capacity:  QueueCapacity,
+		Delivered: q.delivered,
+		Coalesced: q.coalesced,
+		Failed:    q.failed,
+		Sending:   q.sending,
+		LastSent:  q.lastSent,
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"udap/internal/core/domain"
+)
+
+func TestAttributeOperator_DispatchNotConsuming(t *testing.T) {
+	op := NewAttributeOperator()
+	attribute := domain.NewToggleAttribute("entity")
+	attribute.Id = "attribute"
+
+	err := op.Register(&attribute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err = op.Dispatch(ctx, &attribute)
+	if !errors.Is(err, domain.ErrNotConsuming) {
+		t.Errorf("expected ErrNotConsuming, got %v", err)
+	}
+	if attribute.State != domain.FAILED {
+		t.Errorf("expected failed state, got '%s'", attribute.State)
+	}
+}
+
+func TestDispatchQueue_Coalesce(t *testing.T) {
+	channel := make(chan domain.Attribute)
+	queue := newDispatchQueue(channel)
+	defer queue.close()
+
+	// Hold the listener on the first request so the next two are queued together
+	first := domain.Attribute{Request: "0"}
+	first.Id = "blocker"
+	_, err := queue.push(context.Background(), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for queue.stats().Depth != 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	attribute := domain.Attribute{Request: "1"}
+	attribute.Id = "attribute"
+	older, err := queue.push(context.Background(), attribute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attribute.Request = "2"
+	newer, err := queue.push(context.Background(), attribute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if depth := queue.stats().Depth; depth != 1 {
+		t.Errorf("expected a depth of 1, got %d", depth)
+	}
+
+	<-channel
+	received := <-channel
+	if received.Request != "2" {
+		t.Errorf("expected the latest request, got '%s'", received.Request)
+	}
+	if err = <-older; err != nil {
+		t.Error(err)
+	}
+	if err = <-newer; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDispatchQueue_WithdrawCoalesced(t *testing.T) {
+	channel := make(chan domain.Attribute)
+	queue := newDispatchQueue(channel)
+	defer queue.close()
+
+	first := domain.Attribute{Request: "0"}
+	first.Id = "blocker"
+	_, err := queue.push(context.Background(), first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for queue.stats().Depth != 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	attribute := domain.Attribute{Request: "1"}
+	attribute.Id = "attribute"
+	older, err := queue.push(context.Background(), attribute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attribute.Request = "2"
+	newer, err := queue.push(context.Background(), attribute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The older caller gives up, the newer caller is still waiting on the request
+	queue.withdraw(attribute.Id, older)
+	if err = <-older; !errors.Is(err, domain.ErrNotConsuming) {
+		t.Errorf("expected ErrNotConsuming for the withdrawn caller, got %v", err)
+	}
+	if depth := queue.stats().Depth; depth != 1 {
+		t.Errorf("expected a depth of 1, got %d", depth)
+	}
+
+	<-channel
+	received := <-channel
+	if received.Request != "2" {
+		t.Errorf("expected the latest request, got '%s'", received.Request)
+	}
+	if err = <-newer; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+// replaySize is the number of mutations kept for endpoints that reconnect, endpoints that missed more receive a
+// snapshot instead
+const replaySize = 4096
+
+// ephemeral operations are only current for a moment, they are neither sequenced nor replayed
+var ephemeral = map[string]bool{
+	"timing": true,
+}
+
+// replay numbers the mutations sent to endpoints and keeps the most recent of them, so an endpoint that briefly
+// loses its connection can resume from the last mutation it received
+type replay struct {
+	entries  []Response
+	head     int
+	count    int
+	sequence uint64
+}
+
+func newReplay(size int) *replay {
+	return &replay{
+		entries: make([]Response, size),
+	}
+}
+
+// record assigns the next sequence to a mutation and keeps it
+func (r *replay) record(response *Response) {
+	r.sequence++
+	response.Sequence = r.sequence
+	r.entries[(r.head+r.count)%len(r.entries)] = *response
+	if r.count < len(r.entries) {
+		r.count++
+		return
+	}
+	r.head = (r.head + 1) % len(r.entries)
+}
+
+// skip accounts for mutations that were lost before they could be recorded. Their sequence is skipped and the kept
+// mutations are forgotten, so no endpoint can resume across the loss.
+func (r *replay) skip() {
+	r.sequence++
+	r.head = 0
+	r.count = 0
+}
+
+// since returns the mutations that followed a sequence, ok is false when some of them are no longer kept
+func (r *replay) since(sequence uint64) (missed []Response, ok bool) {
+	if sequence > r.sequence {
+		return nil, false
+	}
+	gap := int(r.sequence - sequence)
+	if gap > r.count {
+		return nil, false
+	}
+	for i := r.count - gap; i < r.count; i++ {
+		missed = append(missed, r.entries[(r.head+i)%len(r.entries)])
+	}
+	return missed, true
+}
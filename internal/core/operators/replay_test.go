@@ -0,0 +1,55 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"testing"
+)
+
+func TestReplay_Since(t *testing.T) {
+	r := newReplay(4)
+	for i := 0; i < 6; i++ {
+		r.record(&Response{Operation: "attribute"})
+	}
+
+	tests := []struct {
+		sequence uint64
+		want     []uint64
+		ok       bool
+	}{
+		{6, nil, true},
+		{4, []uint64{5, 6}, true},
+		{2, []uint64{3, 4, 5, 6}, true},
+		{1, nil, false},
+		{7, nil, false},
+	}
+	for _, tt := range tests {
+		missed, ok := r.since(tt.sequence)
+		if ok != tt.ok || len(missed) != len(tt.want) {
+			t.Errorf("since(%d) = %d mutations, %v, want %v, %v", tt.sequence, len(missed), ok, tt.want, tt.ok)
+			continue
+		}
+		for i, response := range missed {
+			if response.Sequence != tt.want[i] {
+				t.Errorf("since(%d) returned sequence %d at %d, want %d", tt.sequence, response.Sequence, i,
+					tt.want[i])
+			}
+		}
+	}
+}
+
+func TestReplay_Skip(t *testing.T) {
+	r := newReplay(4)
+	r.record(&Response{Operation: "attribute"})
+	r.record(&Response{Operation: "attribute"})
+	r.skip()
+	r.record(&Response{Operation: "attribute"})
+
+	if _, ok := r.since(2); ok {
+		t.Errorf("an endpoint should not resume across lost mutations")
+	}
+	missed, ok := r.since(3)
+	if !ok || len(missed) != 1 || missed[0].Sequence != 4 {
+		t.Errorf("since(3) = %+v, %v, want sequence 4", missed, ok)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"fmt"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type ruleOperator struct {
+	ctrl *controller.Controller
+}
+
+func NewRuleOperator(ctrl *controller.Controller) ports.RuleOperator {
+	return &ruleOperator{
+		ctrl: ctrl,
+	}
+}
+
+// Evaluate checks the time of day window and the conditions placed on other attributes
+func (r *ruleOperator) Evaluate(rule domain.Rule) (bool, error) {
+	if !rule.InWindow(time.Now()) {
+		return false, nil
+	}
+	for _, condition := range rule.Conditions {
+		attribute, err := r.ctrl.Attributes.FindByComposite(condition.Entity, condition.Key)
+		if err != nil {
+			return false, err
+		}
+		if !condition.Holds(attribute.Value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Run invokes the trigger, subroutine or macro targeted by the rule
+func (r *ruleOperator) Run(rule domain.Rule) error {
+	source := domain.Source{Kind: domain.RULE, Id: rule.Id}
+	switch rule.Target {
+	case domain.TRIGGER:
+		trigger, err := r.ctrl.Triggers.FindById(rule.TargetId)
+		if err != nil {
+			return err
+		}
+		return r.ctrl.Triggers.TriggerFrom(trigger.Name, source)
+	case domain.SUBROUTINE:
+		return r.ctrl.SubRoutines.Run(rule.TargetId, source)
+	case domain.MACRO:
+		return r.ctrl.Macros.Run(rule.TargetId, source)
+	default:
+		return fmt.Errorf("unknown rule target '%s'", rule.Target)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+	"udap/internal/log"
+)
+
+const (
+	sceneStepInterval = time.Millisecond * 250
+	sceneMaxSteps     = 40
+)
+
+type sceneOperator struct {
+	ctrl *controller.Controller
+}
+
+func NewSceneOperator(ctrl *controller.Controller) ports.SceneOperator {
+	return &sceneOperator{
+		ctrl: ctrl,
+	}
+}
+
+// sceneTarget pairs a resolved attribute with the value the scene requests for it
+type sceneTarget struct {
+	attribute domain.Attribute
+	value     string
+}
+
+// Capture records the current value of the provided keys for every entity in the zone
+func (s *sceneOperator) Capture(zoneId string, keys []string) ([]domain.SceneValue, error) {
+	zone, err := s.ctrl.Zones.FindById(zoneId)
+	if err != nil {
+		return nil, err
+	}
+	var values []domain.SceneValue
+	for _, entity := range zone.Entities {
+		for _, key := range keys {
+			attr, err := s.ctrl.Attributes.FindByComposite(entity.Id, key)
+			if err != nil {
+				continue
+			}
+			values = append(values, domain.SceneValue{
+				Entity: entity.Id,
+				Key:    key,
+				Value:  attr.Value,
+			})
+		}
+	}
+	return values, nil
+}
+
+// Apply validates every value in the scene before requesting any of them, so a scene is either applied in full
+// or not at all. Range attributes are faded over the transition when one is provided, the invocation finishes once
+// the last step has been requested.
+func (s *sceneOperator) Apply(scene domain.Scene, transition time.Duration, source domain.Source) error {
+	invocation, err := s.ctrl.Invocations.Begin(domain.SCENE, scene.Id, scene.Name, source)
+	if err != nil {
+		return err
+	}
+	targets, err := s.resolve(scene)
+	if err != nil {
+		finish(s.ctrl, invocation, err)
+		return err
+	}
+
+	steps := int(transition / sceneStepInterval)
+	if steps > sceneMaxSteps {
+		steps = sceneMaxSteps
+	}
+	if steps < 2 {
+		var failed error
+		for _, target := range targets {
+			err = s.request(invocation.Id, target.attribute, target.value)
+			if err != nil && failed == nil {
+				failed = err
+			}
+		}
+		finish(s.ctrl, invocation, failed)
+		return failed
+	}
+
+	go s.transition(invocation, targets, steps, transition/time.Duration(steps))
+	return nil
+}
+
+// resolve finds the attribute of every value in the scene and validates the value against it
+func (s *sceneOperator) resolve(scene domain.Scene) ([]sceneTarget, error) {
+	var targets []sceneTarget
+	for _, value := range scene.Values {
+		attr, err := s.ctrl.Attributes.FindByComposite(value.Entity, value.Key)
+		if err != nil {
+			return nil, fmt.Errorf("scene '%s' references missing attribute '%s.%s'", scene.Name, value.Entity,
+				value.Key)
+		}
+		err = attr.Validate(value.Value)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, sceneTarget{
+			attribute: *attr,
+			value:     value.Value,
+		})
+	}
+	return targets, nil
+}
+
+// transition fades range attributes in steps. Attributes being turned off are deferred until the fade
+// completes, and all other values are applied immediately. The invocation finishes with the first request that
+// failed.
+func (s *sceneOperator) transition(invocation *domain.Invocation, targets []sceneTarget, steps int,
+	interval time.Duration) {
+	var failed error
+	request := func(target sceneTarget, value string) {
+		err := s.request(invocation.Id, target.attribute, value)
+		if err != nil && failed == nil {
+			failed = err
+		}
+	}
+
+	var fades []sceneTarget
+	var deferred []sceneTarget
+	for _, target := range targets {
+		switch {
+		case target.attribute.Type == domain.RANGE && target.attribute.Value != target.value:
+			fades = append(fades, target)
+		case target.attribute.Type == domain.TOGGLE && target.value == "false":
+			deferred = append(deferred, target)
+		default:
+			request(target, target.value)
+		}
+	}
+
+	for i := 1; i <= steps; i++ {
+		time.Sleep(interval)
+		for _, target := range fades {
+			request(target, interpolate(target, float64(i)/float64(steps)))
+		}
+	}
+
+	for _, target := range deferred {
+		request(target, target.value)
+	}
+	finish(s.ctrl, invocation, failed)
+}
+
+// request requests a value and records it on the invocation, the request's error is returned
+func (s *sceneOperator) request(invocation string, attribute domain.Attribute, value string) error {
+	err := s.ctrl.Attributes.Request(attribute.Entity, attribute.Key, value)
+	if err != nil {
+		log.ErrF(err, "scene could not request '%s.%s'", attribute.Entity, attribute.Key)
+	}
+	record := s.ctrl.Invocations.Request(invocation, attribute.Entity, attribute.Key, value, err)
+	if record != nil {
+		log.Err(record)
+	}
+	return err
+}
+
+// interpolate returns the value a fading attribute should have at the provided progress, rounded to its step
+func interpolate(target sceneTarget, progress float64) string {
+	from, err := strconv.ParseFloat(target.attribute.Value, 64)
+	if err != nil || progress >= 1 {
+		return target.value
+	}
+	to, err := strconv.ParseFloat(target.value, 64)
+	if err != nil {
+		return target.value
+	}
+	value := from + (to-from)*progress
+	if step := target.attribute.Schema.Step; step > 0 {
+		value = math.Round(value/step) * step
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
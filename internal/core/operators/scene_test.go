@@ -0,0 +1,107 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"sync"
+	"testing"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+func TestInterpolate(t *testing.T) {
+	target := sceneTarget{
+		attribute: domain.Attribute{Value: "0", Schema: domain.RangeSchema(0, 100, 1, "%")},
+		value:     "75",
+	}
+	tests := []struct {
+		progress float64
+		want     string
+	}{
+		{0.25, "19"},
+		{0.5, "38"},
+		{1, "75"},
+	}
+	for _, tt := range tests {
+		if got := interpolate(target, tt.progress); got != tt.want {
+			t.Errorf("interpolate(%v) = %s, want %s", tt.progress, got, tt.want)
+		}
+	}
+}
+
+type sceneInvocations struct {
+	ports.InvocationService
+	mutex     sync.Mutex
+	requested int
+	finished  chan error
+}
+
+func (i *sceneInvocations) Begin(string, string, string, domain.Source) (*domain.Invocation, error) {
+	return &domain.Invocation{}, nil
+}
+
+func (i *sceneInvocations) Request(string, string, string, string, error) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.requested++
+	return nil
+}
+
+func (i *sceneInvocations) Finish(_ *domain.Invocation, err error) error {
+	i.finished <- err
+	return nil
+}
+
+func TestSceneOperator_ApplyFailedRequest(t *testing.T) {
+	invocations := &sceneInvocations{finished: make(chan error, 1)}
+	op := NewSceneOperator(&controller.Controller{
+		Attributes:  &macroAttributes{failing: "fan"},
+		Invocations: invocations,
+	})
+	scene := domain.Scene{Name: "evening", Values: []domain.SceneValue{
+		{Entity: "lamp", Key: "on", Value: "true"},
+		{Entity: "fan", Key: "on", Value: "true"},
+	}}
+
+	err := op.Apply(scene, 0, domain.Source{})
+	if err == nil {
+		t.Errorf("a scene whose request failed was applied")
+	}
+	if finished := <-invocations.finished; finished == nil {
+		t.Errorf("the invocation of a failed scene finished without an error")
+	}
+	if invocations.requested != 2 {
+		t.Errorf("%d requests were recorded, want 2", invocations.requested)
+	}
+}
+
+func TestSceneOperator_ApplyTransition(t *testing.T) {
+	invocations := &sceneInvocations{finished: make(chan error, 1)}
+	op := NewSceneOperator(&controller.Controller{
+		Attributes:  &macroAttributes{failing: "fan"},
+		Invocations: invocations,
+	})
+	scene := domain.Scene{Name: "evening", Values: []domain.SceneValue{
+		{Entity: "fan", Key: "on", Value: "true"},
+	}}
+
+	err := op.Apply(scene, sceneStepInterval*2, domain.Source{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-invocations.finished:
+	case <-time.After(time.Second * 2):
+		t.Fatalf("the invocation did not finish after the transition")
+	}
+	if err == nil {
+		t.Errorf("the invocation of a failed transition finished without an error")
+	}
+	invocations.mutex.Lock()
+	defer invocations.mutex.Unlock()
+	if invocations.requested != 1 {
+		t.Errorf("the invocation finished after %d requests, want 1", invocations.requested)
+	}
+}
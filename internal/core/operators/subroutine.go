@@ -3,9 +3,17 @@
 package operators
 
 import (
+	"fmt"
+	"time"
 	"udap/internal/controller"
 	"udap/internal/core/domain"
 	"udap/internal/core/ports"
+	"udap/internal/log"
+)
+
+const (
+	waitInterval = time.Millisecond * 500
+	retryBackoff = time.Second
 )
 
 type subRoutineOperator struct {
@@ -18,13 +26,188 @@ func NewSubRoutineOperator(ctrl *controller.Controller) ports.SubRoutineOperator
 	}
 }
 
-func (m *subRoutineOperator) Run(subRoutine domain.SubRoutine) error {
-	for _, macro := range subRoutine.Macros {
-		// Good
-		err := m.ctrl.Macros.RunAndRevert(macro.Id, subRoutine.RevertAfter)
+// sequence holds the state of a single subroutine run
+type sequence struct {
+	ctrl       *controller.Controller
+	subRoutine domain.SubRoutine
+	invocation *domain.Invocation
+	source     domain.Source
+	failed     bool
+}
+
+// Run executes the steps of the subroutine in order, recording the outcome of each step on its invocation
+func (m *subRoutineOperator) Run(subRoutine domain.SubRoutine, source domain.Source) error {
+	invocation, err := m.ctrl.Invocations.Begin(domain.SUBROUTINE, subRoutine.Id, subRoutine.Description, source)
+	if err != nil {
+		return err
+	}
+
+	s := &sequence{
+		ctrl:       m.ctrl,
+		subRoutine: subRoutine,
+		invocation: invocation,
+		source:     domain.Within(invocation),
+	}
+
+	err = s.run(subRoutine.Sequence(), "")
+
+	switch {
+	case err != nil:
+		invocation.Status = domain.ABORTED
+	case s.failed:
+		invocation.Status = domain.FAILED
+	}
+	finish(m.ctrl, invocation, err)
+
+	return err
+}
+
+func (s *sequence) record() {
+	err := s.ctrl.Invocations.Record(s.invocation)
+	if err != nil {
+		log.Err(err)
+	}
+}
+
+func (s *sequence) policy(step domain.Step) string {
+	if step.Policy != "" {
+		return step.Policy
+	}
+	if s.subRoutine.Policy != "" {
+		return s.subRoutine.Policy
+	}
+	return domain.ABORT
+}
+
+func (s *sequence) attempts(step domain.Step) int {
+	// Branches are not retried as a whole, their steps declare their own policies
+	if s.policy(step) != domain.RETRY || step.Kind == domain.BRANCH {
+		return 1
+	}
+	if step.Retries > 0 {
+		return step.Retries
+	}
+	if s.subRoutine.Retries > 0 {
+		return s.subRoutine.Retries
+	}
+	return domain.DefaultRetries
+}
+
+func (s *sequence) run(steps []domain.Step, prefix string) error {
+	for i, step := range steps {
+		path := fmt.Sprintf("%s%d", prefix, i)
+
+		if step.Delay > 0 && step.Kind != domain.DELAY {
+			time.Sleep(step.Delay)
+		}
+
+		index := len(s.invocation.Steps)
+		s.invocation.Steps = append(s.invocation.Steps, domain.StepResult{
+			Path:    path,
+			Kind:    step.Kind,
+			Target:  step.Target,
+			Status:  domain.RUNNING,
+			Started: time.Now(),
+		})
+		s.record()
+
+		var err error
+		attempts := s.attempts(step)
+		for attempt := 1; attempt <= attempts; attempt++ {
+			s.invocation.Steps[index].Attempts = attempt
+			err = s.perform(step, path)
+			if err == nil {
+				break
+			}
+			if attempt < attempts {
+				time.Sleep(retryBackoff * time.Duration(attempt))
+			}
+		}
+
+		result := &s.invocation.Steps[index]
+		result.Finished = time.Now()
+		if err != nil {
+			result.Status = domain.FAILED
+			result.Error = err.Error()
+		} else {
+			result.Status = domain.SUCCEEDED
+		}
+		s.record()
+
+		if err == nil {
+			continue
+		}
+		s.failed = true
+		if s.policy(step) == domain.CONTINUE {
+			continue
+		}
+		s.skip(steps[i+1:], prefix, i+1)
+		return fmt.Errorf("step %s failed: %s", path, err.Error())
+	}
+	return nil
+}
+
+// skip records the steps that will not run because the sequence was aborted
+func (s *sequence) skip(steps []domain.Step, prefix string, offset int) {
+	for i, step := range steps {
+		s.invocation.Steps = append(s.invocation.Steps, domain.StepResult{
+			Path:   fmt.Sprintf("%s%d", prefix, offset+i),
+			Kind:   step.Kind,
+			Target: step.Target,
+			Status: domain.SKIPPED,
+		})
+	}
+}
+
+func (s *sequence) perform(step domain.Step, path string) error {
+	switch step.Kind {
+	case domain.MACRO:
+		// RevertAfter is stored in minutes
+		revert := s.subRoutine.RevertAfter * time.Minute
+		return s.ctrl.Macros.RunAndRevert(step.Target, s.source, revert)
+	case domain.SCENE:
+		return s.ctrl.Scenes.Apply(step.Target, s.source)
+	case domain.DELAY:
+		time.Sleep(step.Delay)
+		return nil
+	case domain.WAIT:
+		return s.wait(step)
+	case domain.BRANCH:
+		holds, err := s.holds(*step.Condition)
 		if err != nil {
 			return err
 		}
+		if holds {
+			return s.run(step.Then, path+".then.")
+		}
+		return s.run(step.Else, path+".else.")
+	default:
+		return fmt.Errorf("unknown step kind '%s'", step.Kind)
 	}
-	return nil
+}
+
+// wait blocks until the step's condition holds, or fails once its timeout passes
+func (s *sequence) wait(step domain.Step) error {
+	deadline := time.Now().Add(step.WaitTimeout())
+	for {
+		holds, err := s.holds(*step.Condition)
+		if err != nil {
+			return err
+		}
+		if holds {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for '%s.%s'", step.Condition.Entity, step.Condition.Key)
+		}
+		time.Sleep(waitInterval)
+	}
+}
+
+func (s *sequence) holds(condition domain.RuleCondition) (bool, error) {
+	attribute, err := s.ctrl.Attributes.FindByComposite(condition.Entity, condition.Key)
+	if err != nil {
+		return false, err
+	}
+	return condition.Holds(attribute.Value), nil
 }
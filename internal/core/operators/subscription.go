@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package operators
+
+import (
+	"udap/internal/core/domain"
+)
+
+// subscription is a subscription of an enrolled endpoint, zones holds the entities of each subscribed zone so the
+// mutations of those entities can be matched
+type subscription struct {
+	filter  domain.Subscription
+	zones   map[string][]string
+	members map[string]bool
+}
+
+func newSubscription(filter domain.Subscription, zones []domain.Zone) *subscription {
+	s := &subscription{
+		filter:  filter,
+		zones:   map[string][]string{},
+		members: map[string]bool{},
+	}
+	for _, zone := range zones {
+		s.update(zone)
+	}
+	return s
+}
+
+// update records the entities of a zone if the zone is subscribed to
+func (s *subscription) update(zone domain.Zone) {
+	subscribed := false
+	for _, id := range s.filter.Zones {
+		if id == zone.Id {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		return
+	}
+	var entities []string
+	if !zone.Deleted {
+		for _, entity := range zone.Entities {
+			entities = append(entities, entity.Id)
+		}
+	}
+	s.zones[zone.Id] = entities
+	s.members = map[string]bool{}
+	for _, ids := range s.zones {
+		for _, id := range ids {
+			s.members[id] = true
+		}
+	}
+}
+
+// matches reports whether a mutation passes the subscription, the entities of subscribed zones are kept up to date
+// as the zones' mutations pass through
+func (s *subscription) matches(mutation domain.Mutation) bool {
+	if zone, ok := mutation.Body.(domain.Zone); ok {
+		s.update(zone)
+	}
+	return s.filter.Matches(mutation, s.members)
+}
@@ -6,6 +6,7 @@ import (
 	"udap/internal/controller"
 	"udap/internal/core/domain"
 	"udap/internal/core/ports"
+	"udap/internal/log"
 )
 
 type triggerOperator struct {
@@ -18,7 +19,17 @@ func NewTriggerOperator(ctrl *controller.Controller) ports.TriggerOperator {
 	}
 }
 
-func (m *triggerOperator) RunCustom(trigger domain.Trigger, key string, value string) error {
+func (m *triggerOperator) RunCustom(trigger domain.Trigger, key string, value string, source domain.Source) error {
+	invocation, err := m.ctrl.Invocations.Begin(domain.TRIGGER, trigger.Id, trigger.Name, source)
+	if err != nil {
+		return err
+	}
+	err = m.runCustom(trigger, key, value, domain.Within(invocation))
+	finish(m.ctrl, invocation, err)
+	return err
+}
+
+func (m *triggerOperator) runCustom(trigger domain.Trigger, key string, value string, source domain.Source) error {
 
 	actions, err := m.ctrl.Actions.FindByTriggerId(trigger.Id)
 	if err != nil {
@@ -26,7 +37,7 @@ func (m *triggerOperator) RunCustom(trigger domain.Trigger, key string, value st
 	}
 
 	for _, action := range *actions {
-		err = m.ctrl.Actions.ExecuteCustomById(action.Id, key, value)
+		err = m.ctrl.Actions.ExecuteCustomById(action.Id, key, value, source)
 		if err != nil {
 			return err
 		}
@@ -35,9 +46,19 @@ func (m *triggerOperator) RunCustom(trigger domain.Trigger, key string, value st
 	return nil
 }
 
-func (m *triggerOperator) Run(trigger domain.Trigger) error {
+func (m *triggerOperator) Run(trigger domain.Trigger, source domain.Source) error {
+	invocation, err := m.ctrl.Invocations.Begin(domain.TRIGGER, trigger.Id, trigger.Name, source)
+	if err != nil {
+		return err
+	}
+	err = m.run(trigger, domain.Within(invocation))
+	finish(m.ctrl, invocation, err)
+	return err
+}
+
+func (m *triggerOperator) run(trigger domain.Trigger, source domain.Source) error {
 
-	err := m.ctrl.SubRoutines.TriggerById(trigger.Id)
+	err := m.ctrl.SubRoutines.TriggerById(trigger.Id, source)
 	if err != nil {
 		return err
 	}
@@ -48,7 +69,7 @@ func (m *triggerOperator) Run(trigger domain.Trigger) error {
 	}
 
 	for _, action := range *actions {
-		err = m.ctrl.Actions.ExecuteById(action.Id)
+		err = m.ctrl.Actions.ExecuteById(action.Id, source)
 		if err != nil {
 			return err
 		}
@@ -56,3 +77,11 @@ func (m *triggerOperator) Run(trigger domain.Trigger) error {
 
 	return nil
 }
+
+// finish closes an invocation, failing to record it does not fail the invocation itself
+func finish(ctrl *controller.Controller, invocation *domain.Invocation, err error) {
+	err = ctrl.Invocations.Finish(invocation, err)
+	if err != nil {
+		log.Err(err)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package ports
+
+import (
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+)
+
+type ActionRepository interface {
+	common.Persist[domain.Action]
+	FindByTriggerId(id string) (*[]domain.Action, error)
+}
+
+type ActionOperator interface {
+	Execute(action domain.Action, key string, value string, source domain.Source) error
+}
+
+type ActionService interface {
+	domain.Observable
+	FindAll() (*[]domain.Action, error)
+	FindById(id string) (*domain.Action, error)
+	FindByTriggerId(id string) (*[]domain.Action, error)
+	ExecuteById(id string, source domain.Source) error
+	ExecuteCustomById(id string, key string, value string, source domain.Source) error
+	Create(*domain.Action) error
+	Update(*domain.Action) error
+	Delete(id string) error
+}
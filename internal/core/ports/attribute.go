@@ -3,6 +3,7 @@
 package ports
 
 import (
+	"context"
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/core/domain/common"
@@ -23,8 +24,11 @@ type AttributeRepository interface {
 type AttributeOperator interface {
 	Register(attribute *domain.Attribute) error
 	Request(*domain.Attribute, string) error
+	Dispatch(context.Context, *domain.Attribute) error
+	Queues() []domain.DispatchQueue
 	Set(*domain.Attribute, string) error
 	Update(*domain.Attribute, string, time.Time) error
+	Expire(*domain.Attribute, time.Time) bool
 }
 
 type AttributeService interface {
@@ -37,6 +41,8 @@ type AttributeService interface {
 	Register(*domain.Attribute) error
 	Summary(key string, start int64, stop int64, window int, mode string) (map[int64]float64, error)
 	Request(entity string, key string, value string) error
+	RequestContext(ctx context.Context, entity string, key string, value string) error
+	Queues() []domain.DispatchQueue
 	Set(entity string, key string, value string) error
 	Update(entity string, key string, value string, stamp time.Time) error
 	Delete(*domain.Attribute) error
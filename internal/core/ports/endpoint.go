@@ -18,6 +18,11 @@ type EndpointOperator interface {
 	Unenroll(*domain.Endpoint) error
 	Send(id string, operation string, payload any) error
 	SendAll(id string, operation string, payload any) error
+	Subscribe(endpoint string, id string, subscription domain.Subscription) error
+	Unsubscribe(endpoint string, id string) error
+	Execute(endpoint string, command domain.Command)
+	Stats() []domain.EndpointStats
+	Resync()
 	CloseAll() error
 }
 
@@ -34,7 +39,12 @@ type EndpointService interface {
 
 	SendAll(target string, operation string, payload any) error
 	Send(id string, operation string, payload any) error
-	Unenroll(key string) error
+	Unenroll(id string, conn *websocket.Conn) error
+	Subscribe(endpoint string, id string, subscription domain.Subscription) error
+	Unsubscribe(endpoint string, id string) error
+	Execute(endpoint string, command domain.Command)
+	Stats() []domain.EndpointStats
+	Resync()
 
 	FindOrCreate(*domain.Endpoint) error
 	Update(*domain.Endpoint) error
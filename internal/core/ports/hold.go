@@ -0,0 +1,29 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package ports
+
+import (
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+)
+
+type HoldRepository interface {
+	common.Persist[domain.Hold]
+	FindByAttribute(id string) (*domain.Hold, error)
+}
+
+type HoldOperator interface {
+	Restore(hold domain.Hold) error
+}
+
+type HoldService interface {
+	domain.Observable
+	Hold(origin string, macroId string, attribute domain.Attribute, applied string, duration time.Duration) error
+	HandleMutation(mutation domain.Mutation) error
+	Resume() error
+	Release(id string) error
+	Cancel(id string) error
+	FindAll() (*[]domain.Hold, error)
+	FindById(id string) (*domain.Hold, error)
+}
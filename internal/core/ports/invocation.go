@@ -0,0 +1,23 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package ports
+
+import (
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+)
+
+type InvocationRepository interface {
+	common.Persist[domain.Invocation]
+	Query(filter domain.InvocationFilter) (*[]domain.Invocation, int64, error)
+}
+
+type InvocationService interface {
+	domain.Observable
+	Begin(kind string, target string, name string, source domain.Source) (*domain.Invocation, error)
+	Request(id string, entity string, key string, value string, err error) error
+	Record(invocation *domain.Invocation) error
+	Finish(invocation *domain.Invocation, err error) error
+	Query(filter domain.InvocationFilter) (*domain.InvocationPage, error)
+	FindById(id string) (*domain.Invocation, error)
+}
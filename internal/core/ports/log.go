@@ -2,14 +2,20 @@
 
 package ports
 
-import "udap/internal/core/domain"
+import (
+	"time"
+	"udap/internal/core/domain"
+)
 
 type LogRepository interface {
-	FindAll() ([]domain.Log, error)
 	Create(*domain.Log) error
+	Query(filter domain.LogFilter) (*[]domain.Log, int64, error)
+	DeleteBefore(cutoff time.Time) (int64, error)
 }
 
 type LogService interface {
 	domain.Observable
 	Create(*domain.Log) error
+	Query(filter domain.LogFilter) (*domain.LogPage, error)
+	Prune() error
 }
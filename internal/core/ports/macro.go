@@ -13,15 +13,15 @@ type MacroRepository interface {
 }
 
 type MacroOperator interface {
-	Run(macro domain.Macro) error
-	RunAndRevert(macro domain.Macro, baseline domain.Macro, revert time.Duration) error
+	Run(macro domain.Macro, source domain.Source) error
+	RunAndRevert(macro domain.Macro, source domain.Source, revert time.Duration) error
 }
 
 type MacroService interface {
 	domain.Observable
 	FindAll() (*[]domain.Macro, error)
-	Run(id string) error
-	RunAndRevert(id string, revert time.Duration) error
+	Run(id string, source domain.Source) error
+	RunAndRevert(id string, source domain.Source, revert time.Duration) error
 	FindById(id string) (*domain.Macro, error)
 	Create(*domain.Macro) error
 	Update(*domain.Macro) error
@@ -3,6 +3,7 @@
 package ports
 
 import (
+	"io"
 	"udap/internal/core/domain"
 	"udap/internal/core/domain/common"
 )
@@ -14,12 +15,19 @@ type ModuleRepository interface {
 }
 
 type ModuleOperator interface {
-	Build(module string, uuid string) error
-	Load(module string, uuid string) (domain.ModuleConfig, error)
+	Build(module string, uuid string, runtime string) error
+	Load(module string, uuid string, runtime string) (domain.ModuleConfig, error)
 	Dispose(module string, uuid string) error
 	Run(uuid string) error
 	Update(uuid string) error
 	HandleEmit(mutation domain.Mutation) error
+	Subscribe(uuid string, filter domain.MutationFilter) error
+	Configure(uuid string, key string, value string) error
+	Unpack(archive io.Reader) (domain.Manifest, error)
+	Staged(module string) (domain.Manifest, error)
+	Discard(module string) error
+	Install(module string) error
+	Revert(module string) error
 }
 
 type ModuleService interface {
@@ -28,6 +36,7 @@ type ModuleService interface {
 	InitConfig(string, string, string) error
 	SetConfig(string, string, string) error
 	GetConfig(string, string) (string, error)
+	Configure(name string, key string, value string) error
 	HandleEmits(mutation domain.Mutation) error
 	Build(id string) error
 	Load(id string) error
@@ -45,4 +54,14 @@ type ModuleService interface {
 	Enable(name string) error
 	Reload(name string) error
 	Halt(name string) error
+	SetRuntime(name string, runtime string) error
+	Fault(uuid string, reason string) error
+	Health(name string) (domain.ModuleHealth, error)
+	Supervise()
+	Schedule(name string) (domain.ModuleSchedule, error)
+	Schedules() []domain.ModuleSchedule
+	Subscribe(uuid string, filter domain.MutationFilter) error
+	Stage(archive io.Reader) (domain.Manifest, error)
+	Install(name string) (*domain.Module, error)
+	Rollback(name string) error
 }
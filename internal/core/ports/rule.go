@@ -0,0 +1,30 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package ports
+
+import (
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+)
+
+type RuleRepository interface {
+	common.Persist[domain.Rule]
+	FindByAttribute(entity string, key string) (*[]domain.Rule, error)
+}
+
+type RuleOperator interface {
+	Evaluate(rule domain.Rule) (bool, error)
+	Run(rule domain.Rule) error
+}
+
+type RuleService interface {
+	domain.Observable
+	HandleMutation(mutation domain.Mutation) error
+	FindAll() (*[]domain.Rule, error)
+	FindById(id string) (*domain.Rule, error)
+	Create(*domain.Rule) error
+	Update(*domain.Rule) error
+	Enable(id string) error
+	Disable(id string) error
+	Delete(id string) error
+}
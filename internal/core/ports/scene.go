@@ -0,0 +1,31 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package ports
+
+import (
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+)
+
+type SceneRepository interface {
+	common.Persist[domain.Scene]
+}
+
+type SceneOperator interface {
+	Capture(zoneId string, keys []string) ([]domain.SceneValue, error)
+	Apply(scene domain.Scene, transition time.Duration, source domain.Source) error
+}
+
+type SceneService interface {
+	domain.Observable
+	Capture(name string, zoneId string, keys []string) (*domain.Scene, error)
+	Recapture(id string) error
+	Apply(id string, source domain.Source) error
+	ApplyWithTransition(id string, transition time.Duration, source domain.Source) error
+	FindAll() (*[]domain.Scene, error)
+	FindById(id string) (*domain.Scene, error)
+	Create(*domain.Scene) error
+	Update(*domain.Scene) error
+	Delete(id string) error
+}
@@ -12,19 +12,23 @@ type SubRoutineRepository interface {
 	FindByTriggerId(id string) (res []*domain.SubRoutine, err error)
 	AddMacro(subroutine *domain.SubRoutine, id string) error
 	RemoveMacro(subroutine *domain.SubRoutine, id string) error
+	AddScene(subroutine *domain.SubRoutine, id string) error
+	RemoveScene(subroutine *domain.SubRoutine, id string) error
 }
 
 type SubRoutineOperator interface {
-	Run(routine domain.SubRoutine) error
+	Run(routine domain.SubRoutine, source domain.Source) error
 }
 
 type SubRoutineService interface {
 	domain.Observable
-	Run(id string) error
-	TriggerById(id string) error
+	Run(id string, source domain.Source) error
+	TriggerById(id string, source domain.Source) error
 	FindById(id string) (*domain.SubRoutine, error)
 	AddMacro(id string, macroId string) error
 	RemoveMacro(id string, macroId string) error
+	AddScene(id string, sceneId string) error
+	RemoveScene(id string, sceneId string) error
 	Create(*domain.SubRoutine) error
 	Update(*domain.SubRoutine) error
 	Delete(id string) error
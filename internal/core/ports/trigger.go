@@ -14,16 +14,20 @@ type TriggerRepository interface {
 }
 
 type TriggerOperator interface {
-	Run(trigger domain.Trigger) error
-	RunCustom(trigger domain.Trigger, key string, value string) error
+	Run(trigger domain.Trigger, source domain.Source) error
+	RunCustom(trigger domain.Trigger, key string, value string, source domain.Source) error
 }
 
 type TriggerService interface {
 	domain.Observable
 	Trigger(name string) error
+	TriggerFrom(name string, source domain.Source) error
 	TriggerCustom(name string, key string, value string) error
 
 	Register(*domain.Trigger) error
+	Schedule(id string, schedule domain.Schedule) error
+	StartSchedules() error
+	FindAll() (*[]domain.Trigger, error)
 	FindById(id string) (*domain.Trigger, error)
 	Create(*domain.Trigger) error
 	Update(*domain.Trigger) error
@@ -0,0 +1,31 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package repository
+
+import (
+	"gorm.io/gorm"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+type actionRepo struct {
+	generic.Store[domain.Action]
+	db *gorm.DB
+}
+
+func NewActionRepository(db *gorm.DB) ports.ActionRepository {
+	return &actionRepo{
+		db:    db,
+		Store: generic.NewStore[domain.Action](db),
+	}
+}
+
+func (a *actionRepo) FindByTriggerId(id string) (*[]domain.Action, error) {
+	var target []domain.Action
+	err := a.db.Model(&domain.Action{}).Where("trigger_id = ?", id).Order("created_at").Find(&target).Error
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package repository
+
+import (
+	"gorm.io/gorm"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+type holdRepo struct {
+	generic.Store[domain.Hold]
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) ports.HoldRepository {
+	return &holdRepo{
+		db:    db,
+		Store: generic.NewStore[domain.Hold](db),
+	}
+}
+
+// FindByAttribute returns the hold placed on an attribute, or nil if it is not held
+func (h *holdRepo) FindByAttribute(id string) (*domain.Hold, error) {
+	var holds []domain.Hold
+	err := h.db.Model(&domain.Hold{}).Where("attribute = ?", id).Limit(1).Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, nil
+	}
+	return &holds[0], nil
+}
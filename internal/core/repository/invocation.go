@@ -0,0 +1,61 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package repository
+
+import (
+	"gorm.io/gorm"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+type invocationRepo struct {
+	generic.Store[domain.Invocation]
+	db *gorm.DB
+}
+
+func NewInvocationRepository(db *gorm.DB) ports.InvocationRepository {
+	return &invocationRepo{
+		db:    db,
+		Store: generic.NewStore[domain.Invocation](db),
+	}
+}
+
+// Query returns a page of invocations matching the filter, newest first, along with the total number of matches
+func (i *invocationRepo) Query(filter domain.InvocationFilter) (*[]domain.Invocation, int64, error) {
+	query := i.db.Model(&domain.Invocation{})
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.Parent != "" {
+		query = query.Where("parent = ?", filter.Parent)
+	}
+	if filter.Failed {
+		query = query.Where("(error <> '' OR status IN ?)", []string{domain.FAILED, domain.ABORTED})
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("started >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("started < ?", filter.Until)
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var target []domain.Invocation
+	err = query.Order("started desc").Offset(filter.Offset).Limit(filter.Limit).Find(&target).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return &target, total, nil
+}
@@ -3,43 +3,70 @@
 package repository
 
 import (
-	"math/rand"
+	"gorm.io/gorm"
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/core/ports"
 )
 
 type logsRepo struct {
-	logs map[string][]domain.Log
+	db *gorm.DB
 }
 
-func NewLogsRepository() ports.LogRepository {
+func NewLogsRepository(db *gorm.DB) ports.LogRepository {
 	return &logsRepo{
-		logs: map[string][]domain.Log{},
+		db: db,
 	}
 }
 
-func randomSequence() string {
-	template := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
-	var out string
-	rand.Seed(time.Now().Unix())
-	for i := 0; i < 32; i++ {
-		r := rand.Intn(62)
-		u := template[r]
-		out += string(u)
-	}
-	return out
+func (m *logsRepo) Create(logEvent *domain.Log) error {
+	return m.db.Create(logEvent).Error
 }
 
-func (m *logsRepo) Create(logEvent *domain.Log) error {
-	logEvent.Id = randomSequence()
-	m.logs[logEvent.Event] = append(m.logs[logEvent.Event], *logEvent)
-	return nil
+// Query returns a page of logs matching the filter, newest first, along with the total number of matches
+func (m *logsRepo) Query(filter domain.LogFilter) (*[]domain.Log, int64, error) {
+	query := m.db.Model(&domain.Log{})
+	if filter.Level != "" {
+		query = query.Where("level = ?", filter.Level)
+	}
+	if filter.Module != "" {
+		query = query.Where("module = ?", filter.Module)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.Correlation != "" {
+		query = query.Where("correlation = ?", filter.Correlation)
+	}
+	if filter.Search != "" {
+		query = query.Where("to_tsvector('english', message) @@ plainto_tsquery('english', ?)", filter.Search)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("time >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("time < ?", filter.Until)
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var logs []domain.Log
+	err = query.Order("time desc").Offset(filter.Offset).Limit(filter.Limit).Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return &logs, total, nil
 }
 
-func (m *logsRepo) FindAll() (logs []domain.Log, err error) {
-	for _, lgs := range m.logs {
-		logs = append(logs, lgs...)
+// DeleteBefore removes every log older than the provided time
+func (m *logsRepo) DeleteBefore(cutoff time.Time) (int64, error) {
+	result := m.db.Where("time < ?", cutoff).Delete(&domain.Log{})
+	if result.Error != nil {
+		return 0, result.Error
 	}
-	return logs, nil
+	return result.RowsAffected, nil
 }
@@ -0,0 +1,32 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package repository
+
+import (
+	"gorm.io/gorm"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+type ruleRepo struct {
+	generic.Store[domain.Rule]
+	db *gorm.DB
+}
+
+func NewRuleRepository(db *gorm.DB) ports.RuleRepository {
+	return &ruleRepo{
+		db:    db,
+		Store: generic.NewStore[domain.Rule](db),
+	}
+}
+
+func (r *ruleRepo) FindByAttribute(entity string, key string) (*[]domain.Rule, error) {
+	var target []domain.Rule
+	err := r.db.Model(&domain.Rule{}).Where("entity = ? AND key = ? AND enabled = ?", entity, key,
+		true).Find(&target).Error
+	if err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package repository
+
+import (
+	"gorm.io/gorm"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+type sceneRepo struct {
+	generic.Store[domain.Scene]
+	db *gorm.DB
+}
+
+func NewSceneRepository(db *gorm.DB) ports.SceneRepository {
+	return &sceneRepo{
+		db:    db,
+		Store: generic.NewStore[domain.Scene](db),
+	}
+}
@@ -47,6 +47,32 @@ func (s *subRoutineRepo) RemoveMacro(subroutine *domain.SubRoutine, id string) e
 	return nil
 }
 
+func (s *subRoutineRepo) AddScene(subroutine *domain.SubRoutine, id string) error {
+	target := domain.Scene{}
+	err := s.db.Model(&domain.Scene{}).Where("id = ?", id).First(&target).Error
+	if err != nil {
+		return err
+	}
+	err = s.db.Model(subroutine).Association("Scenes").Append(&target)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *subRoutineRepo) RemoveScene(subroutine *domain.SubRoutine, id string) error {
+	target := domain.Scene{}
+	err := s.db.Model(&domain.Scene{}).Where("id = ?", id).First(&target).Error
+	if err != nil {
+		return err
+	}
+	err = s.db.Model(subroutine).Association("Scenes").Delete(&target)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *subRoutineRepo) Delete(e *domain.SubRoutine) error {
 
 	err := s.db.Model(e).Association("Macros").Clear()
@@ -54,6 +80,11 @@ func (s *subRoutineRepo) Delete(e *domain.SubRoutine) error {
 		return err
 	}
 
+	err = s.db.Model(e).Association("Scenes").Clear()
+	if err != nil {
+		return err
+	}
+
 	if err = s.db.Delete(e).Error; err != nil {
 		return err
 	}
@@ -62,7 +93,7 @@ func (s *subRoutineRepo) Delete(e *domain.SubRoutine) error {
 
 func (s *subRoutineRepo) FindById(id string) (*domain.SubRoutine, error) {
 	target := domain.SubRoutine{}
-	err := s.db.Model(&domain.SubRoutine{}).Preload("Macros").Where("id = ?", id).Find(&target).Error
+	err := s.db.Model(&domain.SubRoutine{}).Preload("Macros").Preload("Scenes").Where("id = ?", id).Find(&target).Error
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +102,7 @@ func (s *subRoutineRepo) FindById(id string) (*domain.SubRoutine, error) {
 
 func (s *subRoutineRepo) FindAll() (*[]domain.SubRoutine, error) {
 	var target []domain.SubRoutine
-	if err := s.db.Preload("Macros").Find(&target).Error; err != nil {
+	if err := s.db.Preload("Macros").Preload("Scenes").Find(&target).Error; err != nil {
 		return nil, err
 	}
 	return &target, nil
@@ -79,7 +110,7 @@ func (s *subRoutineRepo) FindAll() (*[]domain.SubRoutine, error) {
 
 func (s *subRoutineRepo) FindByTriggerId(id string) (res []*domain.SubRoutine, err error) {
 	var rs []*domain.SubRoutine
-	err = s.db.Model(&domain.SubRoutine{}).Preload("Macros").Where("trigger_id = ?", id).Find(&rs).Error
+	err = s.db.Model(&domain.SubRoutine{}).Preload("Macros").Preload("Scenes").Where("trigger_id = ?", id).Find(&rs).Error
 	if err != nil {
 		return nil, err
 	}
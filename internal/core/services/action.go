@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+func NewActionService(repository ports.ActionRepository, operator ports.ActionOperator) ports.ActionService {
+	return &actionService{repository: repository, operator: operator}
+}
+
+type actionService struct {
+	repository ports.ActionRepository
+	operator   ports.ActionOperator
+	generic.Watchable[domain.Action]
+}
+
+func (u *actionService) ExecuteById(id string, source domain.Source) error {
+	return u.ExecuteCustomById(id, "", "", source)
+}
+
+func (u *actionService) ExecuteCustomById(id string, key string, value string, source domain.Source) error {
+	action, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = u.operator.Execute(*action, key, value, source)
+	if err != nil {
+		return err
+	}
+	action.LastRun = time.Now()
+	return u.mutate(action)
+}
+
+func (u *actionService) Snapshot() ([]domain.Mutation, error) {
+	all, err := u.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return u.Mutations(*all), nil
+}
+
+func (u *actionService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
+	}
+	u.Publish(snapshot)
+	return nil
+}
+
+func (u *actionService) mutate(action *domain.Action) error {
+	err := u.repository.Update(action)
+	if err != nil {
+		return err
+	}
+	err = u.Emit(*action)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Repository Mapping
+
+func (u *actionService) FindAll() (*[]domain.Action, error) {
+	return u.repository.FindAll()
+}
+
+func (u *actionService) FindById(id string) (*domain.Action, error) {
+	return u.repository.FindById(id)
+}
+
+func (u *actionService) FindByTriggerId(id string) (*[]domain.Action, error) {
+	return u.repository.FindByTriggerId(id)
+}
+
+func (u *actionService) Create(action *domain.Action) error {
+	err := action.Validate()
+	if err != nil {
+		return err
+	}
+	err = u.repository.Create(action)
+	if err != nil {
+		return err
+	}
+	err = u.Emit(*action)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *actionService) Update(action *domain.Action) error {
+	err := action.Validate()
+	if err != nil {
+		return err
+	}
+	return u.mutate(action)
+}
+
+func (u *actionService) Delete(id string) error {
+	byId, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = u.repository.Delete(byId)
+	if err != nil {
+		return err
+	}
+	byId.Deleted = true
+	err = u.Emit(*byId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
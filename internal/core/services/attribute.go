@@ -3,22 +3,29 @@
 package services
 
 import (
+	"context"
+	"reflect"
+	"sync"
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/core/generic"
 	"udap/internal/core/ports"
+	"udap/internal/log"
 )
 
 func NewAttributeService(repository ports.AttributeRepository, op ports.AttributeOperator) ports.AttributeService {
 	return &attributeService{
 		repository: repository,
 		operator:   op,
+		timeouts:   map[string]*time.Timer{},
 	}
 }
 
 type attributeService struct {
 	repository ports.AttributeRepository
 	operator   ports.AttributeOperator
+	timeouts   map[string]*time.Timer
+	mutex      sync.Mutex
 	generic.Watchable[domain.Attribute]
 	Logs generic.Watchable[domain.AttributeLog]
 }
@@ -32,27 +39,20 @@ func (a *attributeService) Watch(ref chan<- domain.Mutation) {
 	//a.Logs.Watch(ref)
 }
 
-func (a *attributeService) EmitAll() error {
+func (a *attributeService) Snapshot() ([]domain.Mutation, error) {
 	all, err := a.repository.FindRecent()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, attribute := range *all {
-		err = a.Emit(attribute)
-		if err != nil {
-			return err
-		}
+	return a.Mutations(*all), nil
+}
+
+func (a *attributeService) EmitAll() error {
+	snapshot, err := a.Snapshot()
+	if err != nil {
+		return err
 	}
-	//logs, err := a.FindRecentLogs()
-	//if err != nil {
-	//	return err
-	//}
-	//for _, log := range *logs {
-	//	err = a.Logs.Emit(log)
-	//	if err != nil {
-	//		return err
-	//	}
-	//}
+	a.Publish(snapshot)
 	return nil
 }
 
@@ -65,10 +65,26 @@ func (a *attributeService) FindAllByEntity(entity string) (*[]domain.Attribute,
 }
 
 func (a *attributeService) Register(attribute *domain.Attribute) error {
+	// The schema declared by the module takes precedence over the stored schema
+	schema := attribute.Schema
+	if schema.IsZero() {
+		schema = domain.DefaultSchema(attribute.Type)
+	}
+	timeout := attribute.Timeout
 	err := a.repository.Register(attribute)
 	if err != nil {
 		return err
 	}
+	if !reflect.DeepEqual(attribute.Schema, schema) || (timeout != 0 && attribute.Timeout != timeout) {
+		attribute.Schema = schema
+		if timeout != 0 {
+			attribute.Timeout = timeout
+		}
+		err = a.repository.Update(attribute)
+		if err != nil {
+			return err
+		}
+	}
 	err = a.operator.Register(attribute)
 	if err != nil {
 		return err
@@ -80,39 +96,101 @@ func (a *attributeService) Register(attribute *domain.Attribute) error {
 	return nil
 }
 
+func (a *attributeService) Queues() []domain.DispatchQueue {
+	return a.operator.Queues()
+}
+
 func (a *attributeService) Request(entity string, key string, value string) error {
+	return a.RequestContext(context.Background(), entity, key, value)
+}
+
+// RequestContext requests a new value, the module must accept the request before the context or attribute timeout expires
+func (a *attributeService) RequestContext(ctx context.Context, entity string, key string, value string) error {
 	e, err := a.repository.FindByComposite(entity, key)
 	if err != nil {
 		return err
 	}
-
-	e.Requested = time.Now()
-	e.Request = value
-	//log, err := a.repository.Log(&(*e))
-	//if err != nil {
-	//	return err
-	//}
+	// Reject the request before it reaches the module if the value is out of schema
+	err = e.Validate(value)
+	if err != nil {
+		return err
+	}
 
 	err = a.operator.Request(e, value)
 	if err != nil {
+		// Record the failed request so clients can show it
+		_ = a.save(e)
+		return err
+	}
+	// Persist the pending request before the module has a chance to confirm it
+	err = a.save(e)
+	if err != nil {
+		return err
+	}
+
+	a.await(*e)
+
+	timeout, cancel := context.WithTimeout(ctx, e.RequestTimeout())
+	defer cancel()
+
+	err = a.operator.Dispatch(timeout, e)
+	if err != nil {
+		a.resolve(*e)
+		_ = a.save(e)
 		return err
 	}
 
-	e.Value = value
+	return nil
+}
+
+// await marks the request as timed out if the module does not confirm it within the attribute's timeout
+func (a *attributeService) await(attribute domain.Attribute) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if timer, ok := a.timeouts[attribute.Id]; ok {
+		timer.Stop()
+	}
+	a.timeouts[attribute.Id] = time.AfterFunc(attribute.RequestTimeout(), func() {
+		err := a.expire(attribute.Entity, attribute.Key, attribute.Requested)
+		if err != nil {
+			log.Err(err)
+		}
+	})
+}
+
+// resolve cancels the timeout of a request that is no longer pending
+func (a *attributeService) resolve(attribute domain.Attribute) {
+	if attribute.Pending() {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if timer, ok := a.timeouts[attribute.Id]; ok {
+		timer.Stop()
+		delete(a.timeouts, attribute.Id)
+	}
+}
 
-	//e.UpdatedAt = time.Now()
-	err = a.repository.Update(e)
+func (a *attributeService) expire(entity string, key string, requested time.Time) error {
+	e, err := a.repository.FindByComposite(entity, key)
 	if err != nil {
 		return err
 	}
-	//
-	//if log != nil {
-	//	err = a.Logs.Emit(*log)
-	//	if err != nil {
-	//		return err
-	//	}
-	//}
-	err = a.Emit(*e)
+	a.mutex.Lock()
+	delete(a.timeouts, e.Id)
+	a.mutex.Unlock()
+	if !a.operator.Expire(e, requested) {
+		return nil
+	}
+	return a.save(e)
+}
+
+func (a *attributeService) save(attribute *domain.Attribute) error {
+	err := a.repository.Update(attribute)
+	if err != nil {
+		return err
+	}
+	err = a.Emit(*attribute)
 	if err != nil {
 		return err
 	}
@@ -124,24 +202,24 @@ func (a *attributeService) Set(entity string, key string, value string) error {
 	if err != nil {
 		return err
 	}
-
-	err = a.operator.Update(e, value, time.Now())
+	err = e.Validate(value)
 	if err != nil {
 		return err
 	}
-	err = a.repository.Log(e)
+
+	err = a.operator.Update(e, value, time.Now())
 	if err != nil {
 		return err
 	}
-	err = a.repository.Update(e)
+	err = a.repository.Log(e)
 	if err != nil {
 		return err
 	}
-
-	err = a.Emit(*e)
+	err = a.save(e)
 	if err != nil {
 		return err
 	}
+	a.resolve(*e)
 	return nil
 }
 
@@ -154,14 +232,11 @@ func (a *attributeService) Update(entity string, key string, value string, stamp
 	if err != nil {
 		return err
 	}
-	err = a.repository.Update(e)
-	if err != nil {
-		return err
-	}
-	err = a.Emit(*e)
+	err = a.save(e)
 	if err != nil {
 		return err
 	}
+	a.resolve(*e)
 	return nil
 }
 
@@ -3,6 +3,7 @@
 package services
 
 import (
+	"errors"
 	"github.com/gorilla/websocket"
 	"udap/internal/core/domain"
 	"udap/internal/core/generic"
@@ -55,17 +56,20 @@ func (u *endpointService) mutate(endpoint *domain.Endpoint) error {
 	return nil
 }
 
-func (u *endpointService) EmitAll() error {
+func (u *endpointService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, endpoint := range *all {
-		err = u.Emit(endpoint)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *endpointService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -102,12 +106,18 @@ func (u *endpointService) Enroll(id string, conn *websocket.Conn) error {
 	return nil
 }
 
-func (u *endpointService) Unenroll(id string) error {
+// Unenroll removes an endpoint's connection. A connection the endpoint has since replaced by reconnecting leaves
+// the endpoint connected.
+func (u *endpointService) Unenroll(id string, conn *websocket.Conn) error {
 	endpoint, err := u.FindById(id)
 	if err != nil {
 		return err
 	}
+	endpoint.Connection = conn
 	err = u.operator.Unenroll(endpoint)
+	if errors.Is(err, domain.ErrReplaced) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -123,6 +133,26 @@ func (u *endpointService) Unenroll(id string) error {
 	return nil
 }
 
+func (u *endpointService) Subscribe(endpoint string, id string, subscription domain.Subscription) error {
+	return u.operator.Subscribe(endpoint, id, subscription)
+}
+
+func (u *endpointService) Unsubscribe(endpoint string, id string) error {
+	return u.operator.Unsubscribe(endpoint, id)
+}
+
+func (u *endpointService) Execute(endpoint string, command domain.Command) {
+	u.operator.Execute(endpoint, command)
+}
+
+func (u *endpointService) Stats() []domain.EndpointStats {
+	return u.operator.Stats()
+}
+
+func (u *endpointService) Resync() {
+	u.operator.Resync()
+}
+
 // Repository Mapping
 
 func (u *endpointService) FindAll() (*[]domain.Endpoint, error) {
@@ -23,17 +23,20 @@ func (u *entityService) FindAllByModule(name string) (*[]domain.Entity, error) {
 	return u.repository.FindAllByModule(name)
 }
 
-func (u *entityService) EmitAll() error {
+func (u *entityService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.repository.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, et := range *all {
-		err = u.Emit(et)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *entityService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
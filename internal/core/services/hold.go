@@ -0,0 +1,198 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"sync"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+	"udap/internal/log"
+)
+
+func NewHoldService(repository ports.HoldRepository, operator ports.HoldOperator) ports.HoldService {
+	return &holdService{
+		repository: repository,
+		operator:   operator,
+		timers:     map[string]*time.Timer{},
+	}
+}
+
+type holdService struct {
+	repository ports.HoldRepository
+	operator   ports.HoldOperator
+	timers     map[string]*time.Timer
+	mutex      sync.Mutex
+	generic.Watchable[domain.Hold]
+}
+
+// Hold records the current value of an attribute before a macro changes it. If the attribute is already held,
+// the original value is kept and the hold is extended instead.
+func (u *holdService) Hold(origin string, macroId string, attribute domain.Attribute, applied string,
+	duration time.Duration) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	hold, err := u.repository.FindByAttribute(attribute.Id)
+	if err != nil {
+		return err
+	}
+	if hold == nil {
+		hold = &domain.Hold{
+			Attribute: attribute.Id,
+			Entity:    attribute.Entity,
+			Key:       attribute.Key,
+			Value:     attribute.Request,
+		}
+	}
+	hold.Origin = origin
+	hold.MacroId = macroId
+	hold.Applied = applied
+	hold.Expires = time.Now().Add(duration)
+	if hold.Id == "" {
+		err = u.repository.Create(hold)
+	} else {
+		err = u.repository.Update(hold)
+	}
+	if err != nil {
+		return err
+	}
+	u.arm(*hold)
+	return u.Emit(*hold)
+}
+
+// HandleMutation cancels a hold when its attribute is changed by something other than the macro that placed it
+func (u *holdService) HandleMutation(mutation domain.Mutation) error {
+	attribute, ok := mutation.Body.(domain.Attribute)
+	if !ok || attribute.Pending() {
+		return nil
+	}
+	hold, err := u.repository.FindByAttribute(attribute.Id)
+	if err != nil {
+		return err
+	}
+	if hold == nil || !hold.Overridden(attribute) {
+		return nil
+	}
+	log.Event("Hold on '%s.%s' cancelled by a manual change.", hold.Entity, hold.Key)
+	return u.Cancel(hold.Id)
+}
+
+// Resume arms the holds persisted before a restart, restoring any that expired while the system was down
+func (u *holdService) Resume() error {
+	holds, err := u.repository.FindAll()
+	if err != nil {
+		return err
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	for _, hold := range *holds {
+		u.arm(hold)
+	}
+	return nil
+}
+
+// Release restores the held value immediately
+func (u *holdService) Release(id string) error {
+	u.mutex.Lock()
+	hold, err := u.remove(id)
+	u.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return u.operator.Restore(*hold)
+}
+
+// Cancel drops the hold without restoring the held value
+func (u *holdService) Cancel(id string) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	_, err := u.remove(id)
+	return err
+}
+
+func (u *holdService) arm(hold domain.Hold) {
+	timer, ok := u.timers[hold.Id]
+	if ok {
+		timer.Stop()
+	}
+	id := hold.Id
+	u.timers[id] = time.AfterFunc(time.Until(hold.Expires), func() {
+		err := u.expire(id)
+		if err != nil {
+			log.Err(err)
+		}
+	})
+}
+
+func (u *holdService) expire(id string) error {
+	u.mutex.Lock()
+	hold, err := u.repository.FindById(id)
+	if err != nil {
+		u.mutex.Unlock()
+		return err
+	}
+	// The hold was extended after the timer fired
+	if hold.Expires.After(time.Now()) {
+		u.arm(*hold)
+		u.mutex.Unlock()
+		return nil
+	}
+	hold, err = u.remove(id)
+	u.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	log.Event("Hold on '%s.%s' expired, reverting to '%s'.", hold.Entity, hold.Key, hold.Value)
+	return u.operator.Restore(*hold)
+}
+
+// remove stops the timer and deletes the hold, the caller must hold the mutex
+func (u *holdService) remove(id string) (*domain.Hold, error) {
+	timer, ok := u.timers[id]
+	if ok {
+		timer.Stop()
+		delete(u.timers, id)
+	}
+	hold, err := u.repository.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+	err = u.repository.Delete(hold)
+	if err != nil {
+		return nil, err
+	}
+	hold.Deleted = true
+	err = u.Emit(*hold)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (u *holdService) Snapshot() ([]domain.Mutation, error) {
+	all, err := u.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return u.Mutations(*all), nil
+}
+
+func (u *holdService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
+	}
+	u.Publish(snapshot)
+	return nil
+}
+
+// Repository Mapping
+
+func (u *holdService) FindAll() (*[]domain.Hold, error) {
+	return u.repository.FindAll()
+}
+
+func (u *holdService) FindById(id string) (*domain.Hold, error) {
+	return u.repository.FindById(id)
+}
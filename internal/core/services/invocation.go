@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"sync"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+const (
+	defaultInvocationLimit = 50
+	maxInvocationLimit     = 500
+	recentInvocations      = 25
+)
+
+func NewInvocationService(repository ports.InvocationRepository) ports.InvocationService {
+	return &invocationService{
+		repository: repository,
+		open:       map[string]*domain.Invocation{},
+	}
+}
+
+type invocationService struct {
+	repository ports.InvocationRepository
+	open       map[string]*domain.Invocation
+	mutex      sync.Mutex
+	generic.Watchable[domain.Invocation]
+}
+
+// Begin records the start of an invocation, it stays open until Finish is called
+func (u *invocationService) Begin(kind string, target string, name string, source domain.Source) (*domain.Invocation,
+	error) {
+	invocation := &domain.Invocation{
+		Kind:     kind,
+		Target:   target,
+		Name:     name,
+		Source:   source.Kind,
+		SourceId: source.Id,
+		Parent:   source.Parent,
+		Status:   domain.RUNNING,
+		Started:  time.Now(),
+	}
+	err := u.repository.Create(invocation)
+	if err != nil {
+		return nil, err
+	}
+	u.mutex.Lock()
+	u.open[invocation.Id] = invocation
+	u.mutex.Unlock()
+	err = u.Emit(*invocation)
+	if err != nil {
+		return nil, err
+	}
+	return invocation, nil
+}
+
+// Request records an attribute request made by an invocation. Requests made after the invocation has finished,
+// such as the later steps of a scene transition, are added to the persisted record.
+func (u *invocationService) Request(id string, entity string, key string, value string, err error) error {
+	if id == "" {
+		return nil
+	}
+	request := domain.InvocationRequest{
+		Entity: entity,
+		Key:    key,
+		Value:  value,
+		Time:   time.Now(),
+	}
+	if err != nil {
+		request.Error = err.Error()
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	invocation, ok := u.open[id]
+	if ok {
+		invocation.Requests = append(invocation.Requests, request)
+		return nil
+	}
+	invocation, err = u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	invocation.Requests = append(invocation.Requests, request)
+	return u.repository.Update(invocation)
+}
+
+// Record saves the progress of an open invocation, such as the steps of a subroutine
+func (u *invocationService) Record(invocation *domain.Invocation) error {
+	u.mutex.Lock()
+	err := u.repository.Update(invocation)
+	u.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return u.Emit(*invocation)
+}
+
+// Finish closes an invocation, recording its duration and error. Invocations that have not set their own status
+// succeed, or fail if an error is provided.
+func (u *invocationService) Finish(invocation *domain.Invocation, err error) error {
+	u.mutex.Lock()
+	delete(u.open, invocation.Id)
+	invocation.Finished = time.Now()
+	invocation.Duration = invocation.Finished.Sub(invocation.Started)
+	if err != nil {
+		invocation.Error = err.Error()
+	}
+	if invocation.Status == domain.RUNNING {
+		invocation.Status = domain.SUCCEEDED
+		if err != nil {
+			invocation.Status = domain.FAILED
+		}
+	}
+	err = u.repository.Update(invocation)
+	u.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return u.Emit(*invocation)
+}
+
+func (u *invocationService) Query(filter domain.InvocationFilter) (*domain.InvocationPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultInvocationLimit
+	}
+	if filter.Limit > maxInvocationLimit {
+		filter.Limit = maxInvocationLimit
+	}
+	invocations, total, err := u.repository.Query(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.InvocationPage{
+		Invocations: *invocations,
+		Total:       total,
+		Offset:      filter.Offset,
+		Limit:       filter.Limit,
+	}, nil
+}
+
+func (u *invocationService) Snapshot() ([]domain.Mutation, error) {
+	page, err := u.Query(domain.InvocationFilter{Limit: recentInvocations})
+	if err != nil {
+		return nil, err
+	}
+	return u.Mutations(page.Invocations), nil
+}
+
+func (u *invocationService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
+	}
+	u.Publish(snapshot)
+	return nil
+}
+
+// Repository Mapping
+
+func (u *invocationService) FindById(id string) (*domain.Invocation, error) {
+	return u.repository.FindById(id)
+}
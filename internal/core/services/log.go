@@ -3,44 +3,94 @@
 package services
 
 import (
+	"time"
 	"udap/internal/core/domain"
 	"udap/internal/core/generic"
 	"udap/internal/core/ports"
-	"udap/internal/core/repository"
+	"udap/internal/log"
 )
 
-func NewLogService() ports.LogService {
-	repo := repository.NewLogsRepository()
-	return &logService{repository: repo}
+const (
+	defaultLogLimit = 100
+	maxLogLimit     = 1000
+	recentLogs      = 50
+)
+
+func NewLogService(repository ports.LogRepository, retention time.Duration) ports.LogService {
+	return &logService{
+		repository: repository,
+		retention:  retention,
+	}
 }
 
 type logService struct {
 	repository ports.LogRepository
+	retention  time.Duration
 	generic.Watchable[domain.Log]
 }
 
+func (u *logService) Snapshot() ([]domain.Mutation, error) {
+	page, err := u.Query(domain.LogFilter{Limit: recentLogs})
+	if err != nil {
+		return nil, err
+	}
+	return u.Mutations(page.Logs), nil
+}
+
 func (u *logService) EmitAll() error {
-	all, err := u.repository.FindAll()
+	snapshot, err := u.Snapshot()
 	if err != nil {
 		return err
 	}
-	for _, log := range all {
-		err = u.Emit(log)
-		if err != nil {
-			return err
-		}
+	u.Publish(snapshot)
+	return nil
+}
+
+func (u *logService) Query(filter domain.LogFilter) (*domain.LogPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLogLimit
+	}
+	if filter.Limit > maxLogLimit {
+		filter.Limit = maxLogLimit
+	}
+	logs, total, err := u.repository.Query(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LogPage{
+		Logs:   *logs,
+		Total:  total,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}, nil
+}
+
+// Prune deletes logs older than the retention period
+func (u *logService) Prune() error {
+	if u.retention <= 0 {
+		return nil
+	}
+	removed, err := u.repository.DeleteBefore(time.Now().Add(-u.retention))
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		log.Event("Pruned %d logs older than %s.", removed, u.retention)
 	}
 	return nil
 }
 
 // Repository Mapping
 
-func (u *logService) Create(log *domain.Log) error {
-	err := u.repository.Create(log)
+func (u *logService) Create(l *domain.Log) error {
+	if l.Time.IsZero() {
+		l.Time = time.Now()
+	}
+	err := u.repository.Create(l)
 	if err != nil {
 		return err
 	}
-	err = u.Emit(*log)
+	err = u.Emit(*l)
 	if err != nil {
 		return err
 	}
@@ -19,41 +19,44 @@ type macroService struct {
 	generic.Watchable[domain.Macro]
 }
 
-func (u *macroService) Run(id string) error {
+func (u *macroService) Run(id string, source domain.Source) error {
 	byId, err := u.FindById(id)
 	if err != nil {
 		return err
 	}
-	err = u.operator.Run(*byId)
+	err = u.operator.Run(*byId, source)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u *macroService) RunAndRevert(id string, revert time.Duration) error {
+func (u *macroService) RunAndRevert(id string, source domain.Source, revert time.Duration) error {
 	byId, err := u.FindById(id)
 	if err != nil {
 		return err
 	}
-	err = u.operator.RunAndRevert(*byId, domain.Macro{}, revert)
+	err = u.operator.RunAndRevert(*byId, source, revert)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u *macroService) EmitAll() error {
+func (u *macroService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, macro := range *all {
-		err = u.Emit(macro)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *macroService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -15,16 +15,20 @@ import (
 	"udap/internal/core/generic"
 	"udap/internal/core/ports"
 	"udap/internal/log"
+	"udap/internal/plugin/archive"
 	"udap/internal/pulse"
+	"udap/platform/secret"
 )
 
 func NewModuleService(repository ports.ModuleRepository, runtime ports.ModuleOperator) ports.ModuleService {
-	return &moduleService{
-		repository:    repository,
-		operator:      runtime,
-		timeoutsMutex: sync.RWMutex{},
-		timeouts:      map[string]*time.Timer{},
-	}
+	service := &moduleService{
+		repository:      repository,
+		operator:        runtime,
+		supervised:      map[string]*supervision{},
+		supervisedMutex: sync.Mutex{},
+	}
+	service.scheduler = newScheduler(service.Update)
+	return service
 }
 
 const DIR = "modules"
@@ -41,10 +45,11 @@ const (
 )
 
 type moduleService struct {
-	repository    ports.ModuleRepository
-	operator      ports.ModuleOperator
-	timeouts      map[string]*time.Timer
-	timeoutsMutex sync.RWMutex
+	repository      ports.ModuleRepository
+	operator        ports.ModuleOperator
+	scheduler       *scheduler
+	supervised      map[string]*supervision
+	supervisedMutex sync.Mutex
 	generic.Watchable[domain.Module]
 }
 
@@ -56,29 +61,44 @@ func (u *moduleService) HandleEmits(mutation domain.Mutation) error {
 	return nil
 }
 
-func (u *moduleService) EmitAll() error {
+// Subscribe delivers mutations matching the filter to a module through its OnEmit function
+func (u *moduleService) Subscribe(uuid string, filter domain.MutationFilter) error {
+	return u.operator.Subscribe(uuid, filter)
+}
+
+// Snapshot describes every module, with the values of secret variables redacted as they are in Emit
+func (u *moduleService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	redacted := make([]domain.Module, 0, len(*all))
 	for _, module := range *all {
-		err = u.Emit(module)
+		module, err = redact(module)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		redacted = append(redacted, module)
 	}
-	return nil
+	return u.Mutations(redacted), nil
 }
 
-// Panic handles a panic state
-func (u *moduleService) Panic(module domain.Module) error {
-	log.Recovered("Module '%s' panicked; module entering safe-mode", module.Name)
-	err := u.Dispose(module.Id)
+func (u *moduleService) EmitAll() error {
+	snapshot, err := u.Snapshot()
 	if err != nil {
-		log.Err(fmt.Errorf("module disposal failed, runtime must be flushed to resume operation: %s",
-			err.Error()))
 		return err
 	}
+	u.Publish(snapshot)
+	return nil
+}
+
+// Panic handles a panic state, the supervisor disposes of the module and restarts it
+func (u *moduleService) Panic(module domain.Module) error {
+	log.Recovered("Module '%s' panicked; module entering safe-mode", module.Name)
+	u.supervisedMutex.Lock()
+	u.supervisor(module.Name).health.Panics++
+	u.supervisedMutex.Unlock()
+	u.fault(module.Id, "panic")
 	return nil
 }
 
@@ -109,27 +129,10 @@ func (u *moduleService) Update(id string) error {
 	defer pulse.End(addr)
 	// Attempt to update the modules
 	err = u.operator.Update(module.UUID)
+	u.observe(module, err)
 	if err != nil {
 		return err
 	}
-
-	go func(mod *domain.Module) {
-		duration := mod.Interval
-		if duration < time.Second {
-			return
-		}
-		timer := time.NewTimer(duration)
-		u.timeoutsMutex.Lock()
-		u.timeouts[mod.UUID] = timer
-		u.timeoutsMutex.Unlock()
-		select {
-		case _ = <-timer.C:
-			err = u.Update(id)
-			if err != nil {
-				return
-			}
-		}
-	}(module)
 	// Return normally
 	return nil
 }
@@ -143,6 +146,12 @@ func (u *moduleService) Run(id string) error {
 	if module.Running {
 		return fmt.Errorf("module must not be running to run")
 	}
+	// Refuse to run a module that is missing required variables
+	err = checkVariables(module)
+	if err != nil {
+		_ = u.setState(module.Id, ERROR)
+		return err
+	}
 	// Mark the module start as starting
 	err = u.setState(module.Id, STARTING)
 	if err != nil {
@@ -179,16 +188,15 @@ func (u *moduleService) Run(id string) error {
 	if err != nil {
 		return err
 	}
+	u.started(module)
 	// Mark the module as running
 	err = u.setState(module.Id, RUNNING)
 	if err != nil {
 		return err
 	}
+	// Begin updating the module at its interval
 	if module.Enabled && module.Running {
-		err = u.Update(module.Id)
-		if err != nil {
-			return err
-		}
+		u.scheduler.Add(*module)
 	}
 
 	return nil
@@ -211,17 +219,32 @@ func (u *moduleService) Load(id string) error {
 	}()
 	start := time.Now()
 	// Attempt to load the module
-	config, err := u.operator.Load(module.Name, module.UUID)
+	config, err := u.operator.Load(module.Name, module.UUID, module.Runtime)
 	if err != nil {
 		return err
 	}
-	module.Variables = config.Variables
-	module.Version = config.Version
-	module.Description = config.Description
+	// Packaged modules may leave their details and variables to the package manifest
+	if config.Variables != "null" && config.Variables != "[]" {
+		module.Variables = config.Variables
+	}
+	if config.Version != "" {
+		module.Version = config.Version
+	}
+	if config.Description != "" {
+		module.Description = config.Description
+	}
+	if config.Author != "" {
+		module.Author = config.Author
+	}
+	module.Grants = config.Grants
 	module.Interval = config.Interval
+	module.Jitter = config.Jitter
 	module.Type = config.Type
-	module.Author = config.Author
 	module.Running = false
+	err = u.initVariables(module)
+	if err != nil {
+		return err
+	}
 	log.Event("Module '%s' @ 0x%s loaded. (%s)", module.Name, module.SessionId(),
 		time.Since(start).String())
 	err = u.repository.Update(module)
@@ -249,7 +272,7 @@ func (u *moduleService) Build(id string) error {
 		return err
 	}
 	// start := time.Now()
-	err = u.operator.Build(module.Name, module.UUID)
+	err = u.operator.Build(module.Name, module.UUID, module.Runtime)
 	if err != nil {
 		return err
 	}
@@ -267,6 +290,8 @@ func (u *moduleService) Dispose(id string) error {
 	if !module.Enabled || !module.Running {
 		return fmt.Errorf("module must be enabled and running to dispose")
 	}
+	// Stop updating the module before it is disposed
+	u.scheduler.Remove(module.Id)
 	// Mark the state as halting, since it may take a while
 	err = u.setState(module.Id, HALTING)
 	if err != nil {
@@ -284,7 +309,8 @@ func (u *moduleService) Dispose(id string) error {
 		time.Since(start).Truncate(time.Millisecond).String())
 	module.UUID = ""
 	// Mark the module as stopped if the disposal was successful
-	err = u.setState(module.Id, STOPPED)
+	module.State = STOPPED
+	err = u.save(module)
 	if err != nil {
 		return err
 	}
@@ -359,6 +385,8 @@ func (u *moduleService) RunAll() error {
 }
 
 func (u *moduleService) DisposeAll() error {
+	// Cancel all pending updates before the modules are disposed
+	u.scheduler.Clear()
 	modules, err := u.repository.FindAll()
 	if err != nil {
 		return err
@@ -450,6 +478,15 @@ func (u *moduleService) Discover() error {
 			target.Name = name
 			target.Path = p
 			target.State = DISCOVERED
+			// Modules installed from a package are described by their manifest
+			var manifest domain.Manifest
+			manifest, err = archive.ReadManifest(filepath.Dir(p))
+			if err == nil && manifest.Name == name {
+				err = describe(target, manifest)
+				if err != nil {
+					log.Err(err)
+				}
+			}
 			err = u.repository.Create(target)
 			if err != nil {
 				continue
@@ -509,25 +546,47 @@ func (u *moduleService) BuildAll() error {
 	return nil
 }
 
-func (u *moduleService) GetConfig(id string, key string) (string, error) {
-	byId, err := u.repository.FindByUUID(id)
+// configValues parses the module's stored configuration
+func configValues(module *domain.Module) map[string]string {
+	var values map[string]string
+	err := json.Unmarshal([]byte(module.Config), &values)
+	if err != nil || values == nil {
+		values = map[string]string{}
+	}
+	return values
+}
+
+// storeConfig writes a configuration value to the module, values of secret variables are sealed
+func storeConfig(module *domain.Module, key string, value string) error {
+	values := configValues(module)
+	if variable, ok := module.Variable(key); ok && variable.Secret() && !secret.Sealed(value) {
+		sealed, err := secret.Seal(value)
+		if err != nil {
+			return err
+		}
+		value = sealed
+	}
+	values[key] = value
+	marshal, err := json.Marshal(values)
 	if err != nil {
-		return "", err
+		return err
 	}
+	module.Config = string(marshal)
+	return nil
+}
 
-	var values map[string]string
-	err = json.Unmarshal([]byte(byId.Config), &values)
+func (u *moduleService) GetConfig(id string, key string) (string, error) {
+	byId, err := u.repository.FindByUUID(id)
 	if err != nil {
-		err = nil
-		values = map[string]string{}
+		return "", err
 	}
 
-	value, ok := values[key]
+	value, ok := configValues(byId)[key]
 	if !ok {
 		return "", fmt.Errorf("config value on key '%s' does not exist", key)
 	}
 
-	return value, nil
+	return secret.Open(value)
 }
 
 func (u *moduleService) InitConfig(id string, key string, value string) error {
@@ -536,27 +595,16 @@ func (u *moduleService) InitConfig(id string, key string, value string) error {
 		return err
 	}
 
-	var values map[string]string
-	err = json.Unmarshal([]byte(byId.Config), &values)
-	if err != nil {
-		err = nil
-		values = map[string]string{}
-	}
-
-	val, ok := values[key]
+	val, ok := configValues(byId)[key]
 	if val != "" && ok {
 		return nil
 	}
 
-	values[key] = value
-
-	marshal, err := json.Marshal(values)
+	err = storeConfig(byId, key, value)
 	if err != nil {
 		return err
 	}
 
-	byId.Config = string(marshal)
-
 	err = u.save(byId)
 	if err != nil {
 		return err
@@ -570,30 +618,119 @@ func (u *moduleService) SetConfig(id string, key string, value string) error {
 		return err
 	}
 
-	var values map[string]string
-	err = json.Unmarshal([]byte(byId.Config), &values)
-	if err != nil {
-		err = nil
-		values = map[string]string{}
+	if variable, ok := byId.Variable(key); ok {
+		err = variable.Validate(value)
+		if err != nil {
+			return err
+		}
 	}
 
-	values[key] = value
+	err = storeConfig(byId, key, value)
+	if err != nil {
+		return err
+	}
 
-	marshal, err := json.Marshal(values)
+	err = u.save(byId)
 	if err != nil {
 		return err
 	}
 
-	byId.Config = string(marshal)
+	return nil
+}
 
-	err = u.save(byId)
+// Configure changes a module's variable from the api. The value is validated against the variable's
+// declaration, and a running module is notified of the change instead of being reloaded.
+func (u *moduleService) Configure(name string, key string, value string) error {
+	module, err := u.repository.FindByName(name)
+	if err != nil {
+		return err
+	}
+	variable, declared := module.Variable(key)
+	if declared {
+		err = variable.Validate(value)
+		if err != nil {
+			return err
+		}
+	} else if _, ok := configValues(module)[key]; !ok {
+		return fmt.Errorf("module '%s' has no variable '%s'", name, key)
+	}
+	err = storeConfig(module, key, value)
 	if err != nil {
 		return err
 	}
+	err = u.save(module)
+	if err != nil {
+		return err
+	}
+	if !module.Enabled || !module.Running {
+		return nil
+	}
+	return u.operator.Configure(module.UUID, key, value)
+}
+
+// initVariables stores the default of each declared variable that has not been set. Values of secret variables
+// stored before the variable was declared secret are sealed.
+func (u *moduleService) initVariables(module *domain.Module) error {
+	values := configValues(module)
+	for _, variable := range module.Declared() {
+		value := values[variable.Name]
+		if value == "" {
+			value = variable.Default
+		} else if !variable.Secret() || secret.Sealed(value) {
+			continue
+		}
+		if value == "" {
+			continue
+		}
+		err := storeConfig(module, variable.Name, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// checkVariables ensures every required variable has a valid value
+func checkVariables(module *domain.Module) error {
+	values := configValues(module)
+	for _, variable := range module.Declared() {
+		value, err := secret.Open(values[variable.Name])
+		if err != nil {
+			return err
+		}
+		err = variable.Validate(value)
+		if err != nil {
+			return fmt.Errorf("module '%s' is misconfigured: %s", module.Name, err.Error())
+		}
+	}
 	return nil
 }
 
+// redact replaces the values of secret variables in a copy of the module's configuration
+func redact(module domain.Module) (domain.Module, error) {
+	values := configValues(&module)
+	for key, value := range values {
+		if variable, ok := module.Variable(key); (ok && variable.Secret()) || secret.Sealed(value) {
+			values[key] = domain.REDACTED
+		}
+	}
+	marshal, err := json.Marshal(values)
+	if err != nil {
+		return domain.Module{}, err
+	}
+	module.Config = string(marshal)
+	return module, nil
+}
+
+// Emit sends a module mutation with the values of secret variables redacted
+func (u *moduleService) Emit(module domain.Module) error {
+	module, err := redact(module)
+	if err != nil {
+		return err
+	}
+	return u.Watchable.Emit(module)
+}
+
 // Repository Mapping
 
 func (u *moduleService) FindAll() (*[]domain.Module, error) {
@@ -609,6 +746,8 @@ func (u *moduleService) Disable(id string) error {
 	if err != nil {
 		return err
 	}
+	u.settle(module.Name)
+	u.scheduler.Remove(module.Id)
 	module.Enabled = false
 	err = u.repository.Update(module)
 	if err != nil {
@@ -638,11 +777,16 @@ func (u *moduleService) Enable(id string) error {
 	if err != nil {
 		return err
 	}
+	u.settle(module.Name)
 	module.Enabled = true
 	err = u.repository.Update(module)
 	if err != nil {
 		return err
 	}
+	// Resume updating a module that was disabled while running
+	if module.Running {
+		u.scheduler.Add(*module)
+	}
 	err = u.Emit(*module)
 	if err != nil {
 		return err
@@ -656,6 +800,7 @@ func (u *moduleService) Reload(name string) error {
 	if err != nil {
 		return err
 	}
+	u.settle(target.Name)
 	if !target.Enabled {
 		return fmt.Errorf("module must be enabled and running to reload")
 	}
@@ -685,9 +830,71 @@ func (u *moduleService) Halt(name string) error {
 	if err != nil {
 		return err
 	}
+	u.settle(byName.Name)
 	err = u.Dispose(byName.Id)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// SetRuntime selects whether a module is loaded as a plugin or run in its own process, a running module is
+// reloaded into the new runtime
+func (u *moduleService) SetRuntime(name string, runtime string) error {
+	if runtime != domain.PLUGIN && runtime != domain.PROCESS {
+		return fmt.Errorf("unknown module runtime '%s'", runtime)
+	}
+	module, err := u.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if module.Runtime == runtime {
+		return nil
+	}
+	running := module.Enabled && module.Running
+	if running {
+		err = u.Dispose(module.Id)
+		if err != nil {
+			return err
+		}
+		module, err = u.repository.FindById(module.Id)
+		if err != nil {
+			return err
+		}
+	}
+	module.Runtime = runtime
+	err = u.save(module)
+	if err != nil {
+		return err
+	}
+	if !running {
+		return nil
+	}
+	err = u.Build(module.Id)
+	if err != nil {
+		return err
+	}
+	err = u.Load(module.Id)
+	if err != nil {
+		return err
+	}
+	return u.Run(module.Id)
+}
+
+// Schedule reports the update cadence of a running module
+func (u *moduleService) Schedule(name string) (domain.ModuleSchedule, error) {
+	module, err := u.repository.FindByName(name)
+	if err != nil {
+		return domain.ModuleSchedule{}, err
+	}
+	schedule, ok := u.scheduler.Schedule(module.Id)
+	if !ok {
+		return domain.ModuleSchedule{}, fmt.Errorf("module '%s' is not scheduled", name)
+	}
+	return schedule, nil
+}
+
+// Schedules reports the update cadence of every running module
+func (u *moduleService) Schedules() []domain.ModuleSchedule {
+	return u.scheduler.Schedules()
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"testing"
+	"udap/internal/core/domain"
+	"udap/platform/secret"
+)
+
+func TestModuleService_InitVariablesSealsSecrets(t *testing.T) {
+	t.Setenv("secretKey", "test")
+	module := &domain.Module{
+		Variables: `[{"name":"key","type":"secret"},{"name":"host","default":"10.0.1.2"}]`,
+		Config:    `{"key":"plaintext"}`,
+	}
+
+	err := (&moduleService{}).initVariables(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := configValues(module)
+	if !secret.Sealed(values["key"]) {
+		t.Errorf("the stored secret was not sealed")
+	}
+	if opened, _ := secret.Open(values["key"]); opened != "plaintext" {
+		t.Errorf("the sealed secret opened as '%s'", opened)
+	}
+	if values["host"] != "10.0.1.2" {
+		t.Errorf("the default was stored as '%s'", values["host"])
+	}
+}
+
+func TestModuleService_SnapshotRedactsSecrets(t *testing.T) {
+	repository := &supervisedModules{}
+	repository.module = domain.Module{
+		Name:      "govee",
+		Variables: `[{"name":"key","type":"secret"}]`,
+		Config:    `{"key":"plaintext","host":"10.0.1.2"}`,
+	}
+	service := NewModuleService(repository, nil)
+
+	snapshot, err := service.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot) != 1 {
+		t.Fatalf("the snapshot has %d mutations, want 1", len(snapshot))
+	}
+	values := configValues(&domain.Module{Config: snapshot[0].Body.(domain.Module).Config})
+	if values["key"] != domain.REDACTED || values["host"] != "10.0.1.2" {
+		t.Errorf("the snapshot configuration is %v", values)
+	}
+}
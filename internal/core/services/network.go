@@ -17,17 +17,20 @@ type networkService struct {
 	generic.Watchable[domain.Network]
 }
 
-func (u *networkService) EmitAll() error {
+func (u *networkService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, network := range *all {
-		err = u.Emit(network)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *networkService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -17,17 +17,20 @@ type notificationService struct {
 	generic.Watchable[domain.Notification]
 }
 
-func (u *notificationService) EmitAll() error {
+func (u *notificationService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, notification := range *all {
-		err = u.Emit(notification)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *notificationService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -46,7 +49,11 @@ func (u *notificationService) FindById(id string) (*domain.Notification, error)
 }
 
 func (u *notificationService) Create(notification *domain.Notification) error {
-	return u.repository.Create(notification)
+	err := u.repository.Create(notification)
+	if err != nil {
+		return err
+	}
+	return u.Emit(*notification)
 }
 
 func (u *notificationService) FindOrCreate(notification *domain.Notification) error {
@@ -0,0 +1,223 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"udap/internal/core/domain"
+	"udap/internal/log"
+)
+
+// Stage unpacks a module package so it can be installed, the package is refused if its dependencies are not met
+func (u *moduleService) Stage(archive io.Reader) (domain.Manifest, error) {
+	manifest, err := u.operator.Unpack(archive)
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	err = u.checkDependencies(manifest)
+	if err != nil {
+		_ = u.operator.Discard(manifest.Name)
+		return domain.Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// Install installs or upgrades a module from its staged package. The module is built and loaded, and run if it
+// is new or was running before the upgrade. An upgrade that fails to build or load is reverted to the version it
+// replaced.
+func (u *moduleService) Install(name string) (*domain.Module, error) {
+	manifest, err := u.operator.Staged(name)
+	if err != nil {
+		return nil, err
+	}
+	module, err := u.repository.FindByName(manifest.Name)
+	if err != nil {
+		return u.installNew(manifest)
+	}
+	u.settle(module.Name)
+	running := module.Enabled && module.Running
+	if running {
+		err = u.Dispose(module.Id)
+		if err != nil {
+			_ = u.operator.Discard(manifest.Name)
+			return nil, err
+		}
+	}
+	err = u.operator.Install(manifest.Name)
+	if err != nil {
+		_ = u.operator.Discard(manifest.Name)
+		return nil, err
+	}
+	module, err = u.repository.FindById(module.Id)
+	if err != nil {
+		return nil, err
+	}
+	prior := *module
+	module.Previous = prior.Version
+	err = describe(module, manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = u.save(module)
+	if err != nil {
+		return nil, err
+	}
+	err = u.load(module.Id)
+	if err == nil {
+		if running {
+			err = u.Run(module.Id)
+		}
+		return u.installed(module.Id, err)
+	}
+	// Restore the version that was replaced, the failed version is kept as the previous version
+	log.Event("Module '%s' %s failed to load, reverting to %s", manifest.Name, manifest.Version, prior.Version)
+	failure := err
+	err = u.operator.Revert(manifest.Name)
+	if err != nil {
+		return nil, fmt.Errorf("module '%s' failed to load (%s) and could not be reverted: %s", manifest.Name,
+			failure.Error(), err.Error())
+	}
+	err = u.restore(module.Id, prior, manifest.Version, running)
+	if err != nil {
+		log.Err(err)
+	}
+	return nil, fmt.Errorf("module '%s' %s failed to load and was reverted to %s: %s", manifest.Name,
+		manifest.Version, prior.Version, failure.Error())
+}
+
+// installNew installs a module that is not yet known to the system
+func (u *moduleService) installNew(manifest domain.Manifest) (*domain.Module, error) {
+	err := u.operator.Install(manifest.Name)
+	if err != nil {
+		_ = u.operator.Discard(manifest.Name)
+		return nil, err
+	}
+	module := &domain.Module{
+		Name:    manifest.Name,
+		Path:    fmt.Sprintf("%s/%s/%s.go", DIR, manifest.Name, manifest.Name),
+		State:   DISCOVERED,
+		Enabled: true,
+	}
+	err = describe(module, manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = u.repository.Create(module)
+	if err != nil {
+		return nil, err
+	}
+	err = u.load(module.Id)
+	if err == nil {
+		err = u.Run(module.Id)
+	}
+	return u.installed(module.Id, err)
+}
+
+// installed provides the module once it has been installed
+func (u *moduleService) installed(id string, failure error) (*domain.Module, error) {
+	if failure != nil {
+		return nil, failure
+	}
+	return u.repository.FindById(id)
+}
+
+// Rollback swaps a module with its previous version, a running module is restarted on the previous version
+func (u *moduleService) Rollback(name string) error {
+	module, err := u.repository.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if module.Previous == "" {
+		return fmt.Errorf("module '%s' has no previous version", name)
+	}
+	u.settle(module.Name)
+	running := module.Enabled && module.Running
+	if running {
+		err = u.Dispose(module.Id)
+		if err != nil {
+			return err
+		}
+	}
+	err = u.operator.Revert(module.Name)
+	if err != nil {
+		return err
+	}
+	module, err = u.repository.FindById(module.Id)
+	if err != nil {
+		return err
+	}
+	prior := *module
+	prior.Version = module.Previous
+	return u.restore(module.Id, prior, module.Version, running)
+}
+
+// restore returns a module's record to a prior version, and loads and runs it again if it was running
+func (u *moduleService) restore(id string, prior domain.Module, previous string, running bool) error {
+	module, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	module.Version = prior.Version
+	module.Previous = previous
+	module.Description = prior.Description
+	module.Author = prior.Author
+	module.Variables = prior.Variables
+	err = u.save(module)
+	if err != nil {
+		return err
+	}
+	if !running {
+		return nil
+	}
+	err = u.load(module.Id)
+	if err != nil {
+		return err
+	}
+	return u.Run(module.Id)
+}
+
+// load builds and loads a module, the module is left in the error state if either fails
+func (u *moduleService) load(id string) error {
+	err := u.Build(id)
+	if err == nil {
+		err = u.Load(id)
+	}
+	if err != nil {
+		_ = u.setState(id, ERROR)
+		return err
+	}
+	return nil
+}
+
+// checkDependencies ensures each module a package depends on is installed at the required version
+func (u *moduleService) checkDependencies(manifest domain.Manifest) error {
+	for _, dependency := range manifest.Dependencies {
+		module, err := u.repository.FindByName(dependency.Module)
+		if err != nil {
+			return fmt.Errorf("module '%s' requires module '%s'", manifest.Name, dependency.Module)
+		}
+		if !dependency.Satisfied(module.Version) {
+			return fmt.Errorf("module '%s' requires module '%s' %s or later, %s is installed", manifest.Name,
+				dependency.Module, dependency.Version, module.Version)
+		}
+	}
+	return nil
+}
+
+// describe copies the details of a package's manifest to its module
+func describe(module *domain.Module, manifest domain.Manifest) error {
+	module.Version = manifest.Version
+	module.Description = manifest.Description
+	module.Author = manifest.Author
+	if len(manifest.Variables) == 0 {
+		return nil
+	}
+	marshal, err := json.Marshal(manifest.Variables)
+	if err != nil {
+		return err
+	}
+	module.Variables = string(marshal)
+	return nil
+}
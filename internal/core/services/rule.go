@@ -0,0 +1,236 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+	"udap/internal/log"
+)
+
+func NewRuleService(repository ports.RuleRepository, attributes ports.AttributeRepository,
+	operator ports.RuleOperator) ports.RuleService {
+	service := &ruleService{
+		repository: repository,
+		operator:   operator,
+		values:     map[string]string{},
+		bound:      map[string][]domain.Rule{},
+	}
+	// Start from the stored values so the first change after a restart can fire a rule
+	err := service.seed(attributes)
+	if err != nil {
+		log.Err(err)
+	}
+	return service
+}
+
+type ruleService struct {
+	repository ports.RuleRepository
+	operator   ports.RuleOperator
+	values     map[string]string
+	// bound caches the enabled rules of each attribute by composite, it is cleared whenever a rule changes.
+	// generation counts the changes, so a lookup that raced a change is not cached.
+	bound      map[string][]domain.Rule
+	generation int
+	mutex      sync.Mutex
+	generic.Watchable[domain.Rule]
+}
+
+// seed records the last known value of every attribute
+func (u *ruleService) seed(attributes ports.AttributeRepository) error {
+	all, err := attributes.FindAll()
+	if err != nil {
+		return err
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	for _, attribute := range *all {
+		u.values[fmt.Sprintf("%s.%s", attribute.Entity, attribute.Key)] = attribute.Value
+	}
+	return nil
+}
+
+// HandleMutation evaluates the rules bound to an attribute whenever its value changes
+func (u *ruleService) HandleMutation(mutation domain.Mutation) error {
+	attribute, ok := mutation.Body.(domain.Attribute)
+	if !ok {
+		return nil
+	}
+	composite := fmt.Sprintf("%s.%s", attribute.Entity, attribute.Key)
+	// Record the value so the next change can be compared against it
+	u.mutex.Lock()
+	previous, known := u.values[composite]
+	u.values[composite] = attribute.Value
+	u.mutex.Unlock()
+	if !known || previous == attribute.Value {
+		return nil
+	}
+
+	rules, err := u.rules(attribute.Entity, attribute.Key)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if !rule.Matches(previous, attribute.Value) {
+			continue
+		}
+		go func(rule domain.Rule) {
+			err := u.fire(rule)
+			if err != nil {
+				log.Err(err)
+			}
+		}(rule)
+	}
+
+	return nil
+}
+
+// rules finds the enabled rules bound to an attribute, the repository is only queried once for each attribute
+// until a rule changes
+func (u *ruleService) rules(entity string, key string) ([]domain.Rule, error) {
+	composite := fmt.Sprintf("%s.%s", entity, key)
+	u.mutex.Lock()
+	rules, ok := u.bound[composite]
+	generation := u.generation
+	u.mutex.Unlock()
+	if ok {
+		return rules, nil
+	}
+	found, err := u.repository.FindByAttribute(entity, key)
+	if err != nil {
+		return nil, err
+	}
+	u.mutex.Lock()
+	if generation == u.generation {
+		u.bound[composite] = *found
+	}
+	u.mutex.Unlock()
+	return *found, nil
+}
+
+// forget clears the cached rules after a rule changes
+func (u *ruleService) forget() {
+	u.mutex.Lock()
+	u.bound = map[string][]domain.Rule{}
+	u.generation++
+	u.mutex.Unlock()
+}
+
+func (u *ruleService) fire(rule domain.Rule) error {
+	holds, err := u.operator.Evaluate(rule)
+	if err != nil {
+		return err
+	}
+	if !holds {
+		return nil
+	}
+	log.Event("Rule '%s' fired.", rule.Name)
+	err = u.operator.Run(rule)
+	if err != nil {
+		return err
+	}
+	rule.LastFired = time.Now()
+	err = u.mutate(&rule)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *ruleService) Snapshot() ([]domain.Mutation, error) {
+	all, err := u.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return u.Mutations(*all), nil
+}
+
+func (u *ruleService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
+	}
+	u.Publish(snapshot)
+	return nil
+}
+
+func (u *ruleService) mutate(rule *domain.Rule) error {
+	err := u.repository.Update(rule)
+	if err != nil {
+		return err
+	}
+	u.forget()
+	err = u.Emit(*rule)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *ruleService) Enable(id string) error {
+	rule, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	rule.Enabled = true
+	return u.mutate(rule)
+}
+
+func (u *ruleService) Disable(id string) error {
+	rule, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	rule.Enabled = false
+	return u.mutate(rule)
+}
+
+// Repository Mapping
+
+func (u *ruleService) FindAll() (*[]domain.Rule, error) {
+	return u.repository.FindAll()
+}
+
+func (u *ruleService) FindById(id string) (*domain.Rule, error) {
+	return u.repository.FindById(id)
+}
+
+func (u *ruleService) Create(rule *domain.Rule) error {
+	err := u.repository.Create(rule)
+	if err != nil {
+		return err
+	}
+	u.forget()
+	err = u.Emit(*rule)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *ruleService) Update(rule *domain.Rule) error {
+	return u.mutate(rule)
+}
+
+func (u *ruleService) Delete(id string) error {
+	byId, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = u.repository.Delete(byId)
+	if err != nil {
+		return err
+	}
+	u.forget()
+	byId.Deleted = true
+	err = u.Emit(*byId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
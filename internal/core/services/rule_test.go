@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"testing"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type boundRules struct {
+	ports.RuleRepository
+	rules   []domain.Rule
+	lookups int
+}
+
+func (r *boundRules) FindByAttribute(string, string) (*[]domain.Rule, error) {
+	r.lookups++
+	rules := r.rules
+	return &rules, nil
+}
+
+func (r *boundRules) Update(*domain.Rule) error {
+	return nil
+}
+
+type storedAttributes struct {
+	ports.AttributeRepository
+}
+
+func (a *storedAttributes) FindAll() (*[]domain.Attribute, error) {
+	return &[]domain.Attribute{{Entity: "sensor", Key: "motion", Value: "false"}}, nil
+}
+
+type evaluatedRules struct {
+	evaluated chan string
+}
+
+func (o *evaluatedRules) Evaluate(rule domain.Rule) (bool, error) {
+	o.evaluated <- rule.Name
+	return false, nil
+}
+
+func (o *evaluatedRules) Run(domain.Rule) error {
+	return nil
+}
+
+func TestRuleService_CachesRules(t *testing.T) {
+	rule := domain.Rule{Name: "motion", Entity: "sensor", Key: "motion", Mode: domain.CHANGES}
+	repository := &boundRules{rules: []domain.Rule{rule}}
+	operator := &evaluatedRules{evaluated: make(chan string, 8)}
+	service := NewRuleService(repository, &storedAttributes{}, operator)
+
+	for _, value := range []string{"true", "false", "true"} {
+		err := service.HandleMutation(domain.Mutation{Body: domain.Attribute{Entity: "sensor", Key: "motion",
+			Value: value}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-operator.evaluated
+	}
+	if repository.lookups != 1 {
+		t.Errorf("the rules were looked up %d times for three changes, want 1", repository.lookups)
+	}
+
+	err := service.Update(&repository.rules[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = service.HandleMutation(domain.Mutation{Body: domain.Attribute{Entity: "sensor", Key: "motion",
+		Value: "false"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-operator.evaluated
+	if repository.lookups != 2 {
+		t.Errorf("the rules were looked up %d times after a rule changed, want 2", repository.lookups)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"fmt"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/generic"
+	"udap/internal/core/ports"
+)
+
+func NewSceneService(repository ports.SceneRepository, operator ports.SceneOperator) ports.SceneService {
+	return &sceneService{repository: repository, operator: operator}
+}
+
+type sceneService struct {
+	repository ports.SceneRepository
+	operator   ports.SceneOperator
+	generic.Watchable[domain.Scene]
+}
+
+// Capture creates a scene from the current state of a zone
+func (u *sceneService) Capture(name string, zoneId string, keys []string) (*domain.Scene, error) {
+	if len(keys) == 0 {
+		keys = domain.DefaultSceneKeys
+	}
+	values, err := u.operator.Capture(zoneId, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("zone has no attributes to capture")
+	}
+	scene := domain.Scene{
+		Name:   name,
+		ZoneId: zoneId,
+		Values: values,
+	}
+	err = u.Create(&scene)
+	if err != nil {
+		return nil, err
+	}
+	return &scene, nil
+}
+
+// Recapture replaces the values of a scene with the current state of its zone
+func (u *sceneService) Recapture(id string) error {
+	scene, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	if scene.ZoneId == "" {
+		return fmt.Errorf("scene '%s' was not captured from a zone", scene.Name)
+	}
+	keys := map[string]bool{}
+	var ordered []string
+	for _, value := range scene.Values {
+		if keys[value.Key] {
+			continue
+		}
+		keys[value.Key] = true
+		ordered = append(ordered, value.Key)
+	}
+	if len(ordered) == 0 {
+		ordered = domain.DefaultSceneKeys
+	}
+	values, err := u.operator.Capture(scene.ZoneId, ordered)
+	if err != nil {
+		return err
+	}
+	scene.Values = values
+	return u.mutate(scene)
+}
+
+func (u *sceneService) Apply(id string, source domain.Source) error {
+	scene, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	return u.apply(scene, scene.Transition, source)
+}
+
+func (u *sceneService) ApplyWithTransition(id string, transition time.Duration, source domain.Source) error {
+	scene, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	return u.apply(scene, transition, source)
+}
+
+func (u *sceneService) apply(scene *domain.Scene, transition time.Duration, source domain.Source) error {
+	err := u.operator.Apply(*scene, transition, source)
+	if err != nil {
+		return err
+	}
+	scene.LastApplied = time.Now()
+	return u.mutate(scene)
+}
+
+func (u *sceneService) Snapshot() ([]domain.Mutation, error) {
+	all, err := u.repository.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	return u.Mutations(*all), nil
+}
+
+func (u *sceneService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
+	}
+	u.Publish(snapshot)
+	return nil
+}
+
+func (u *sceneService) mutate(scene *domain.Scene) error {
+	err := u.repository.Update(scene)
+	if err != nil {
+		return err
+	}
+	err = u.Emit(*scene)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Repository Mapping
+
+func (u *sceneService) FindAll() (*[]domain.Scene, error) {
+	return u.repository.FindAll()
+}
+
+func (u *sceneService) FindById(id string) (*domain.Scene, error) {
+	return u.repository.FindById(id)
+}
+
+func (u *sceneService) Create(scene *domain.Scene) error {
+	err := u.repository.Create(scene)
+	if err != nil {
+		return err
+	}
+	err = u.Emit(*scene)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *sceneService) Update(scene *domain.Scene) error {
+	return u.mutate(scene)
+}
+
+func (u *sceneService) Delete(id string) error {
+	byId, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = u.repository.Delete(byId)
+	if err != nil {
+		return err
+	}
+	byId.Deleted = true
+	err = u.Emit(*byId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"fmt"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/log"
+)
+
+// StartSchedules arms every scheduled trigger, handling runs that were missed while the system was down
+func (u *triggerService) StartSchedules() error {
+	triggers, err := u.repository.FindAll()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, trigger := range *triggers {
+		if !trigger.Scheduled() {
+			continue
+		}
+		trigger := trigger
+		missed := !trigger.NextFire.IsZero() && trigger.NextFire.Before(now)
+		if missed && trigger.Schedule.Policy == domain.CATCHUP {
+			log.Event("Trigger '%s' missed a scheduled run, catching up.", trigger.Name)
+			go u.fire(trigger.Id)
+			continue
+		}
+		err = u.arm(&trigger, now)
+		if err != nil {
+			log.ErrF(err, "trigger '%s' could not be scheduled", trigger.Name)
+		}
+	}
+	return nil
+}
+
+// Schedule replaces the schedule of a trigger and arms it, an empty schedule removes it
+func (u *triggerService) Schedule(id string, schedule domain.Schedule) error {
+	err := schedule.Validate()
+	if err != nil {
+		return err
+	}
+	trigger, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	trigger.Schedule = schedule
+	trigger.NextFire = time.Time{}
+	if !trigger.Scheduled() {
+		u.disarm(trigger.Id)
+		return u.save(trigger)
+	}
+	return u.arm(trigger, time.Now())
+}
+
+// arm computes the next fire time after the provided time, persists it and starts a timer
+func (u *triggerService) arm(trigger *domain.Trigger, after time.Time) error {
+	u.disarm(trigger.Id)
+	if isSolar(trigger.Schedule.Kind) && u.location == (domain.Location{}) {
+		return fmt.Errorf("location is not configured for solar schedules")
+	}
+	next, err := trigger.Schedule.Next(after, trigger.LastTrigger, u.location)
+	if err != nil {
+		return err
+	}
+	trigger.NextFire = next
+	err = u.save(trigger)
+	if err != nil {
+		return err
+	}
+	id := trigger.Id
+	u.mutex.Lock()
+	u.timers[id] = time.AfterFunc(time.Until(next), func() {
+		u.fire(id)
+	})
+	u.mutex.Unlock()
+	return nil
+}
+
+func (u *triggerService) disarm(id string) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	timer, ok := u.timers[id]
+	if !ok {
+		return
+	}
+	timer.Stop()
+	delete(u.timers, id)
+}
+
+// fire runs a scheduled trigger and arms its next run
+func (u *triggerService) fire(id string) {
+	trigger, err := u.repository.FindById(id)
+	if err != nil {
+		log.Err(err)
+		return
+	}
+	if !trigger.Scheduled() {
+		return
+	}
+	err = u.TriggerFrom(trigger.Name, domain.Source{Kind: domain.SCHEDULE, Id: trigger.Id})
+	if err != nil {
+		log.ErrF(err, "scheduled trigger '%s' failed", trigger.Name)
+	}
+	// Reload the trigger so the run time recorded by Trigger is kept
+	trigger, err = u.repository.FindById(id)
+	if err != nil {
+		log.Err(err)
+		return
+	}
+	err = u.arm(trigger, time.Now())
+	if err != nil {
+		log.ErrF(err, "trigger '%s' could not be scheduled", trigger.Name)
+	}
+}
+
+func (u *triggerService) save(trigger *domain.Trigger) error {
+	err := u.repository.Update(trigger)
+	if err != nil {
+		return err
+	}
+	return u.Emit(*trigger)
+}
+
+func isSolar(kind string) bool {
+	return kind == domain.SUNRISE || kind == domain.SUNSET
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"container/heap"
+	"math/rand"
+	"sync"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// minInterval is the shortest update interval a module may use
+const minInterval = time.Millisecond * 50
+
+// cadence is the update schedule of a single module
+type cadence struct {
+	domain.ModuleSchedule
+	id    string
+	index int
+}
+
+type cadenceQueue []*cadence
+
+func (q cadenceQueue) Len() int           { return len(q) }
+func (q cadenceQueue) Less(i, j int) bool { return q[i].Next.Before(q[j].Next) }
+
+func (q cadenceQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *cadenceQueue) Push(x any) {
+	c := x.(*cadence)
+	c.index = len(*q)
+	*q = append(*q, c)
+}
+
+func (q *cadenceQueue) Pop() any {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	old[n-1] = nil
+	c.index = -1
+	*q = old[:n-1]
+	return c
+}
+
+// scheduler owns the update cadence of every running module. Updates are started from a single loop, an update
+// that is due while the previous one is still running is skipped.
+type scheduler struct {
+	cadences map[string]*cadence
+	// running holds the modules with an update in progress. It outlives their cadences, so a module rescheduled
+	// during an update does not start another until the update finishes.
+	running map[string]bool
+	queue   cadenceQueue
+	update  func(id string) error
+	mutex   sync.Mutex
+	wake    chan struct{}
+}
+
+func newScheduler(update func(id string) error) *scheduler {
+	s := &scheduler{
+		cadences: map[string]*cadence{},
+		running:  map[string]bool{},
+		queue:    cadenceQueue{},
+		update:   update,
+		wake:     make(chan struct{}, 1),
+	}
+	go s.loop()
+	return s
+}
+
+// Add schedules a module's updates, the first update is due immediately. Modules without an interval are not
+// updated.
+func (s *scheduler) Add(module domain.Module) {
+	s.Remove(module.Id)
+	if module.Interval <= 0 {
+		return
+	}
+	interval := module.Interval
+	if interval < minInterval {
+		interval = minInterval
+	}
+	s.mutex.Lock()
+	c := &cadence{
+		id: module.Id,
+		ModuleSchedule: domain.ModuleSchedule{
+			Module:   module.Name,
+			Interval: interval,
+			Jitter:   module.Jitter,
+			Next:     time.Now(),
+			Running:  s.running[module.Id],
+		},
+	}
+	s.cadences[module.Id] = c
+	heap.Push(&s.queue, c)
+	s.mutex.Unlock()
+	s.notify()
+}
+
+// Remove cancels a module's updates. An update that is already running finishes, but no further updates start.
+func (s *scheduler) Remove(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, ok := s.cadences[id]
+	if !ok {
+		return
+	}
+	delete(s.cadences, id)
+	if c.index >= 0 {
+		heap.Remove(&s.queue, c.index)
+	}
+}
+
+// Clear cancels the updates of every module
+func (s *scheduler) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cadences = map[string]*cadence{}
+	s.queue = cadenceQueue{}
+}
+
+// Schedule reports the cadence of a module
+func (s *scheduler) Schedule(id string) (domain.ModuleSchedule, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, ok := s.cadences[id]
+	if !ok {
+		return domain.ModuleSchedule{}, false
+	}
+	return c.ModuleSchedule, true
+}
+
+// Schedules reports the cadence of every scheduled module
+func (s *scheduler) Schedules() []domain.ModuleSchedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	schedules := make([]domain.ModuleSchedule, 0, len(s.cadences))
+	for _, c := range s.cadences {
+		schedules = append(schedules, c.ModuleSchedule)
+	}
+	return schedules
+}
+
+func (s *scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *scheduler) loop() {
+	timer := time.NewTimer(time.Hour)
+	for {
+		s.mutex.Lock()
+		wait := time.Hour
+		if len(s.queue) > 0 {
+			wait = time.Until(s.queue[0].Next)
+		}
+		s.mutex.Unlock()
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+			s.dispatch()
+		case <-s.wake:
+		}
+	}
+}
+
+// dispatch starts every update that is due and schedules the next
+func (s *scheduler) dispatch() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	for len(s.queue) > 0 && !s.queue[0].Next.After(now) {
+		c := s.queue[0]
+		due := c.Next
+		if s.running[c.id] {
+			c.Skipped++
+		} else {
+			s.running[c.id] = true
+			c.Running = true
+			c.Runs++
+			c.LastRun = now
+			c.Lateness = now.Sub(due)
+			if c.Lateness > c.MaxLateness {
+				c.MaxLateness = c.Lateness
+			}
+			go s.run(c)
+		}
+		c.Next = due.Add(c.Interval + jitter(c.Jitter))
+		// Do not try to catch up on updates that were missed
+		if c.Next.Before(now) {
+			c.Next = now.Add(c.Interval)
+		}
+		heap.Fix(&s.queue, c.index)
+	}
+}
+
+func (s *scheduler) run(c *cadence) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.running, c.id)
+		c.Running = false
+		// The module may have been rescheduled while it was updating
+		if current, ok := s.cadences[c.id]; ok {
+			current.Running = false
+		}
+		s.mutex.Unlock()
+	}()
+	_ = s.update(c.id)
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
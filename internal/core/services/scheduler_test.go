@@ -0,0 +1,108 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+	"udap/internal/core/domain"
+)
+
+func testModule(interval time.Duration) domain.Module {
+	module := domain.Module{Name: "test", Interval: interval}
+	module.Id = "module"
+	return module
+}
+
+func TestScheduler_SkipsOverlappingUpdates(t *testing.T) {
+	var runs int32
+	s := newScheduler(func(id string) error {
+		atomic.AddInt32(&runs, 1)
+		time.Sleep(time.Millisecond * 180)
+		return nil
+	})
+	s.Add(testModule(minInterval))
+	time.Sleep(time.Millisecond * 300)
+	s.Remove("module")
+
+	if got := atomic.LoadInt32(&runs); got != 2 {
+		t.Errorf("expected 2 updates, got %d", got)
+	}
+}
+
+func TestScheduler_Remove(t *testing.T) {
+	var runs int32
+	s := newScheduler(func(id string) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	s.Add(testModule(minInterval))
+	time.Sleep(time.Millisecond * 20)
+	s.Remove("module")
+	time.Sleep(time.Millisecond * 150)
+
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("expected 1 update before removal, got %d", got)
+	}
+	if _, ok := s.Schedule("module"); ok {
+		t.Errorf("removed module is still scheduled")
+	}
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	s := newScheduler(func(id string) error {
+		return nil
+	})
+	s.Add(testModule(time.Millisecond))
+	defer s.Remove("module")
+	time.Sleep(time.Millisecond * 10)
+
+	schedule, ok := s.Schedule("module")
+	if !ok {
+		t.Fatalf("module is not scheduled")
+	}
+	if schedule.Interval != minInterval {
+		t.Errorf("interval should be raised to %s, got %s", minInterval, schedule.Interval)
+	}
+	if schedule.Runs != 1 {
+		t.Errorf("expected 1 run, got %d", schedule.Runs)
+	}
+}
+
+func TestScheduler_ReloadDuringUpdate(t *testing.T) {
+	var runs, running, overlapped int32
+	release := make(chan struct{})
+	s := newScheduler(func(id string) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlapped, 1)
+		}
+		if atomic.AddInt32(&runs, 1) == 1 {
+			<-release
+		}
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	defer s.Remove("module")
+	s.Add(testModule(minInterval))
+	time.Sleep(time.Millisecond * 20)
+
+	// Reloading the module reschedules it while its first update is blocked
+	s.Add(testModule(minInterval))
+	time.Sleep(time.Millisecond * 150)
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("%d updates started while the first was running, want 1", got)
+	}
+	if schedule, _ := s.Schedule("module"); !schedule.Running {
+		t.Errorf("the rescheduled module should report its update as running")
+	}
+
+	close(release)
+	time.Sleep(time.Millisecond * 150)
+	if got := atomic.LoadInt32(&runs); got < 2 {
+		t.Errorf("updates did not resume once the first finished")
+	}
+	if atomic.LoadInt32(&overlapped) != 0 {
+		t.Errorf("updates of the module overlapped")
+	}
+}
@@ -43,13 +43,37 @@ func (u *subRoutineService) RemoveMacro(id string, macroId string) error {
 	return nil
 }
 
-func (u *subRoutineService) TriggerById(id string) error {
+func (u *subRoutineService) AddScene(id string, sceneId string) error {
+	byId, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = u.repository.AddScene(byId, sceneId)
+	if err != nil {
+		return err
+	}
+	return u.Emit(*byId)
+}
+
+func (u *subRoutineService) RemoveScene(id string, sceneId string) error {
+	byId, err := u.repository.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = u.repository.RemoveScene(byId, sceneId)
+	if err != nil {
+		return err
+	}
+	return u.Emit(*byId)
+}
+
+func (u *subRoutineService) TriggerById(id string, source domain.Source) error {
 	routines, err := u.repository.FindByTriggerId(id)
 	if err != nil {
 		return err
 	}
 	for _, routine := range routines {
-		err = u.operator.Run(*routine)
+		err = u.operator.Run(*routine, source)
 		if err != nil {
 			return err
 		}
@@ -58,29 +82,32 @@ func (u *subRoutineService) TriggerById(id string) error {
 	return nil
 }
 
-func (u *subRoutineService) Run(id string) error {
+func (u *subRoutineService) Run(id string, source domain.Source) error {
 	subroutine, err := u.FindById(id)
 	if err != nil {
 		return err
 	}
-	err = u.operator.Run(*subroutine)
+	err = u.operator.Run(*subroutine, source)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u *subRoutineService) EmitAll() error {
+func (u *subRoutineService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.repository.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, user := range *all {
-		err = u.Emit(user)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *subRoutineService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -91,7 +118,11 @@ func (u *subRoutineService) FindById(id string) (*domain.SubRoutine, error) {
 }
 
 func (u *subRoutineService) Create(subRoutine *domain.SubRoutine) error {
-	err := u.repository.Create(subRoutine)
+	err := subRoutine.Validate()
+	if err != nil {
+		return err
+	}
+	err = u.repository.Create(subRoutine)
 	if err != nil {
 		return err
 	}
@@ -103,7 +134,11 @@ func (u *subRoutineService) Create(subRoutine *domain.SubRoutine) error {
 }
 
 func (u *subRoutineService) Update(subRoutine *domain.SubRoutine) error {
-	err := u.repository.Update(subRoutine)
+	err := subRoutine.Validate()
+	if err != nil {
+		return err
+	}
+	err = u.repository.Update(subRoutine)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,302 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/log"
+)
+
+const (
+	superviseInterval = time.Second * 10
+	// Consecutive missed update deadlines before a module is restarted
+	missedThreshold = 3
+	// Number of recent updates used to compute the error rate, and the rate that triggers a restart
+	errorWindow    = 10
+	errorThreshold = 0.5
+	// Restarts attempted before a module is marked as failed
+	maxRestarts    = 5
+	restartBackoff = time.Second * 2
+	maxBackoff     = time.Minute * 5
+	// A module that runs this long without a fault has its restart attempts reset
+	stableAfter   = time.Minute * 10
+	historyLength = 20
+)
+
+// supervision tracks the health of one module across restarts
+type supervision struct {
+	health     domain.ModuleHealth
+	results    []bool
+	attempts   int
+	lastFault  time.Time
+	restarting bool
+	timer      *time.Timer
+}
+
+func (s *supervision) failed() bool {
+	return s.health.Status == domain.FAILED
+}
+
+// supervisor returns the supervision of a module, creating it if needed. The caller must hold the lock.
+func (u *moduleService) supervisor(name string) *supervision {
+	s, ok := u.supervised[name]
+	if !ok {
+		s = &supervision{
+			health: domain.ModuleHealth{
+				Module:  name,
+				Status:  domain.HEALTHY,
+				History: []domain.ModuleRestart{},
+			},
+		}
+		u.supervised[name] = s
+	}
+	return s
+}
+
+// Supervise starts checking running modules for missed update deadlines
+func (u *moduleService) Supervise() {
+	go func() {
+		ticker := time.NewTicker(superviseInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			err := u.inspect()
+			if err != nil {
+				log.Err(err)
+			}
+		}
+	}()
+}
+
+func (u *moduleService) inspect() error {
+	modules, err := u.repository.FindAll()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, module := range *modules {
+		if !module.Enabled || !module.Running || module.Interval < time.Second {
+			continue
+		}
+		u.supervisedMutex.Lock()
+		s := u.supervisor(module.Name)
+		if s.restarting || s.failed() {
+			u.supervisedMutex.Unlock()
+			continue
+		}
+		// Reset the restart attempts of modules that have recovered
+		if s.attempts > 0 && now.Sub(s.lastFault) > stableAfter {
+			s.attempts = 0
+			s.health.Status = domain.HEALTHY
+		}
+		deadline := s.health.LastUpdate.Add(module.Interval*3 + superviseInterval)
+		missed := false
+		if !s.health.LastUpdate.IsZero() && now.After(deadline) {
+			s.health.Missed++
+			missed = s.health.Missed >= missedThreshold
+		}
+		u.supervisedMutex.Unlock()
+		if missed {
+			u.fault(module.Id, "missed update deadlines")
+		}
+	}
+	return nil
+}
+
+// started marks a module as having begun to run, its update deadlines are measured from this point
+func (u *moduleService) started(module *domain.Module) {
+	u.supervisedMutex.Lock()
+	defer u.supervisedMutex.Unlock()
+	s := u.supervisor(module.Name)
+	s.health.LastUpdate = time.Now()
+	s.health.Missed = 0
+}
+
+// observe records the outcome of an update, restarting the module if too many recent updates have failed
+func (u *moduleService) observe(module *domain.Module, result error) {
+	u.supervisedMutex.Lock()
+	s := u.supervisor(module.Name)
+	s.health.Updates++
+	s.health.LastUpdate = time.Now()
+	s.health.Missed = 0
+	s.results = append(s.results, result == nil)
+	if len(s.results) > errorWindow {
+		s.results = s.results[1:]
+	}
+	failures := 0
+	for _, ok := range s.results {
+		if !ok {
+			failures++
+		}
+	}
+	s.health.ErrorRate = float64(failures) / float64(len(s.results))
+	if result != nil {
+		s.health.Errors++
+		s.health.LastError = result.Error()
+		s.health.Status = domain.DEGRADED
+	} else if failures == 0 && s.health.Status == domain.DEGRADED {
+		s.health.Status = domain.HEALTHY
+	}
+	rate := s.health.ErrorRate
+	unhealthy := len(s.results) >= errorWindow/2 && rate >= errorThreshold
+	u.supervisedMutex.Unlock()
+	if unhealthy {
+		go u.fault(module.Id, fmt.Sprintf("update error rate of %.0f%%", rate*100))
+	}
+}
+
+// Fault reports that a running module has failed, such as when a goroutine started by the module panics or
+// its process exits. The module is restarted.
+func (u *moduleService) Fault(uuid string, reason string) error {
+	module, err := u.repository.FindByUUID(uuid)
+	if err != nil {
+		return err
+	}
+	u.fault(module.Id, reason)
+	return nil
+}
+
+// fault disposes of a failed module and schedules its restart, modules that keep failing are marked as failed
+func (u *moduleService) fault(id string, reason string) {
+	module, err := u.repository.FindById(id)
+	if err != nil {
+		log.Err(err)
+		return
+	}
+
+	u.supervisedMutex.Lock()
+	s := u.supervisor(module.Name)
+	if s.restarting || s.failed() {
+		u.supervisedMutex.Unlock()
+		return
+	}
+	s.lastFault = time.Now()
+	s.health.LastError = reason
+	s.attempts++
+	attempt := s.attempts
+	if attempt > maxRestarts {
+		s.health.Status = domain.FAILED
+		u.supervisedMutex.Unlock()
+		log.Event("Module '%s' failed %d times and will not be restarted: %s", module.Name, maxRestarts, reason)
+		if module.Running {
+			err = u.Dispose(module.Id)
+			if err != nil {
+				log.Err(err)
+			}
+		}
+		err = u.setState(module.Id, ERROR)
+		if err != nil {
+			log.Err(err)
+		}
+		return
+	}
+	s.restarting = true
+	s.health.Status = domain.RESTARTING
+	delay := backoff(attempt)
+	u.supervisedMutex.Unlock()
+
+	log.Event("Module '%s' failed (%s), restarting in %s", module.Name, reason, delay.String())
+	if module.Running {
+		err = u.Dispose(module.Id)
+		if err != nil {
+			log.Err(err)
+		}
+	}
+
+	u.supervisedMutex.Lock()
+	s.timer = time.AfterFunc(delay, func() {
+		u.restart(id, reason, attempt, delay)
+	})
+	u.supervisedMutex.Unlock()
+}
+
+func (u *moduleService) restart(id string, reason string, attempt int, delay time.Duration) {
+	err := u.Build(id)
+	if err == nil {
+		err = u.Load(id)
+	}
+	if err == nil {
+		err = u.Run(id)
+	}
+
+	record := domain.ModuleRestart{
+		Time:    time.Now(),
+		Reason:  reason,
+		Attempt: attempt,
+		Delay:   delay,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	module, findErr := u.repository.FindById(id)
+	if findErr != nil {
+		log.Err(findErr)
+		return
+	}
+
+	u.supervisedMutex.Lock()
+	s := u.supervisor(module.Name)
+	s.restarting = false
+	s.timer = nil
+	s.results = nil
+	s.health.Restarts++
+	s.health.History = append(s.health.History, record)
+	if len(s.health.History) > historyLength {
+		s.health.History = s.health.History[1:]
+	}
+	if err == nil && !s.failed() {
+		s.health.Status = domain.HEALTHY
+	}
+	u.supervisedMutex.Unlock()
+
+	module.Recover++
+	saveErr := u.save(module)
+	if saveErr != nil {
+		log.Err(saveErr)
+	}
+
+	if err != nil {
+		u.fault(id, fmt.Sprintf("restart failed: %s", err.Error()))
+	}
+}
+
+// settle cancels any pending restart of a module and clears its failures, used when a user takes control
+func (u *moduleService) settle(name string) {
+	u.supervisedMutex.Lock()
+	defer u.supervisedMutex.Unlock()
+	s := u.supervisor(name)
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.restarting = false
+	s.attempts = 0
+	s.results = nil
+	s.health.Missed = 0
+	s.health.Status = domain.HEALTHY
+}
+
+// Health reports the health and restart history of a module
+func (u *moduleService) Health(name string) (domain.ModuleHealth, error) {
+	_, err := u.repository.FindByName(name)
+	if err != nil {
+		return domain.ModuleHealth{}, err
+	}
+	u.supervisedMutex.Lock()
+	defer u.supervisedMutex.Unlock()
+	health := u.supervisor(name).health
+	health.History = append([]domain.ModuleRestart{}, health.History...)
+	return health, nil
+}
+
+// backoff doubles the restart delay with each attempt
+func backoff(attempt int) time.Duration {
+	delay := restartBackoff * time.Duration(math.Pow(2, float64(attempt-1)))
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+	"udap/internal/plugin"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second * 2},
+		{2, time.Second * 4},
+		{4, time.Second * 16},
+		{12, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// supervisedModules stores a single module in memory
+type supervisedModules struct {
+	ports.ModuleRepository
+	module domain.Module
+	mutex  sync.Mutex
+}
+
+func (r *supervisedModules) FindById(string) (*domain.Module, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	module := r.module
+	return &module, nil
+}
+
+func (r *supervisedModules) FindByUUID(uuid string) (*domain.Module, error) {
+	module, _ := r.FindById("")
+	if module.UUID != uuid {
+		return nil, fmt.Errorf("module not found")
+	}
+	return module, nil
+}
+
+func (r *supervisedModules) FindByName(string) (*domain.Module, error) {
+	return r.FindById("")
+}
+
+func (r *supervisedModules) FindAll() (*[]domain.Module, error) {
+	module, _ := r.FindById("")
+	return &[]domain.Module{*module}, nil
+}
+
+func (r *supervisedModules) Update(module *domain.Module) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.module = *module
+	return nil
+}
+
+// restartingOperator counts the times a module is run
+type restartingOperator struct {
+	ports.ModuleOperator
+	runs chan string
+}
+
+func (o *restartingOperator) Build(string, string, string) error {
+	return nil
+}
+
+func (o *restartingOperator) Load(string, string, string) (domain.ModuleConfig, error) {
+	return domain.ModuleConfig{Variables: "[]"}, nil
+}
+
+func (o *restartingOperator) Run(uuid string) error {
+	o.runs <- uuid
+	return nil
+}
+
+func (o *restartingOperator) Dispose(string, string) error {
+	return nil
+}
+
+func TestModule_GoPanicRestarts(t *testing.T) {
+	repository := &supervisedModules{}
+	repository.module = domain.Module{Name: "panicky", UUID: "first", Enabled: true, Running: true}
+	repository.module.Id = "module"
+	operator := &restartingOperator{runs: make(chan string, 1)}
+	service := NewModuleService(repository, operator)
+
+	module := plugin.Module{UUID: "first", Controller: &controller.Controller{Modules: service}}
+	module.Go(func() {
+		panic("listener failed")
+	})
+
+	select {
+	case uuid := <-operator.runs:
+		if uuid == "first" {
+			t.Errorf("the module was restarted without being rebuilt")
+		}
+	case <-time.After(backoff(1) + time.Second*5):
+		t.Fatalf("the module was not restarted")
+	}
+	health, err := service.Health("panicky")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.LastError != "panic: listener failed" {
+		t.Errorf("the fault was recorded as '%s'", health.LastError)
+	}
+}
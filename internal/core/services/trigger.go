@@ -3,19 +3,28 @@
 package services
 
 import (
+	"sync"
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/core/generic"
 	"udap/internal/core/ports"
 )
 
-func NewTriggerService(repository ports.TriggerRepository, operator ports.TriggerOperator) ports.TriggerService {
-	return &triggerService{repository: repository, operator: operator}
+func NewTriggerService(repository ports.TriggerRepository, operator ports.TriggerOperator, location domain.Location) ports.TriggerService {
+	return &triggerService{
+		repository: repository,
+		operator:   operator,
+		location:   location,
+		timers:     map[string]*time.Timer{},
+	}
 }
 
 type triggerService struct {
 	repository ports.TriggerRepository
 	operator   ports.TriggerOperator
+	location   domain.Location
+	timers     map[string]*time.Timer
+	mutex      sync.Mutex
 	generic.Watchable[domain.Trigger]
 }
 
@@ -27,12 +36,17 @@ func (u *triggerService) Register(trigger *domain.Trigger) error {
 	return nil
 }
 
+// Trigger invokes a trigger on behalf of a module
 func (u *triggerService) Trigger(name string) error {
+	return u.TriggerFrom(name, domain.Source{Kind: domain.MODULE})
+}
+
+func (u *triggerService) TriggerFrom(name string, source domain.Source) error {
 	trigger, err := u.repository.FindByName(name)
 	if err != nil {
 		return err
 	}
-	err = u.operator.Run(*trigger)
+	err = u.operator.Run(*trigger, source)
 	if err != nil {
 		return err
 	}
@@ -53,7 +67,7 @@ func (u *triggerService) TriggerCustom(name string, key string, value string) er
 	if err != nil {
 		return err
 	}
-	err = u.operator.RunCustom(*trigger, key, value)
+	err = u.operator.RunCustom(*trigger, key, value, domain.Source{Kind: domain.MODULE})
 	if err != nil {
 		return err
 	}
@@ -69,28 +83,46 @@ func (u *triggerService) TriggerCustom(name string, key string, value string) er
 	return nil
 }
 
-func (u *triggerService) EmitAll() error {
+func (u *triggerService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.repository.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, user := range *all {
-		err = u.Emit(user)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *triggerService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
 // Repository Mapping
 
+func (u *triggerService) FindAll() (*[]domain.Trigger, error) {
+	return u.repository.FindAll()
+}
+
 func (u *triggerService) FindById(id string) (*domain.Trigger, error) {
 	return u.repository.FindById(id)
 }
 
 func (u *triggerService) Create(trigger *domain.Trigger) error {
-	return u.repository.Create(trigger)
+	err := trigger.Schedule.Validate()
+	if err != nil {
+		return err
+	}
+	err = u.repository.Create(trigger)
+	if err != nil {
+		return err
+	}
+	if trigger.Scheduled() {
+		return u.arm(trigger, time.Now())
+	}
+	return nil
 }
 
 func (u *triggerService) Update(trigger *domain.Trigger) error {
@@ -98,5 +130,6 @@ func (u *triggerService) Update(trigger *domain.Trigger) error {
 }
 
 func (u *triggerService) Delete(trigger *domain.Trigger) error {
+	u.disarm(trigger.Id)
 	return u.repository.Delete(trigger)
 }
@@ -19,17 +19,20 @@ type userService struct {
 	generic.Watchable[domain.User]
 }
 
-func (u *userService) EmitAll() error {
+func (u *userService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, user := range *all {
-		err = u.Emit(user)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *userService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
@@ -18,17 +18,20 @@ type zoneService struct {
 	generic.Watchable[domain.Zone]
 }
 
-func (u *zoneService) EmitAll() error {
+func (u *zoneService) Snapshot() ([]domain.Mutation, error) {
 	all, err := u.FindAll()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	for _, zone := range *all {
-		err = u.Emit(zone)
-		if err != nil {
-			return err
-		}
+	return u.Mutations(*all), nil
+}
+
+func (u *zoneService) EmitAll() error {
+	snapshot, err := u.Snapshot()
+	if err != nil {
+		return err
 	}
+	u.Publish(snapshot)
 	return nil
 }
 
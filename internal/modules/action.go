@@ -0,0 +1,22 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package modules
+
+import (
+	"udap/internal/core/operators"
+	"udap/internal/core/repository"
+	"udap/internal/core/services"
+	"udap/internal/port/routes"
+	"udap/internal/srv"
+)
+
+func NewAction(sys srv.System) {
+	// Initialize service
+	service := services.NewActionService(
+		repository.NewActionRepository(sys.DB()),
+		operators.NewActionOperator(sys.Ctrl()))
+	sys.Ctrl().Actions = service
+	// Enroll routes
+	sys.WithWatch(service)
+	sys.WithRoute(routes.NewActionRouter(service))
+}
@@ -17,6 +17,6 @@ func NewAttribute(sys srv.System) {
 		operators.NewAttributeOperator())
 	// Enroll routes
 	sys.WithWatch(service)
-	sys.WithRoute(routes.NewAttributeRouter(service))
+	sys.WithRoute(routes.NewAttributeRouter(service, sys.Ctrl().Entities))
 	sys.Ctrl().Attributes = service
 }
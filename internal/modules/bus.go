@@ -0,0 +1,12 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package modules
+
+import (
+	"udap/internal/port/routes"
+	"udap/internal/srv"
+)
+
+func NewBus(sys srv.System) {
+	sys.WithRoute(routes.NewBusRouter(sys.Ctrl().Bus))
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package modules
+
+import (
+	"udap/internal/core/bus"
+	"udap/internal/core/operators"
+	"udap/internal/core/repository"
+	"udap/internal/core/services"
+	"udap/internal/log"
+	"udap/internal/port/routes"
+	"udap/internal/srv"
+)
+
+func NewHold(sys srv.System) {
+	// Initialize service
+	service := services.NewHoldService(
+		repository.NewHoldRepository(sys.DB()),
+		operators.NewHoldOperator(sys.Ctrl()))
+	sys.Ctrl().Holds = service
+	// Cancel holds when their attributes are changed manually. Every change is queued so a manual change is not
+	// hidden behind a later request, without holding up the attributes' publishers while holds are looked up.
+	mutations := sys.Ctrl().Bus.Subscribe("holds", bus.Options{
+		Topics: []bus.Topic{bus.ATTRIBUTE},
+		Policy: bus.UNBOUNDED,
+	})
+	go func() {
+		for mutation := range mutations.C() {
+			err := service.HandleMutation(mutation)
+			if err != nil {
+				log.Err(err)
+			}
+		}
+	}()
+	// Enroll routes
+	sys.WithWatch(service)
+	sys.WithRoute(routes.NewHoldRouter(service))
+}
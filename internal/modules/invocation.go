@@ -0,0 +1,20 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package modules
+
+import (
+	"udap/internal/core/repository"
+	"udap/internal/core/services"
+	"udap/internal/port/routes"
+	"udap/internal/srv"
+)
+
+func NewInvocation(sys srv.System) {
+	// Initialize service
+	service := services.NewInvocationService(
+		repository.NewInvocationRepository(sys.DB()))
+	sys.Ctrl().Invocations = service
+	// Enroll routes
+	sys.WithWatch(service)
+	sys.WithRoute(routes.NewInvocationRouter(service))
+}
@@ -3,14 +3,49 @@
 package modules
 
 import (
+	"os"
+	"time"
+	"udap/internal/core/repository"
 	"udap/internal/core/services"
+	"udap/internal/log"
+	"udap/internal/port/routes"
 	"udap/internal/srv"
 )
 
+// defaultLogRetention is used when the logRetention env is not set
+const defaultLogRetention = time.Hour * 24 * 14
+
 func NewLog(sys srv.System) {
 	// Initialize service
-	service := services.NewLogService()
+	service := services.NewLogService(
+		repository.NewLogsRepository(sys.DB()),
+		retention())
 	sys.Ctrl().Logs = service
+	// Prune old logs periodically
+	go func() {
+		for {
+			err := service.Prune()
+			if err != nil {
+				log.Err(err)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
 	// Enroll routes
 	sys.WithWatch(service)
+	sys.WithRoute(routes.NewLogRouter(service))
+}
+
+// retention reads how long logs are kept from the environment, such as logRetention=336h
+func retention() time.Duration {
+	value, ok := os.LookupEnv("logRetention")
+	if !ok {
+		return defaultLogRetention
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Event("Env logRetention '%s' is invalid, keeping logs for %s.", value, defaultLogRetention)
+		return defaultLogRetention
+	}
+	return duration
 }
@@ -49,6 +49,8 @@ func NewModule(sys srv.System) {
 		if err != nil {
 			return
 		}
+		// Restart modules that fail
+		service.Supervise()
 	})
 
 }
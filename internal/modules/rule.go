@@ -0,0 +1,39 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package modules
+
+import (
+	"udap/internal/core/bus"
+	"udap/internal/core/operators"
+	"udap/internal/core/repository"
+	"udap/internal/core/services"
+	"udap/internal/log"
+	"udap/internal/port/routes"
+	"udap/internal/srv"
+)
+
+func NewRule(sys srv.System) {
+	// Initialize service
+	service := services.NewRuleService(
+		repository.NewRuleRepository(sys.DB()),
+		repository.NewAttributeRepository(sys.DB(), sys.Store()),
+		operators.NewRuleOperator(sys.Ctrl()))
+	sys.Ctrl().Rules = service
+	// Evaluate rules from the attribute mutation stream. Rules match the change from one value to the next, so
+	// every change is queued, without holding up the attributes' publishers while rules are looked up.
+	mutations := sys.Ctrl().Bus.Subscribe("rules", bus.Options{
+		Topics: []bus.Topic{bus.ATTRIBUTE},
+		Policy: bus.UNBOUNDED,
+	})
+	go func() {
+		for mutation := range mutations.C() {
+			err := service.HandleMutation(mutation)
+			if err != nil {
+				log.Err(err)
+			}
+		}
+	}()
+	// Enroll routes
+	sys.WithWatch(service)
+	sys.WithRoute(routes.NewRuleRouter(service))
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package modules
+
+import (
+	"udap/internal/core/operators"
+	"udap/internal/core/repository"
+	"udap/internal/core/services"
+	"udap/internal/port/routes"
+	"udap/internal/srv"
+)
+
+func NewScene(sys srv.System) {
+	// Initialize service
+	service := services.NewSceneService(
+		repository.NewSceneRepository(sys.DB()),
+		operators.NewSceneOperator(sys.Ctrl()))
+	sys.Ctrl().Scenes = service
+	// Enroll routes
+	sys.WithWatch(service)
+	sys.WithRoute(routes.NewSceneRouter(service))
+}
@@ -3,9 +3,13 @@
 package modules
 
 import (
+	"os"
+	"strconv"
+	"udap/internal/core/domain"
 	"udap/internal/core/operators"
 	"udap/internal/core/repository"
 	"udap/internal/core/services"
+	"udap/internal/log"
 	"udap/internal/port/routes"
 	"udap/internal/srv"
 )
@@ -14,9 +18,28 @@ func NewTrigger(sys srv.System) {
 	// Initialize service
 	service := services.NewTriggerService(
 		repository.NewTriggerRepository(sys.DB()),
-		operators.NewTriggerOperator(sys.Ctrl()))
+		operators.NewTriggerOperator(sys.Ctrl()),
+		location())
 	// Enroll routes
 	sys.Ctrl().Triggers = service
 	sys.WithWatch(service)
 	sys.WithRoute(routes.NewTriggerRouter(service))
 }
+
+// location reads the coordinates used for sunrise and sunset schedules from the environment
+func location() domain.Location {
+	lat, err := strconv.ParseFloat(os.Getenv("latitude"), 64)
+	if err != nil {
+		log.Event("Env latitude not set, solar schedules are disabled.")
+		return domain.Location{}
+	}
+	lon, err := strconv.ParseFloat(os.Getenv("longitude"), 64)
+	if err != nil {
+		log.Event("Env longitude not set, solar schedules are disabled.")
+		return domain.Location{}
+	}
+	return domain.Location{
+		Latitude:  lat,
+		Longitude: lon,
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 	"udap/internal/controller"
 	"udap/internal/core"
+	"udap/internal/core/bus"
 	"udap/internal/core/device"
 	"udap/internal/core/domain"
 	"udap/internal/log"
@@ -31,7 +32,8 @@ type orchestrator struct {
 	done       chan bool
 	ready      bool
 	sys        srv.System
-	mutations  chan domain.Mutation
+	events     *bus.Bus
+	mutations  *bus.Subscription
 }
 
 type Orchestrator interface {
@@ -55,7 +57,7 @@ func (o *orchestrator) Terminate() {
 		}
 	}()
 
-	close(o.mutations)
+	o.mutations.Close()
 
 	fmt.Printf("\nThreads at exit: %d\n", runtime.NumGoroutine())
 
@@ -73,6 +75,9 @@ func NewOrchestrator() (Orchestrator, error) {
 
 	str := store.NewStore()
 
+	// Initialize the bus services publish their changes to
+	events := bus.New()
+
 	// Initialize Orchestrator
 	return &orchestrator{
 		db:         db,
@@ -81,7 +86,12 @@ func NewOrchestrator() (Orchestrator, error) {
 		done:       make(chan bool),
 		controller: nil,
 		maxTick:    time.Second * 1,
-		mutations:  make(chan domain.Mutation, 512),
+		events:     events,
+		// Endpoints and modules only need the latest state of each element, so mutations are coalesced
+		mutations: events.Subscribe("orchestrator", bus.Options{
+			Size:   1024,
+			Policy: bus.COALESCE,
+		}),
 	}, nil
 }
 
@@ -111,7 +121,7 @@ func (o *orchestrator) Start() error {
 		return err
 	}
 
-	o.controller, err = controller.NewController(o.mutations)
+	o.controller, err = controller.NewController(o.events)
 	if err != nil {
 		return err
 	}
@@ -121,7 +131,7 @@ func (o *orchestrator) Start() error {
 	o.sys = srv.NewRtx(&o.server, o.controller, o.db, o.store)
 
 	o.sys.UseModules(
-		modules.NewModule, modules.NewTrace)
+		modules.NewModule, modules.NewTrace, modules.NewBus)
 
 	o.sys.UseModules(
 		modules.NewEntity,
@@ -133,7 +143,9 @@ func (o *orchestrator) Start() error {
 
 	o.sys.UseModules(
 		modules.NewMacro,
+		modules.NewScene,
 		modules.NewSubroutine,
+		modules.NewInvocation,
 		modules.NewTrigger,
 		modules.NewUser,
 		modules.NewNetwork,
@@ -144,9 +156,21 @@ func (o *orchestrator) Start() error {
 
 	o.sys.UseModules(modules.NewAction)
 
+	o.sys.UseModules(modules.NewRule, modules.NewHold)
+
 	o.sys.Loaded()
 	o.ready = true
 
+	err = o.controller.Triggers.StartSchedules()
+	if err != nil {
+		return err
+	}
+
+	err = o.controller.Holds.Resume()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -165,24 +189,34 @@ func (o *orchestrator) broadcastTimings() error {
 	timings := pulse.Timings.Timings()
 
 	for s, proc := range timings {
-		o.mutations <- domain.Mutation{
+		o.controller.Bus.Publish(domain.Mutation{
 			Status:    "update",
 			Operation: "timing",
 			Body:      proc,
 			Id:        s,
-		}
+		})
 	}
 	return nil
 }
 
 func (o *orchestrator) handleMutations() error {
-	for response := range o.mutations {
-		// o.modules.HandleEmits(response)
+	var dropped uint64
+	for response := range o.mutations.C() {
 		for !o.ready {
 			time.Sleep(time.Millisecond * 250)
 		}
+		// Mutations dropped by a full queue never reach endpoints, so they resubscribe instead of resuming
+		if stats := o.mutations.Stats(); stats.Dropped != dropped {
+			dropped = stats.Dropped
+			o.controller.Endpoints.Resync()
+		}
+		// Deliver the mutation to subscribed modules
+		err := o.controller.Modules.HandleEmits(response)
+		if err != nil {
+			log.Err(err)
+		}
 
-		err := o.controller.Endpoints.SendAll(response.Id, response.Operation, response.Body)
+		err = o.controller.Endpoints.SendAll(response.Id, response.Operation, response.Body)
 		if err != nil {
 			log.Err(err)
 			continue
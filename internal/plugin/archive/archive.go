@@ -0,0 +1,170 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package archive reads and writes module packages. A package is a gzipped tar of the module's directory with a
+// manifest.json describing the module at its root.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"udap/internal/core/domain"
+)
+
+// MANIFEST is the name of the manifest file at the root of a package
+const MANIFEST = "manifest.json"
+
+// maxSize is the largest total size of the files a package may unpack
+const maxSize = 256 << 20
+
+// ReadManifest reads and validates the manifest of a module directory, the directory must contain the module's
+// source file
+func ReadManifest(dir string) (domain.Manifest, error) {
+	manifest := domain.Manifest{}
+	data, err := os.ReadFile(filepath.Join(dir, MANIFEST))
+	if err != nil {
+		return manifest, err
+	}
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("manifest is invalid: %s", err.Error())
+	}
+	err = manifest.Validate()
+	if err != nil {
+		return manifest, err
+	}
+	_, err = os.Stat(filepath.Join(dir, manifest.Name+".go"))
+	if err != nil {
+		return manifest, fmt.Errorf("module '%s' source file '%s.go' not found", manifest.Name, manifest.Name)
+	}
+	return manifest, nil
+}
+
+// Pack writes the module directory as a package, compiled binaries are left out
+func Pack(dir string, w io.Writer) (domain.Manifest, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return manifest, err
+	}
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if name == "." || strings.HasSuffix(name, ".so") || strings.HasSuffix(name, ".bin") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return manifest, err
+	}
+	err = tw.Close()
+	if err != nil {
+		return manifest, err
+	}
+	return manifest, zw.Close()
+}
+
+// Unpack extracts a package into an empty directory and returns its manifest. Packages with entries that would
+// be written outside the directory, or that are not regular files or directories, are refused.
+func Unpack(r io.Reader, dir string) (domain.Manifest, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return domain.Manifest{}, fmt.Errorf("package is not a gzipped archive")
+	}
+	defer zr.Close()
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return domain.Manifest{}, err
+	}
+	tr := tar.NewReader(zr)
+	var total int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return domain.Manifest{}, err
+		}
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return domain.Manifest{}, fmt.Errorf("package entry '%s' is outside the module", header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+			if err != nil {
+				return domain.Manifest{}, err
+			}
+		case tar.TypeReg:
+			total += header.Size
+			if total > maxSize {
+				return domain.Manifest{}, fmt.Errorf("package exceeds %d bytes", maxSize)
+			}
+			err = write(target, tr, header.Size)
+			if err != nil {
+				return domain.Manifest{}, err
+			}
+		default:
+			return domain.Manifest{}, fmt.Errorf("package entry '%s' is not a regular file", header.Name)
+		}
+	}
+	return ReadManifest(dir)
+}
+
+// write copies a single file out of the package
+func write(target string, r io.Reader, size int64) error {
+	err := os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyN(f, r, size)
+	return err
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const manifest = `{"name": "lamp", "version": "1.2.0", "api": 1}`
+
+func TestPackUnpack(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		MANIFEST:          manifest,
+		"lamp.go":         "package main",
+		"models/lamp.dat": "weights",
+		"lamp-1234.so":    "binary",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := bytes.Buffer{}
+	packed, err := Pack(dir, &buf)
+	if err != nil {
+		t.Fatalf("Pack() = %v", err)
+	}
+	out := filepath.Join(t.TempDir(), "lamp")
+	unpacked, err := Unpack(&buf, out)
+	if err != nil {
+		t.Fatalf("Unpack() = %v", err)
+	}
+	if unpacked.Name != packed.Name || unpacked.Version != "1.2.0" {
+		t.Errorf("unpacked manifest %v, want %v", unpacked, packed)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "models", "lamp.dat"))
+	if err != nil || string(data) != "weights" {
+		t.Errorf("nested files should be unpacked")
+	}
+	if _, err = os.Stat(filepath.Join(out, "lamp-1234.so")); err == nil {
+		t.Errorf("binaries should not be packed")
+	}
+}
+
+func TestUnpackOutside(t *testing.T) {
+	buf := bytes.Buffer{}
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	content := []byte("escape")
+	_ = tw.WriteHeader(&tar.Header{Name: "../escape.go", Mode: 0644, Size: int64(len(content)),
+		Typeflag: tar.TypeReg})
+	_, _ = tw.Write(content)
+	_ = tw.Close()
+	_ = zw.Close()
+	dir := t.TempDir()
+	_, err := Unpack(&buf, filepath.Join(dir, "lamp"))
+	if err == nil {
+		t.Errorf("entries outside the module should be refused")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "escape.go")); err == nil {
+		t.Errorf("entry was written outside the module")
+	}
+}
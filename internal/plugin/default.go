@@ -11,6 +11,7 @@ import (
 	"udap/internal/controller"
 	"udap/internal/core/domain"
 	"udap/internal/log"
+	"udap/internal/plugin/web"
 	"udap/internal/pulse"
 )
 
@@ -19,26 +20,57 @@ type Config struct {
 	Type        string        `json:"type"` // Module, Daemon, etc.
 	Description string        `json:"description"`
 	Interval    time.Duration `json:"interval"`
+	Jitter      time.Duration `json:"jitter"` // Random delay added to each interval
 	Version     string        `json:"version"`
 	Author      string        `json:"author"`
 	Variables   []Variable    `json:"variables"`
+	Grants      []Grant       `json:"grants"` // Access beyond the module's own entities, enforced by the host
 }
 
-type Variable struct {
-	Name        string `json:"name"`
-	Default     string `json:"default"`
-	Description string `json:"description"`
-}
+// Variable declares a configuration value, see domain.Variable for the types and constraints available
+type Variable = domain.Variable
+
+// Grant declares a capability the module requires, see domain.Grant
+type Grant = domain.Grant
 
 type Module struct {
 	Config
 	LastUpdate time.Time
 	Frequency  time.Duration
 	UUID       string
+	clock      Clock
+	web        *web.Client
 
 	*controller.Controller
 }
 
+// Clock tells the time, tests replace the module's clock to control when it is ready to update
+type Clock interface {
+	Now() time.Time
+}
+
+// UseClock replaces the clock the module uses to decide when it is ready
+func (m *Module) UseClock(clock Clock) {
+	m.clock = clock
+}
+
+// Now reports the current time according to the module's clock
+func (m *Module) Now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock.Now()
+}
+
+// Web provides the module's http client, which retries failed requests and respects the rate limits of the
+// hosts it calls
+func (m *Module) Web() *web.Client {
+	if m.web == nil {
+		m.web = web.New(m.Config.Name)
+	}
+	return m.web
+}
+
 type WebRequest struct {
 	request  *http.Request
 	client   *http.Client
@@ -63,13 +95,16 @@ func (w *WebRequest) WithTimeout(timeout time.Duration) {
 	w.timeout = timeout
 }
 
+// Execute sends the request and decodes the response into output, responses with a non-2xx status return a
+// *web.StatusError
 func (w *WebRequest) Execute(output any) error {
 
-	addr := fmt.Sprintf("module.%s.webrequest.%s", w.moduleId, w.request.RemoteAddr)
+	addr := fmt.Sprintf("module.%s.webrequest.%s", w.moduleId, w.request.URL.Host)
 
 	pulse.Begin(addr)
 	defer pulse.End(addr)
 
+	w.client.Timeout = w.timeout
 	response, err := w.client.Do(w.request)
 	if err != nil {
 		return err
@@ -87,6 +122,10 @@ func (w *WebRequest) Execute(output any) error {
 	}
 
 	w.client.CloseIdleConnections()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &web.StatusError{Method: w.request.Method, URL: w.request.URL.String(), Status: response.StatusCode,
+			Body: buf.Bytes()}
+	}
 	if output != nil {
 		err = json.Unmarshal(buf.Bytes(), output)
 		if err != nil {
@@ -145,95 +184,64 @@ func (m *Module) RegisterEntity(name string, entityType string) (string, error)
 	return product.Id, nil
 }
 
-// LogF is called once at the launch of the module
-func (m *Module) LogF(format string, args ...any) {
+// Record writes a structured log entry to the system log and the log store. The entity and correlation id
+// may be left empty, logs sharing a correlation id can be queried together.
+func (m *Module) Record(level string, entity string, correlation string, format string, args ...any) {
 	out := domain.Log{
-		Group:   "module",
-		Level:   "info",
-		Event:   m.Name,
-		Time:    time.Now(),
-		Message: fmt.Sprintf(format, args...),
+		Group:       "module",
+		Level:       level,
+		Event:       m.Name,
+		Module:      m.Config.Name,
+		Entity:      entity,
+		Correlation: correlation,
+		Time:        m.Now(),
+		Message:     fmt.Sprintf(format, args...),
 	}
+	// Log the event to the program log
 	log.Event("%s::%s %s", out.Group, out.Event, out.Message)
+	// Create a log entry in the database
 	err := m.Logs.Create(&out)
 	if err != nil {
+		// Log the error to console
+		log.Err(err)
 		return
 	}
 }
 
+// LogF is called once at the launch of the module
+func (m *Module) LogF(format string, args ...any) {
+	m.Record(domain.INFO, "", "", format, args...)
+}
+
 // WarnF prints a logf message to the system and UDAP network
 func (m *Module) WarnF(format string, args ...any) {
-	// Generate the structure template for a log message
-	out := domain.Log{
-		Group:   "module",
-		Level:   "warn",
-		Event:   m.Name,
-		Time:    time.Now(),
-		Message: fmt.Sprintf(format, args...),
-	}
-	// Log the message to the system log
-	log.Event("%s::%s %s", out.Group, out.Event, out.Message)
-	// Attempt to log with the database logs
-	err := m.Logs.Create(&out)
-	if err != nil {
-		// Forward error to log
-		log.Err(err)
-		return
-	}
+	m.Record(domain.WARN, "", "", format, args...)
 }
 
 // ErrF generates an error logf error message
 func (m *Module) ErrF(format string, args ...any) {
-	// Define the log struct
-	out := domain.Log{
-		Group:   "module",
-		Level:   "error",
-		Event:   m.Name,
-		Time:    time.Now(),
-		Message: fmt.Sprintf(format, args...),
-	}
-	// Log the event to the program log
-	log.Event("%s::%s %s", out.Group, out.Event, out.Message)
-	// Create a log entry in the database
-	err := m.Logs.Create(&out)
-	if err != nil {
-		// Log the error to console
-		log.Err(err)
-		return
-	}
+	m.Record(domain.ERROR, "", "", format, args...)
 }
 
 func (m *Module) Err(err error) {
 	if err == nil {
 		return
 	}
-	out := domain.Log{
-		Group:   "module",
-		Level:   "error",
-		Event:   m.Name,
-		Time:    time.Now(),
-		Message: fmt.Sprintf("Error: %s", err.Error()),
-	}
-	log.Event("%s::%s %s", out.Group, out.Event, out.Message)
-	err = nil
-	err = m.Logs.Create(&out)
-	if err != nil {
-		return
-	}
+	m.Record(domain.ERROR, "", "", "Error: %s", err.Error())
 }
 
 // UpdateInterval is called once at the launch of the module
 func (m *Module) UpdateInterval(frequency time.Duration) error {
 	m.Frequency = time.Millisecond * frequency
-	m.LastUpdate = time.Now().Add(-m.Frequency)
+	m.LastUpdate = m.Now().Add(-m.Frequency)
 	m.Config.Interval = time.Millisecond * frequency
 	return nil
 }
 
 // Ready is used to determine whether the module should update
 func (m *Module) Ready() bool {
-	if time.Since(m.LastUpdate) >= m.Frequency {
-		m.LastUpdate = time.Now()
+	if m.Now().Sub(m.LastUpdate) >= m.Frequency {
+		m.LastUpdate = m.Now()
 		return true
 	}
 	return false
@@ -241,16 +249,48 @@ func (m *Module) Ready() bool {
 
 // Connect is called once at the launch of the module
 func (m *Module) Connect(ctrl *controller.Controller, uuid string) error {
-	m.LastUpdate = time.Now()
+	m.LastUpdate = m.Now()
 	m.Controller = ctrl
 	m.UUID = uuid
+	// Create the http client before the module's goroutines can use it
+	m.Web()
 	return nil
 }
 
-func (m *Module) OnEmit() error {
+// ConfigChanged is called when one of the module's variables is changed through the api, modules that can apply
+// a new value without reloading override it
+func (m *Module) ConfigChanged(key string, value string) error {
 	return nil
 }
 
+// OnEmit is called with each mutation matching the module's subscriptions, modules that subscribe override it
+func (m *Module) OnEmit(mutation domain.Mutation) error {
+	return nil
+}
+
+// Subscribe requests that mutations matching the filter be delivered to the module's OnEmit function. Mutations
+// are queued for the module, and dropped if the module falls too far behind.
+func (m *Module) Subscribe(filter domain.MutationFilter) error {
+	return m.Modules.Subscribe(m.UUID, filter)
+}
+
+// Go runs a function in a new goroutine. A panic in the function is reported to the module supervisor, which
+// restarts the module, instead of stopping the system.
+func (m *Module) Go(fn func()) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Recovered("Module '%s' goroutine panicked: %v", m.Config.Name, r)
+				err := m.Modules.Fault(m.UUID, fmt.Sprintf("panic: %v", r))
+				if err != nil {
+					log.Err(err)
+				}
+			}
+		}()
+		fn()
+	}()
+}
+
 // InitConfig attempts to initialize a persistent storage key value pair
 func (m *Module) InitConfig(key string, value string) error {
 	return m.Modules.InitConfig(m.UUID, key, value)
@@ -0,0 +1,33 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package moduletest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a clock that only moves when it is advanced
+type Clock struct {
+	now   time.Time
+	mutex sync.RWMutex
+}
+
+func NewClock(start time.Time) *Clock {
+	return &Clock{
+		now: start,
+	}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now
+}
+
+// Advance moves the clock forward
+func (c *Clock) Advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package moduletest runs modules against in-memory controller services, so modules can be tested without a
+// database or the rest of the system:
+//
+//	func TestModule(t *testing.T) {
+//		h := moduletest.New(t, "hs100", &Module)
+//		h.Start()
+//		h.Request("kitchen", "on", "true")
+//		h.Await(func() bool { return h.Attribute("kitchen", "on").Value == "true" })
+//	}
+package moduletest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/plugin"
+	"udap/internal/plugin/scope"
+	"udap/internal/plugin/web"
+)
+
+// uuid is the session id given to modules under test
+const uuid = "00000000-0000-0000-0000-000000000001"
+
+// awaitTimeout is how long Await waits for a condition to be met
+const awaitTimeout = time.Second * 2
+
+// Harness drives a single module through its lifecycle. The module reaches the in-memory services through the
+// same scope it would be given when loaded by the system.
+type Harness struct {
+	t      testing.TB
+	name   string
+	module plugin.ModuleInterface
+	scope  *scope.Scope
+	Clock  *Clock
+	Memory *Memory
+	Config plugin.Config
+}
+
+// New prepares a harness for a module, the clock starts at the current time
+func New(t testing.TB, name string, module plugin.ModuleInterface) *Harness {
+	clock := NewClock(time.Now())
+	memory := NewMemory(clock)
+	h := &Harness{
+		t:      t,
+		name:   name,
+		module: module,
+		Clock:  clock,
+		Memory: memory,
+	}
+	h.scope = scope.New(memory.Controller(), name, uuid)
+	memory.modules.configure = module.ConfigChanged
+	if m, ok := module.(interface{ UseClock(plugin.Clock) }); ok {
+		m.UseClock(clock)
+	}
+	return h
+}
+
+// Setup connects the module and runs its setup, the declared variables are initialized with their defaults
+func (h *Harness) Setup() error {
+	err := h.module.Connect(h.scope.Controller(), uuid)
+	if err != nil {
+		return err
+	}
+	config, err := h.module.Setup()
+	if err != nil {
+		return err
+	}
+	h.Config = config
+	h.scope.Grant(config.Grants)
+	variables, err := json.Marshal(config.Variables)
+	if err != nil {
+		return err
+	}
+	values := map[string]string{}
+	for _, variable := range config.Variables {
+		values[variable.Name] = variable.Default
+	}
+	initial, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	module := domain.Module{
+		Name:      h.name,
+		UUID:      uuid,
+		Type:      config.Type,
+		Version:   config.Version,
+		Interval:  config.Interval,
+		Variables: string(variables),
+		Config:    string(initial),
+		Enabled:   true,
+		Runtime:   domain.PLUGIN,
+	}
+	return h.Memory.modules.Create(&module)
+}
+
+// Run runs the module
+func (h *Harness) Run() error {
+	return h.module.Run()
+}
+
+// Update runs a single update of the module
+func (h *Harness) Update() error {
+	return h.module.Update()
+}
+
+// Dispose halts the module
+func (h *Harness) Dispose() error {
+	return h.module.Dispose()
+}
+
+// Start sets up and runs the module, failing the test if either fails. The module is disposed when the test
+// completes.
+func (h *Harness) Start() {
+	h.t.Helper()
+	err := h.Setup()
+	if err != nil {
+		h.t.Fatalf("module '%s' setup failed: %s", h.name, err.Error())
+	}
+	err = h.Run()
+	if err != nil {
+		h.t.Fatalf("module '%s' run failed: %s", h.name, err.Error())
+	}
+	h.t.Cleanup(func() {
+		_ = h.Dispose()
+	})
+}
+
+// Replay answers the module's http requests from the fixtures in a directory, requests without a fixture fail
+func (h *Harness) Replay(dir string) {
+	h.web().Replay(dir)
+}
+
+// Record writes the responses to the module's http requests to fixtures in a directory, so the test can later
+// be run with Replay
+func (h *Harness) Record(dir string) {
+	h.web().Record(dir)
+}
+
+// web provides the module's http client, failing the test if the module does not use the shared client
+func (h *Harness) web() *web.Client {
+	h.t.Helper()
+	m, ok := h.module.(interface{ Web() *web.Client })
+	if !ok {
+		h.t.Fatalf("module '%s' does not use the shared http client", h.name)
+	}
+	return m.Web()
+}
+
+// Advance moves the module's clock forward. Only Ready and the timestamps the module reads through Now follow the
+// clock, timers and tickers the module starts itself still run on the real clock.
+func (h *Harness) Advance(duration time.Duration) {
+	h.Clock.Advance(duration)
+}
+
+// Configure changes one of the module's variables as it would be changed through the api
+func (h *Harness) Configure(key string, value string) error {
+	return h.Memory.modules.Configure(h.name, key, value)
+}
+
+// Request sends an attribute request to the module, failing the test if the module does not accept it
+func (h *Harness) Request(entity string, key string, value string) {
+	h.t.Helper()
+	e, err := h.Memory.entities.FindByName(entity)
+	if err != nil {
+		h.t.Fatalf("entity '%s' was not registered", entity)
+	}
+	err = h.Memory.attributes.Request(e.Id, key, value)
+	if err != nil {
+		h.t.Fatalf("request for '%s' on '%s' failed: %s", key, entity, err.Error())
+	}
+}
+
+// Emit delivers a mutation to the module if it matches one of the module's subscriptions
+func (h *Harness) Emit(mutation domain.Mutation) error {
+	h.Memory.mutex.Lock()
+	filters := h.Memory.filters
+	h.Memory.mutex.Unlock()
+	entity, module, key := "", "", ""
+	switch body := mutation.Body.(type) {
+	case domain.Attribute:
+		entity, key = body.Entity, body.Key
+		if e, err := h.Memory.entities.FindById(body.Entity); err == nil {
+			module = e.Module
+		}
+	case domain.Entity:
+		entity, module = body.Id, body.Module
+	}
+	for _, filter := range filters {
+		if filter.Matches(mutation.Operation, entity, module, key) {
+			return h.module.OnEmit(mutation)
+		}
+	}
+	return nil
+}
+
+// Await waits for a condition to be met, failing the test if it is not met in time
+func (h *Harness) Await(condition func() bool) {
+	h.t.Helper()
+	deadline := time.Now().Add(awaitTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("condition was not met within %s", awaitTimeout)
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+}
+
+// Entity finds an entity registered by any module, failing the test if it does not exist
+func (h *Harness) Entity(name string) domain.Entity {
+	h.t.Helper()
+	entity, err := h.Memory.entities.FindByName(name)
+	if err != nil {
+		h.t.Fatalf("entity '%s' was not registered", name)
+	}
+	return *entity
+}
+
+// Entities lists the entities registered by the module
+func (h *Harness) Entities() []domain.Entity {
+	return *h.Memory.entities.filter(func(entity domain.Entity) bool { return entity.Module == h.name })
+}
+
+// Attribute finds an attribute of an entity, failing the test if it does not exist
+func (h *Harness) Attribute(entity string, key string) domain.Attribute {
+	h.t.Helper()
+	attribute, err := h.Memory.attributes.FindByComposite(h.Entity(entity).Id, key)
+	if err != nil {
+		h.t.Fatalf("attribute '%s' of '%s' was not registered", key, entity)
+	}
+	return *attribute
+}
+
+// Sets lists the attribute Set calls made, in order
+func (h *Harness) Sets() []Call {
+	h.Memory.mutex.Lock()
+	defer h.Memory.mutex.Unlock()
+	return append([]Call{}, h.Memory.sets...)
+}
+
+// Updates lists the attribute Update calls made, in order
+func (h *Harness) Updates() []Call {
+	h.Memory.mutex.Lock()
+	defer h.Memory.mutex.Unlock()
+	return append([]Call{}, h.Memory.updates...)
+}
+
+// Fired lists the names of the triggers fired, in order
+func (h *Harness) Fired() []string {
+	h.Memory.mutex.Lock()
+	defer h.Memory.mutex.Unlock()
+	return append([]string{}, h.Memory.fired...)
+}
+
+// Faults lists the faults the module reported
+func (h *Harness) Faults() []string {
+	h.Memory.mutex.Lock()
+	defer h.Memory.mutex.Unlock()
+	return append([]string{}, h.Memory.faults...)
+}
+
+// Logs lists the log entries written, including the violations of the module's scope
+func (h *Harness) Logs() []domain.Log {
+	return h.Memory.logs.all()
+}
+
+// Violations lists the actions the module was refused
+func (h *Harness) Violations() []domain.Log {
+	return *h.Memory.logs.filter(func(entry domain.Log) bool { return entry.Event == "violation" })
+}
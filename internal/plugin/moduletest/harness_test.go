@@ -0,0 +1,114 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package moduletest
+
+import (
+	"testing"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/plugin"
+)
+
+// lamp is a module with a single light that reports how many times it has updated
+type lamp struct {
+	plugin.Module
+	entity  string
+	updates int
+	request chan domain.Attribute
+}
+
+func (l *lamp) Setup() (plugin.Config, error) {
+	l.Config = plugin.Config{
+		Name:     "lamp",
+		Interval: time.Second,
+		Variables: []plugin.Variable{
+			{Name: "brightness", Type: domain.INT, Default: "50", Min: 0, Max: 100},
+		},
+	}
+	err := l.UpdateInterval(1000)
+	if err != nil {
+		return plugin.Config{}, err
+	}
+	return l.Config, nil
+}
+
+func (l *lamp) Run() error {
+	l.request = make(chan domain.Attribute)
+	entity := domain.Entity{Name: "lamp", Type: "light"}
+	err := l.Entities.Register(&entity)
+	if err != nil {
+		return err
+	}
+	l.entity = entity.Id
+	err = l.Attributes.Register(&domain.Attribute{Entity: entity.Id, Key: "on", Value: "false",
+		Channel: l.request})
+	if err != nil {
+		return err
+	}
+	err = l.Triggers.Register(&domain.Trigger{Name: "lamp-on", Type: domain.MODULE})
+	if err != nil {
+		return err
+	}
+	go func() {
+		for attribute := range l.request {
+			_ = l.Attributes.Set(attribute.Entity, attribute.Key, attribute.Request)
+			_ = l.Triggers.Trigger("lamp-on")
+		}
+	}()
+	return nil
+}
+
+func (l *lamp) Update() error {
+	if l.Ready() {
+		l.updates++
+		return l.Attributes.Update(l.entity, "on", "false", l.Now())
+	}
+	return nil
+}
+
+func (l *lamp) Dispose() error {
+	close(l.request)
+	return nil
+}
+
+func TestHarness(t *testing.T) {
+	module := &lamp{}
+	h := New(t, "lamp", module)
+	h.Start()
+
+	if len(h.Entities()) != 1 {
+		t.Fatalf("expected the lamp entity to be registered")
+	}
+
+	h.Request("lamp", "on", "true")
+	h.Await(func() bool { return h.Attribute("lamp", "on").Value == "true" })
+	h.Await(func() bool { return len(h.Fired()) == 1 })
+	if sets := h.Sets(); len(sets) != 1 || sets[0] != (Call{Entity: "lamp", Key: "on", Value: "true",
+		Stamp: h.Clock.Now()}) {
+		t.Errorf("unexpected set calls %v", sets)
+	}
+
+	// The module only updates once its interval has passed on the clock
+	_ = h.Update()
+	_ = h.Update()
+	h.Advance(time.Second)
+	_ = h.Update()
+	if module.updates != 2 {
+		t.Errorf("module updated %d times, want 2", module.updates)
+	}
+
+	value, err := module.GetConfig("brightness")
+	if err != nil || value != "50" {
+		t.Errorf("brightness = %s (%v), want the default 50", value, err)
+	}
+	if h.Configure("brightness", "500") == nil {
+		t.Errorf("an out of range variable should be refused")
+	}
+
+	// Other modules' entities may be read but not changed
+	other := domain.Entity{Name: "fan", Module: "other"}
+	_ = h.Memory.entities.Create(&other)
+	if module.Attributes.Set(other.Id, "on", "true") == nil || len(h.Violations()) != 1 {
+		t.Errorf("setting another module's attribute should be a violation")
+	}
+}
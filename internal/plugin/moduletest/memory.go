@@ -0,0 +1,164 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package moduletest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+	"udap/internal/core/generic"
+)
+
+// memory is an in-memory replacement for a repository, elements are copied in and out so the services
+// behave like they would with a database
+type memory[T generic.Identifiable] struct {
+	elements map[string]T
+	persist  func(*T) *common.Persistent
+	clock    *Clock
+	serial   int
+	mutex    sync.RWMutex
+	generic.Watchable[T]
+}
+
+func newMemory[T generic.Identifiable](m *Memory, persist func(*T) *common.Persistent) *memory[T] {
+	mem := &memory[T]{
+		elements: map[string]T{},
+		persist:  persist,
+		clock:    m.clock,
+	}
+	mem.UseBus(m.Bus)
+	return mem
+}
+
+// all returns copies of every element, ordered by creation
+func (m *memory[T]) all() []T {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	out := make([]T, 0, len(m.elements))
+	for _, element := range m.elements {
+		out = append(out, element)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return m.persist(&out[i]).CreatedAt.Before(m.persist(&out[j]).CreatedAt) ||
+			m.persist(&out[i]).CreatedAt.Equal(m.persist(&out[j]).CreatedAt) && out[i].GetId() < out[j].GetId()
+	})
+	return out
+}
+
+// find returns the first element matching the predicate
+func (m *memory[T]) find(match func(T) bool) (*T, error) {
+	for _, element := range m.all() {
+		if match(element) {
+			e := element
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("record not found")
+}
+
+// filter returns every element matching the predicate
+func (m *memory[T]) filter(match func(T) bool) *[]T {
+	out := []T{}
+	for _, element := range m.all() {
+		if match(element) {
+			out = append(out, element)
+		}
+	}
+	return &out
+}
+
+func (m *memory[T]) FindAll() (*[]T, error) {
+	all := m.all()
+	return &all, nil
+}
+
+func (m *memory[T]) FindById(id string) (*T, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	element, ok := m.elements[id]
+	if !ok {
+		return nil, fmt.Errorf("record not found")
+	}
+	return &element, nil
+}
+
+func (m *memory[T]) Create(element *T) error {
+	m.mutex.Lock()
+	p := m.persist(element)
+	if p.Id == "" {
+		m.serial++
+		p.Id = fmt.Sprintf("%08d-0000-0000-0000-000000000000", m.serial)
+	}
+	if _, ok := m.elements[p.Id]; ok {
+		m.mutex.Unlock()
+		return fmt.Errorf("record '%s' already exists", p.Id)
+	}
+	p.CreatedAt = m.clock.Now()
+	p.UpdatedAt = p.CreatedAt
+	m.elements[p.Id] = *element
+	m.mutex.Unlock()
+	return m.Emit(*element)
+}
+
+func (m *memory[T]) FindOrCreate(element *T) error {
+	id := m.persist(element).Id
+	if id != "" {
+		found, err := m.FindById(id)
+		if err == nil {
+			*element = *found
+			return nil
+		}
+	}
+	return m.Create(element)
+}
+
+func (m *memory[T]) Update(element *T) error {
+	m.mutex.Lock()
+	p := m.persist(element)
+	if _, ok := m.elements[p.Id]; !ok {
+		m.mutex.Unlock()
+		return fmt.Errorf("record not found")
+	}
+	p.UpdatedAt = m.clock.Now()
+	m.elements[p.Id] = *element
+	m.mutex.Unlock()
+	return m.Emit(*element)
+}
+
+func (m *memory[T]) Delete(element *T) error {
+	return m.remove(m.persist(element).Id)
+}
+
+func (m *memory[T]) remove(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if _, ok := m.elements[id]; !ok {
+		return fmt.Errorf("record not found")
+	}
+	delete(m.elements, id)
+	return nil
+}
+
+// change applies a modification to a stored element
+func (m *memory[T]) change(id string, modify func(*T) error) error {
+	element, err := m.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = modify(element)
+	if err != nil {
+		return err
+	}
+	return m.Update(element)
+}
+
+func (m *memory[T]) Snapshot() ([]domain.Mutation, error) {
+	return m.Mutations(m.all()), nil
+}
+
+func (m *memory[T]) EmitAll() error {
+	m.Publish(m.Mutations(m.all()))
+	return nil
+}
@@ -0,0 +1,1155 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package moduletest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/bus"
+	"udap/internal/core/domain"
+	"udap/internal/core/domain/common"
+	"udap/internal/core/ports"
+)
+
+// errUnavailable is returned by the parts of the system that cannot run in a test
+var errUnavailable = fmt.Errorf("not available in module tests")
+
+// Call is a change made to an attribute
+type Call struct {
+	Entity string    `json:"entity"` // The name of the entity
+	Key    string    `json:"key"`
+	Value  string    `json:"value"`
+	Stamp  time.Time `json:"stamp"`
+}
+
+// Memory holds in-memory implementations of every controller service, and records what is done with them
+type Memory struct {
+	// Bus carries the mutations of the in-memory services
+	Bus           *bus.Bus
+	clock         *Clock
+	attributes    *attributes
+	devices       *devices
+	entities      *entities
+	networks      *networks
+	logs          *logs
+	notifications *notifications
+	users         *users
+	zones         *zones
+	endpoints     *endpoints
+	modules       *modules
+	macros        *macros
+	triggers      *triggers
+	subRoutines   *subRoutines
+	actions       *actions
+	rules         *rules
+	holds         *holds
+	scenes        *scenes
+	invocations   *invocations
+
+	sets    []Call
+	updates []Call
+	fired   []string
+	faults  []string
+	filters []domain.MutationFilter
+	mutex   sync.Mutex
+}
+
+func NewMemory(clock *Clock) *Memory {
+	m := &Memory{Bus: bus.New(), clock: clock}
+	m.attributes = &attributes{
+		memory: newMemory(m, func(a *domain.Attribute) *common.Persistent { return &a.Persistent }),
+		m:      m,
+		hooks:  map[string]chan domain.Attribute{},
+	}
+	m.devices = &devices{newMemory(m, func(d *domain.Device) *common.Persistent { return &d.Persistent })}
+	m.entities = &entities{newMemory(m, func(e *domain.Entity) *common.Persistent { return &e.Persistent })}
+	m.networks = &networks{newMemory(m, func(n *domain.Network) *common.Persistent { return &n.Persistent })}
+	m.logs = &logs{newMemory(m, func(l *domain.Log) *common.Persistent { return &l.Persistent })}
+	m.notifications = &notifications{newMemory(m,
+		func(n *domain.Notification) *common.Persistent { return &n.Persistent })}
+	m.users = &users{newMemory(m, func(u *domain.User) *common.Persistent { return &u.Persistent })}
+	m.zones = &zones{newMemory(m, func(z *domain.Zone) *common.Persistent { return &z.Persistent })}
+	m.endpoints = &endpoints{newMemory(m, func(e *domain.Endpoint) *common.Persistent { return &e.Persistent })}
+	m.modules = &modules{
+		memory: newMemory(m, func(d *domain.Module) *common.Persistent { return &d.Persistent }),
+		m:      m,
+	}
+	m.macros = &macros{newMemory(m, func(d *domain.Macro) *common.Persistent { return &d.Persistent })}
+	m.triggers = &triggers{
+		memory: newMemory(m, func(t *domain.Trigger) *common.Persistent { return &t.Persistent }),
+		m:      m,
+	}
+	m.subRoutines = &subRoutines{newMemory(m,
+		func(s *domain.SubRoutine) *common.Persistent { return &s.Persistent })}
+	m.actions = &actions{newMemory(m, func(a *domain.Action) *common.Persistent { return &a.Persistent })}
+	m.rules = &rules{newMemory(m, func(r *domain.Rule) *common.Persistent { return &r.Persistent })}
+	m.holds = &holds{
+		memory: newMemory(m, func(h *domain.Hold) *common.Persistent { return &h.Persistent }),
+		m:      m,
+	}
+	m.scenes = &scenes{
+		memory: newMemory(m, func(s *domain.Scene) *common.Persistent { return &s.Persistent }),
+		m:      m,
+	}
+	m.invocations = &invocations{
+		memory: newMemory(m, func(i *domain.Invocation) *common.Persistent { return &i.Persistent }),
+		m:      m,
+	}
+	return m
+}
+
+// Controller builds a controller of the in-memory services
+func (m *Memory) Controller() *controller.Controller {
+	return &controller.Controller{
+		Attributes:    m.attributes,
+		Devices:       m.devices,
+		Entities:      m.entities,
+		Networks:      m.networks,
+		Logs:          m.logs,
+		Notifications: m.notifications,
+		Users:         m.users,
+		Zones:         m.zones,
+		Endpoints:     m.endpoints,
+		Modules:       m.modules,
+		Macros:        m.macros,
+		Triggers:      m.triggers,
+		SubRoutines:   m.subRoutines,
+		Actions:       m.actions,
+		Rules:         m.rules,
+		Holds:         m.holds,
+		Scenes:        m.scenes,
+		Invocations:   m.invocations,
+		Bus:           m.Bus,
+	}
+}
+
+// entityName resolves an entity id to its name for recorded calls
+func (m *Memory) entityName(id string) string {
+	entity, err := m.entities.FindById(id)
+	if err != nil {
+		return id
+	}
+	return entity.Name
+}
+
+type attributes struct {
+	*memory[domain.Attribute]
+	m     *Memory
+	hooks map[string]chan domain.Attribute
+	lock  sync.Mutex
+}
+
+func (a *attributes) FindByComposite(entity string, key string) (*domain.Attribute, error) {
+	return a.find(func(attribute domain.Attribute) bool {
+		return attribute.Entity == entity && attribute.Key == key
+	})
+}
+
+func (a *attributes) FindAllByEntity(entity string) (*[]domain.Attribute, error) {
+	return a.filter(func(attribute domain.Attribute) bool { return attribute.Entity == entity }), nil
+}
+
+// Register creates the attribute or finds the existing one, requests are sent to the attribute's channel
+func (a *attributes) Register(attribute *domain.Attribute) error {
+	if attribute.Channel == nil {
+		return fmt.Errorf("attribute channel is not set")
+	}
+	channel := attribute.Channel
+	existing, err := a.FindByComposite(attribute.Entity, attribute.Key)
+	if err == nil {
+		attribute.Id = existing.Id
+		attribute.CreatedAt = existing.CreatedAt
+		err = a.memory.Update(attribute)
+	} else {
+		attribute.State = domain.CONFIRMED
+		err = a.Create(attribute)
+	}
+	if err != nil {
+		return err
+	}
+	attribute.Channel = channel
+	a.lock.Lock()
+	a.hooks[attribute.Id] = channel
+	a.lock.Unlock()
+	return nil
+}
+
+func (a *attributes) Summary(string, int64, int64, int, string) (map[int64]float64, error) {
+	return map[int64]float64{}, nil
+}
+
+func (a *attributes) Request(entity string, key string, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return a.RequestContext(ctx, entity, key, value)
+}
+
+// RequestContext delivers the request to the module that registered the attribute
+func (a *attributes) RequestContext(ctx context.Context, entity string, key string, value string) error {
+	attribute, err := a.FindByComposite(entity, key)
+	if err != nil {
+		return err
+	}
+	a.lock.Lock()
+	channel, ok := a.hooks[attribute.Id]
+	a.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("channel is not set")
+	}
+	attribute.Request = value
+	attribute.Requested = a.m.clock.Now()
+	attribute.State = domain.PENDING
+	err = a.memory.Update(attribute)
+	if err != nil {
+		return err
+	}
+	select {
+	case channel <- *attribute:
+		return nil
+	case <-ctx.Done():
+		return domain.ErrNotConsuming
+	}
+}
+
+func (a *attributes) Queues() []domain.DispatchQueue {
+	return []domain.DispatchQueue{}
+}
+
+func (a *attributes) Set(entity string, key string, value string) error {
+	a.m.mutex.Lock()
+	a.m.sets = append(a.m.sets, Call{Entity: a.m.entityName(entity), Key: key, Value: value, Stamp: a.m.clock.Now()})
+	a.m.mutex.Unlock()
+	attribute, err := a.FindByComposite(entity, key)
+	if err != nil {
+		return err
+	}
+	attribute.Value = value
+	attribute.Request = value
+	attribute.State = domain.CONFIRMED
+	return a.memory.Update(attribute)
+}
+
+func (a *attributes) Update(entity string, key string, value string, stamp time.Time) error {
+	a.m.mutex.Lock()
+	a.m.updates = append(a.m.updates, Call{Entity: a.m.entityName(entity), Key: key, Value: value, Stamp: stamp})
+	a.m.mutex.Unlock()
+	attribute, err := a.FindByComposite(entity, key)
+	if err != nil {
+		return err
+	}
+	attribute.Value = value
+	attribute.Updated = stamp
+	if !attribute.Pending() || attribute.Request == value {
+		attribute.Request = value
+		attribute.State = domain.CONFIRMED
+	}
+	return a.memory.Update(attribute)
+}
+
+type devices struct {
+	*memory[domain.Device]
+}
+
+// Register finds a device with the same name, mac or address, or creates it
+func (d *devices) Register(device *domain.Device) error {
+	found, err := d.find(func(existing domain.Device) bool {
+		return device.Mac != "" && existing.Mac == device.Mac ||
+			device.Ipv4 != "" && existing.Ipv4 == device.Ipv4 ||
+			device.Name != "" && existing.Name == device.Name
+	})
+	if err == nil {
+		device.Id = found.Id
+		device.CreatedAt = found.CreatedAt
+		return d.Update(device)
+	}
+	return d.Create(device)
+}
+
+func (d *devices) Ping(id string, latency time.Duration) error {
+	return d.change(id, func(device *domain.Device) error {
+		device.Latency = latency
+		device.LastSeen = d.clock.Now()
+		return nil
+	})
+}
+
+func (d *devices) Utilization(id string, utilization domain.Utilization) error {
+	return d.change(id, func(device *domain.Device) error {
+		device.Utilization = utilization
+		return nil
+	})
+}
+
+type entities struct {
+	*memory[domain.Entity]
+}
+
+func (e *entities) FindByName(name string) (*domain.Entity, error) {
+	return e.find(func(entity domain.Entity) bool { return entity.Name == name })
+}
+
+func (e *entities) FindAllByModule(name string) (*[]domain.Entity, error) {
+	return e.filter(func(entity domain.Entity) bool { return entity.Module == name }), nil
+}
+
+// Register finds the module's entity of the same name, or creates it
+func (e *entities) Register(entity *domain.Entity) error {
+	found, err := e.find(func(existing domain.Entity) bool {
+		return existing.Name == entity.Name && existing.Module == entity.Module
+	})
+	if err == nil {
+		*entity = *found
+		return nil
+	}
+	return e.Create(entity)
+}
+
+func (e *entities) ChangeIcon(id string, icon string) error {
+	return e.change(id, func(entity *domain.Entity) error {
+		entity.Icon = icon
+		return nil
+	})
+}
+
+func (e *entities) ChangeAlias(id string, alias string) error {
+	return e.change(id, func(entity *domain.Entity) error {
+		entity.Alias = alias
+		return nil
+	})
+}
+
+func (e *entities) SetPrediction(id string, prediction string) error {
+	return e.change(id, func(entity *domain.Entity) error {
+		entity.Predicted = prediction
+		return nil
+	})
+}
+
+func (e *entities) Config(id string, value string) error {
+	return e.change(id, func(entity *domain.Entity) error {
+		entity.Config = value
+		return nil
+	})
+}
+
+type networks struct {
+	*memory[domain.Network]
+}
+
+// Register finds a network by name, or creates it
+func (n *networks) Register(network *domain.Network) error {
+	found, err := n.find(func(existing domain.Network) bool { return existing.Name == network.Name })
+	if err == nil {
+		network.Id = found.Id
+		network.CreatedAt = found.CreatedAt
+		return n.Update(network)
+	}
+	return n.Create(network)
+}
+
+type logs struct {
+	*memory[domain.Log]
+}
+
+func (l *logs) Create(entry *domain.Log) error {
+	if entry.Time.IsZero() {
+		entry.Time = l.clock.Now()
+	}
+	return l.memory.Create(entry)
+}
+
+func (l *logs) Query(filter domain.LogFilter) (*domain.LogPage, error) {
+	matched := *l.filter(func(entry domain.Log) bool {
+		return (filter.Level == "" || entry.Level == filter.Level) &&
+			(filter.Module == "" || entry.Module == filter.Module) &&
+			(filter.Entity == "" || entry.Entity == filter.Entity) &&
+			(filter.Correlation == "" || entry.Correlation == filter.Correlation) &&
+			(filter.Search == "" || strings.Contains(entry.Message, filter.Search)) &&
+			(filter.Since.IsZero() || !entry.Time.Before(filter.Since)) &&
+			(filter.Until.IsZero() || !entry.Time.After(filter.Until))
+	})
+	page := domain.LogPage{Total: int64(len(matched)), Offset: filter.Offset, Limit: filter.Limit}
+	page.Logs = paginate(matched, filter.Offset, filter.Limit)
+	return &page, nil
+}
+
+func (l *logs) Prune() error {
+	return nil
+}
+
+type notifications struct {
+	*memory[domain.Notification]
+}
+
+func (n *notifications) Register(notification *domain.Notification) error {
+	return n.Create(notification)
+}
+
+type users struct {
+	*memory[domain.User]
+}
+
+func (u *users) Register(user *domain.User) error {
+	_, err := u.find(func(existing domain.User) bool { return existing.Username == user.Username })
+	if err == nil {
+		return fmt.Errorf("user '%s' already exists", user.Username)
+	}
+	return u.Create(user)
+}
+
+func (u *users) Authenticate(user *domain.User) error {
+	found, err := u.find(func(existing domain.User) bool {
+		return existing.Username == user.Username && existing.Password == user.Password
+	})
+	if err != nil {
+		return fmt.Errorf("invalid credentials")
+	}
+	*user = *found
+	return nil
+}
+
+type zones struct {
+	*memory[domain.Zone]
+}
+
+func (z *zones) FindByName(name string) (*domain.Zone, error) {
+	return z.find(func(zone domain.Zone) bool { return zone.Name == name })
+}
+
+func (z *zones) AddEntity(id string, entity string) error {
+	return z.change(id, func(zone *domain.Zone) error {
+		zone.Entities = append(zone.Entities, domain.Entity{Persistent: common.Persistent{Id: entity}})
+		return nil
+	})
+}
+
+func (z *zones) RemoveEntity(id string, entity string) error {
+	return z.change(id, func(zone *domain.Zone) error {
+		kept := []domain.Entity{}
+		for _, e := range zone.Entities {
+			if e.Id != entity {
+				kept = append(kept, e)
+			}
+		}
+		zone.Entities = kept
+		return nil
+	})
+}
+
+func (z *zones) Pin(id string) error {
+	return z.change(id, func(zone *domain.Zone) error {
+		zone.Pinned = true
+		return nil
+	})
+}
+
+func (z *zones) Unpin(id string) error {
+	return z.change(id, func(zone *domain.Zone) error {
+		zone.Pinned = false
+		return nil
+	})
+}
+
+func (z *zones) Delete(id string) error {
+	return z.change(id, func(zone *domain.Zone) error {
+		zone.Deleted = true
+		return nil
+	})
+}
+
+func (z *zones) Restore(id string) error {
+	return z.change(id, func(zone *domain.Zone) error {
+		zone.Deleted = false
+		return nil
+	})
+}
+
+type endpoints struct {
+	*memory[domain.Endpoint]
+}
+
+func (e *endpoints) FindByKey(key string) (*domain.Endpoint, error) {
+	return e.find(func(endpoint domain.Endpoint) bool { return endpoint.Key == key })
+}
+
+func (e *endpoints) RegisterPush(id string, push string) error {
+	return e.change(id, func(endpoint *domain.Endpoint) error {
+		endpoint.Push = push
+		return nil
+	})
+}
+
+func (e *endpoints) CloseAll() error {
+	return nil
+}
+
+func (e *endpoints) Enroll(string, *websocket.Conn) error {
+	return errUnavailable
+}
+
+func (e *endpoints) SendAll(string, string, any) error {
+	return nil
+}
+
+func (e *endpoints) Send(string, string, any) error {
+	return nil
+}
+
+func (e *endpoints) Unenroll(string, *websocket.Conn) error {
+	return nil
+}
+
+func (e *endpoints) Subscribe(string, string, domain.Subscription) error {
+	return errUnavailable
+}
+
+func (e *endpoints) Unsubscribe(string, string) error {
+	return errUnavailable
+}
+
+func (e *endpoints) Execute(string, domain.Command) {
+}
+
+func (e *endpoints) Stats() []domain.EndpointStats {
+	return []domain.EndpointStats{}
+}
+
+func (e *endpoints) Resync() {}
+
+func (e *endpoints) Delete(id string) error {
+	return e.remove(id)
+}
+
+// modules stands in for the module service, the module under test is driven by the harness instead
+type modules struct {
+	*memory[domain.Module]
+	m         *Memory
+	configure func(key string, value string) error
+}
+
+func (s *modules) byUUID(uuid string) (*domain.Module, error) {
+	return s.find(func(module domain.Module) bool { return module.UUID == uuid })
+}
+
+func (s *modules) values(module *domain.Module) map[string]string {
+	values := map[string]string{}
+	_ = json.Unmarshal([]byte(module.Config), &values)
+	return values
+}
+
+func (s *modules) store(module *domain.Module, key string, value string) error {
+	values := s.values(module)
+	values[key] = value
+	marshal, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	module.Config = string(marshal)
+	return s.memory.Update(module)
+}
+
+func (s *modules) Discover() error {
+	return nil
+}
+
+func (s *modules) InitConfig(uuid string, key string, value string) error {
+	module, err := s.byUUID(uuid)
+	if err != nil {
+		return err
+	}
+	if s.values(module)[key] != "" {
+		return nil
+	}
+	return s.store(module, key, value)
+}
+
+func (s *modules) SetConfig(uuid string, key string, value string) error {
+	module, err := s.byUUID(uuid)
+	if err != nil {
+		return err
+	}
+	if variable, ok := module.Variable(key); ok {
+		err = variable.Validate(value)
+		if err != nil {
+			return err
+		}
+	}
+	return s.store(module, key, value)
+}
+
+func (s *modules) GetConfig(uuid string, key string) (string, error) {
+	module, err := s.byUUID(uuid)
+	if err != nil {
+		return "", err
+	}
+	value, ok := s.values(module)[key]
+	if !ok {
+		return "", fmt.Errorf("config value on key '%s' does not exist", key)
+	}
+	return value, nil
+}
+
+// Configure changes a variable and notifies the module under test
+func (s *modules) Configure(name string, key string, value string) error {
+	module, err := s.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if variable, ok := module.Variable(key); ok {
+		err = variable.Validate(value)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.store(module, key, value)
+	if err != nil {
+		return err
+	}
+	if s.configure == nil {
+		return nil
+	}
+	return s.configure(key, value)
+}
+
+func (s *modules) HandleEmits(domain.Mutation) error {
+	return nil
+}
+
+func (s *modules) Build(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Load(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Update(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Run(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Dispose(string) error {
+	return errUnavailable
+}
+
+func (s *modules) UpdateAll() error {
+	return nil
+}
+
+func (s *modules) RunAll() error {
+	return nil
+}
+
+func (s *modules) DisposeAll() error {
+	return nil
+}
+
+func (s *modules) LoadAll() error {
+	return nil
+}
+
+func (s *modules) BuildAll() error {
+	return nil
+}
+
+func (s *modules) FindByName(name string) (*domain.Module, error) {
+	return s.find(func(module domain.Module) bool { return module.Name == name })
+}
+
+func (s *modules) Disable(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Enable(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Reload(string) error {
+	return errUnavailable
+}
+
+func (s *modules) Halt(string) error {
+	return errUnavailable
+}
+
+func (s *modules) SetRuntime(string, string) error {
+	return errUnavailable
+}
+
+func (s *modules) Fault(_ string, reason string) error {
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+	s.m.faults = append(s.m.faults, reason)
+	return nil
+}
+
+func (s *modules) Health(name string) (domain.ModuleHealth, error) {
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+	status := domain.HEALTHY
+	if len(s.m.faults) > 0 {
+		status = domain.DEGRADED
+	}
+	return domain.ModuleHealth{Module: name, Status: status}, nil
+}
+
+func (s *modules) Supervise() {
+}
+
+func (s *modules) Schedule(name string) (domain.ModuleSchedule, error) {
+	return domain.ModuleSchedule{}, fmt.Errorf("module '%s' is not scheduled", name)
+}
+
+func (s *modules) Schedules() []domain.ModuleSchedule {
+	return []domain.ModuleSchedule{}
+}
+
+func (s *modules) Subscribe(_ string, filter domain.MutationFilter) error {
+	s.m.mutex.Lock()
+	defer s.m.mutex.Unlock()
+	s.m.filters = append(s.m.filters, filter)
+	return nil
+}
+
+func (s *modules) Stage(io.Reader) (domain.Manifest, error) {
+	return domain.Manifest{}, errUnavailable
+}
+
+func (s *modules) Install(string) (*domain.Module, error) {
+	return nil, errUnavailable
+}
+
+func (s *modules) Rollback(string) error {
+	return errUnavailable
+}
+
+type macros struct {
+	*memory[domain.Macro]
+}
+
+func (s *macros) Run(id string, _ domain.Source) error {
+	_, err := s.FindById(id)
+	return err
+}
+
+func (s *macros) RunAndRevert(id string, source domain.Source, _ time.Duration) error {
+	return s.Run(id, source)
+}
+
+func (s *macros) Delete(id string) error {
+	return s.remove(id)
+}
+
+// triggers records the triggers that are fired
+type triggers struct {
+	*memory[domain.Trigger]
+	m *Memory
+}
+
+func (t *triggers) FindByName(name string) (*domain.Trigger, error) {
+	return t.find(func(trigger domain.Trigger) bool { return trigger.Name == name })
+}
+
+func (t *triggers) Trigger(name string) error {
+	return t.TriggerFrom(name, domain.Source{Kind: domain.MODULE})
+}
+
+func (t *triggers) TriggerFrom(name string, _ domain.Source) error {
+	trigger, err := t.FindByName(name)
+	if err != nil {
+		return err
+	}
+	t.m.mutex.Lock()
+	t.m.fired = append(t.m.fired, name)
+	t.m.mutex.Unlock()
+	trigger.LastTrigger = t.clock.Now()
+	return t.Update(trigger)
+}
+
+func (t *triggers) TriggerCustom(name string, _ string, _ string) error {
+	return t.Trigger(name)
+}
+
+// Register finds a trigger by name, or creates it
+func (t *triggers) Register(trigger *domain.Trigger) error {
+	found, err := t.FindByName(trigger.Name)
+	if err == nil {
+		*trigger = *found
+		return nil
+	}
+	return t.Create(trigger)
+}
+
+func (t *triggers) Schedule(id string, schedule domain.Schedule) error {
+	return t.change(id, func(trigger *domain.Trigger) error {
+		trigger.Schedule = schedule
+		return nil
+	})
+}
+
+func (t *triggers) StartSchedules() error {
+	return nil
+}
+
+type subRoutines struct {
+	*memory[domain.SubRoutine]
+}
+
+func (s *subRoutines) Run(id string, _ domain.Source) error {
+	return s.change(id, func(subRoutine *domain.SubRoutine) error {
+		subRoutine.LastRun = s.clock.Now()
+		return nil
+	})
+}
+
+func (s *subRoutines) TriggerById(id string, source domain.Source) error {
+	for _, subRoutine := range *s.filter(func(subRoutine domain.SubRoutine) bool {
+		return subRoutine.TriggerId == id
+	}) {
+		err := s.Run(subRoutine.Id, source)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *subRoutines) AddMacro(id string, macroId string) error {
+	return s.change(id, func(subRoutine *domain.SubRoutine) error {
+		macro := domain.Macro{}
+		macro.Id = macroId
+		subRoutine.Macros = append(subRoutine.Macros, macro)
+		return nil
+	})
+}
+
+func (s *subRoutines) RemoveMacro(id string, macroId string) error {
+	return s.change(id, func(subRoutine *domain.SubRoutine) error {
+		kept := []domain.Macro{}
+		for _, macro := range subRoutine.Macros {
+			if macro.Id != macroId {
+				kept = append(kept, macro)
+			}
+		}
+		subRoutine.Macros = kept
+		return nil
+	})
+}
+
+func (s *subRoutines) AddScene(id string, sceneId string) error {
+	return s.change(id, func(subRoutine *domain.SubRoutine) error {
+		scene := domain.Scene{}
+		scene.Id = sceneId
+		subRoutine.Scenes = append(subRoutine.Scenes, scene)
+		return nil
+	})
+}
+
+func (s *subRoutines) RemoveScene(id string, sceneId string) error {
+	return s.change(id, func(subRoutine *domain.SubRoutine) error {
+		kept := []domain.Scene{}
+		for _, scene := range subRoutine.Scenes {
+			if scene.Id != sceneId {
+				kept = append(kept, scene)
+			}
+		}
+		subRoutine.Scenes = kept
+		return nil
+	})
+}
+
+func (s *subRoutines) Delete(id string) error {
+	return s.remove(id)
+}
+
+type actions struct {
+	*memory[domain.Action]
+}
+
+func (a *actions) FindByTriggerId(id string) (*[]domain.Action, error) {
+	return a.filter(func(action domain.Action) bool { return action.TriggerId == id }), nil
+}
+
+func (a *actions) ExecuteById(id string, _ domain.Source) error {
+	return a.change(id, func(action *domain.Action) error {
+		action.LastRun = a.clock.Now()
+		return nil
+	})
+}
+
+func (a *actions) ExecuteCustomById(id string, _ string, _ string, source domain.Source) error {
+	return a.ExecuteById(id, source)
+}
+
+func (a *actions) Delete(id string) error {
+	return a.remove(id)
+}
+
+type rules struct {
+	*memory[domain.Rule]
+}
+
+func (r *rules) HandleMutation(domain.Mutation) error {
+	return nil
+}
+
+func (r *rules) Enable(id string) error {
+	return r.change(id, func(rule *domain.Rule) error {
+		rule.Enabled = true
+		return nil
+	})
+}
+
+func (r *rules) Disable(id string) error {
+	return r.change(id, func(rule *domain.Rule) error {
+		rule.Enabled = false
+		return nil
+	})
+}
+
+func (r *rules) Delete(id string) error {
+	return r.remove(id)
+}
+
+type holds struct {
+	*memory[domain.Hold]
+	m *Memory
+}
+
+// Hold records a hold on an attribute, holds do not expire on their own in tests
+func (h *holds) Hold(origin string, macroId string, attribute domain.Attribute, applied string,
+	duration time.Duration) error {
+	hold := domain.Hold{
+		Origin:    origin,
+		MacroId:   macroId,
+		Attribute: attribute.Id,
+		Entity:    attribute.Entity,
+		Key:       attribute.Key,
+		Value:     attribute.Request,
+		Applied:   applied,
+		Expires:   h.clock.Now().Add(duration),
+	}
+	return h.Create(&hold)
+}
+
+func (h *holds) HandleMutation(domain.Mutation) error {
+	return nil
+}
+
+func (h *holds) Resume() error {
+	return nil
+}
+
+// Release restores the held value and removes the hold
+func (h *holds) Release(id string) error {
+	hold, err := h.FindById(id)
+	if err != nil {
+		return err
+	}
+	err = h.m.attributes.Set(hold.Entity, hold.Key, hold.Value)
+	if err != nil {
+		return err
+	}
+	return h.remove(id)
+}
+
+func (h *holds) Cancel(id string) error {
+	return h.remove(id)
+}
+
+type scenes struct {
+	*memory[domain.Scene]
+	m *Memory
+}
+
+// Capture records the current values of the keys of each entity in the zone
+func (s *scenes) Capture(name string, zoneId string, keys []string) (*domain.Scene, error) {
+	zone, err := s.m.zones.FindById(zoneId)
+	if err != nil {
+		return nil, err
+	}
+	scene := domain.Scene{Name: name, ZoneId: zoneId, Values: s.values(*zone, keys)}
+	err = s.Create(&scene)
+	if err != nil {
+		return nil, err
+	}
+	return &scene, nil
+}
+
+func (s *scenes) values(zone domain.Zone, keys []string) []domain.SceneValue {
+	values := []domain.SceneValue{}
+	for _, entity := range zone.Entities {
+		for _, key := range keys {
+			attribute, err := s.m.attributes.FindByComposite(entity.Id, key)
+			if err != nil {
+				continue
+			}
+			values = append(values, domain.SceneValue{Entity: entity.Id, Key: key, Value: attribute.Value})
+		}
+	}
+	return values
+}
+
+func (s *scenes) Recapture(id string) error {
+	return s.change(id, func(scene *domain.Scene) error {
+		keys := []string{}
+		for _, value := range scene.Values {
+			keys = append(keys, value.Key)
+		}
+		zone, err := s.m.zones.FindById(scene.ZoneId)
+		if err != nil {
+			return err
+		}
+		scene.Values = s.values(*zone, keys)
+		return nil
+	})
+}
+
+// Apply requests each of the scene's values
+func (s *scenes) Apply(id string, _ domain.Source) error {
+	scene, err := s.FindById(id)
+	if err != nil {
+		return err
+	}
+	for _, value := range scene.Values {
+		err = s.m.attributes.Request(value.Entity, value.Key, value.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return s.change(id, func(scene *domain.Scene) error {
+		scene.LastApplied = s.clock.Now()
+		return nil
+	})
+}
+
+func (s *scenes) ApplyWithTransition(id string, _ time.Duration, source domain.Source) error {
+	return s.Apply(id, source)
+}
+
+func (s *scenes) Delete(id string) error {
+	return s.remove(id)
+}
+
+type invocations struct {
+	*memory[domain.Invocation]
+	m *Memory
+}
+
+func (i *invocations) Begin(kind string, target string, name string, source domain.Source) (*domain.Invocation,
+	error) {
+	invocation := domain.Invocation{
+		Kind:     kind,
+		Target:   target,
+		Name:     name,
+		Source:   source.Kind,
+		SourceId: source.Id,
+		Parent:   source.Parent,
+		Status:   domain.RUNNING,
+		Started:  i.clock.Now(),
+	}
+	err := i.Create(&invocation)
+	if err != nil {
+		return nil, err
+	}
+	return &invocation, nil
+}
+
+func (i *invocations) Request(id string, entity string, key string, value string, err error) error {
+	return i.change(id, func(invocation *domain.Invocation) error {
+		request := domain.InvocationRequest{Entity: entity, Key: key, Value: value, Time: i.clock.Now()}
+		if err != nil {
+			request.Error = err.Error()
+		}
+		invocation.Requests = append(invocation.Requests, request)
+		return nil
+	})
+}
+
+func (i *invocations) Record(invocation *domain.Invocation) error {
+	return i.Update(invocation)
+}
+
+func (i *invocations) Finish(invocation *domain.Invocation, err error) error {
+	invocation.Finished = i.clock.Now()
+	invocation.Duration = invocation.Finished.Sub(invocation.Started)
+	if err != nil {
+		invocation.Error = err.Error()
+	}
+	if invocation.Status == domain.RUNNING {
+		invocation.Status = domain.SUCCEEDED
+		if err != nil {
+			invocation.Status = domain.FAILED
+		}
+	}
+	return i.Update(invocation)
+}
+
+func (i *invocations) Query(filter domain.InvocationFilter) (*domain.InvocationPage, error) {
+	matched := *i.filter(func(invocation domain.Invocation) bool {
+		return (filter.Kind == "" || invocation.Kind == filter.Kind) &&
+			(filter.Target == "" || invocation.Target == filter.Target) &&
+			(filter.Source == "" || invocation.Source == filter.Source) &&
+			(filter.Parent == "" || invocation.Parent == filter.Parent) &&
+			(!filter.Failed || invocation.Error != "" || invocation.Status == domain.FAILED ||
+				invocation.Status == domain.ABORTED) &&
+			(filter.Since.IsZero() || !invocation.Started.Before(filter.Since)) &&
+			(filter.Until.IsZero() || !invocation.Started.After(filter.Until))
+	})
+	page := domain.InvocationPage{Total: int64(len(matched)), Offset: filter.Offset, Limit: filter.Limit}
+	page.Invocations = paginate(newestFirst(matched), filter.Offset, filter.Limit)
+	return &page, nil
+}
+
+// newestFirst reverses elements ordered by creation
+func newestFirst[T any](elements []T) []T {
+	out := make([]T, len(elements))
+	for i, element := range elements {
+		out[len(elements)-1-i] = element
+	}
+	return out
+}
+
+// paginate returns a page of elements, every element after the offset is returned when the limit is zero
+func paginate[T any](elements []T, offset int, limit int) []T {
+	if offset >= len(elements) {
+		return []T{}
+	}
+	elements = elements[offset:]
+	if limit > 0 && limit < len(elements) {
+		elements = elements[:limit]
+	}
+	return elements
+}
+
+// The services must satisfy their ports
+var (
+	_ ports.AttributeService    = &attributes{}
+	_ ports.DeviceService       = &devices{}
+	_ ports.EntityService       = &entities{}
+	_ ports.NetworkService      = &networks{}
+	_ ports.LogService          = &logs{}
+	_ ports.NotificationService = &notifications{}
+	_ ports.UserService         = &users{}
+	_ ports.ZoneService         = &zones{}
+	_ ports.EndpointService     = &endpoints{}
+	_ ports.ModuleService       = &modules{}
+	_ ports.MacroService        = &macros{}
+	_ ports.TriggerService      = &triggers{}
+	_ ports.SubRoutineService   = &subRoutines{}
+	_ ports.ActionService       = &actions{}
+	_ ports.RuleService         = &rules{}
+	_ ports.HoldService         = &holds{}
+	_ ports.SceneService        = &scenes{}
+	_ ports.InvocationService   = &invocations{}
+)
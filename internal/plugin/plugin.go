@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"plugin"
 	"udap/internal/controller"
+	"udap/internal/core/domain"
 )
 
 // ModuleInterface defines the functions of a plugin's exported variable
@@ -20,8 +21,10 @@ type ModuleInterface interface {
 	Update() error
 	// Dispose provides module-relevant data
 	Dispose() error
-	// OnEmit catches all emit events
-	OnEmit() error
+	// OnEmit receives the mutations matching the module's subscriptions
+	OnEmit(mutation domain.Mutation) error
+	// ConfigChanged receives the new value of a variable changed while the module is running
+	ConfigChanged(key string, value string) error
 }
 
 // Load attempts to load the plugin from a given path
@@ -0,0 +1,216 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package process
+
+import (
+	"fmt"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+)
+
+// Host serves the controller services a module process may call. Each module process has its own host, which
+// lets attribute requests be routed back to the process that registered the attribute.
+type Host struct {
+	ctrl      *controller.Controller
+	module    string
+	uuid      string
+	requests  chan domain.Attribute
+	handshake chan error
+}
+
+func newHost(module string) *Host {
+	return &Host{
+		module:    module,
+		requests:  make(chan domain.Attribute, 8),
+		handshake: make(chan error, 1),
+	}
+}
+
+// Handshake is the first call made by a module process, it confirms both sides speak the same protocol
+func (h *Host) Handshake(args Handshake, _ *Empty) error {
+	if args.Version != Version {
+		err := fmt.Errorf("module '%s' speaks protocol version %d, host requires %d", h.module, args.Version,
+			Version)
+		h.handshake <- err
+		return err
+	}
+	h.handshake <- nil
+	return nil
+}
+
+func (h *Host) RegisterEntity(args domain.Entity, reply *domain.Entity) error {
+	err := h.ctrl.Entities.Register(&args)
+	if err != nil {
+		return err
+	}
+	*reply = args
+	return nil
+}
+
+func (h *Host) FindEntityByName(name string, reply *domain.Entity) error {
+	entity, err := h.ctrl.Entities.FindByName(name)
+	if err != nil {
+		return err
+	}
+	*reply = *entity
+	return nil
+}
+
+func (h *Host) FindAllEntities(_ Empty, reply *[]domain.Entity) error {
+	entities, err := h.ctrl.Entities.FindAll()
+	if err != nil {
+		return err
+	}
+	*reply = *entities
+	return nil
+}
+
+func (h *Host) FindEntitiesByModule(name string, reply *[]domain.Entity) error {
+	entities, err := h.ctrl.Entities.FindAllByModule(name)
+	if err != nil {
+		return err
+	}
+	*reply = *entities
+	return nil
+}
+
+func (h *Host) ConfigEntity(args EntityArgs, _ *Empty) error {
+	return h.ctrl.Entities.Config(args.Id, args.Value)
+}
+
+func (h *Host) SetPrediction(args EntityArgs, _ *Empty) error {
+	return h.ctrl.Entities.SetPrediction(args.Id, args.Value)
+}
+
+// RegisterAttribute registers the attribute with the host's request channel in place of the module's own
+func (h *Host) RegisterAttribute(args domain.Attribute, reply *domain.Attribute) error {
+	args.Channel = h.requests
+	err := h.ctrl.Attributes.Register(&args)
+	if err != nil {
+		return err
+	}
+	*reply = args
+	reply.Channel = nil
+	return nil
+}
+
+func (h *Host) FindAttribute(args AttributeArgs, reply *domain.Attribute) error {
+	attribute, err := h.ctrl.Attributes.FindByComposite(args.Entity, args.Key)
+	if err != nil {
+		return err
+	}
+	*reply = *attribute
+	reply.Channel = nil
+	return nil
+}
+
+func (h *Host) SetAttribute(args AttributeArgs, _ *Empty) error {
+	return h.ctrl.Attributes.Set(args.Entity, args.Key, args.Value)
+}
+
+func (h *Host) UpdateAttribute(args AttributeArgs, _ *Empty) error {
+	return h.ctrl.Attributes.Update(args.Entity, args.Key, args.Value, args.Stamp)
+}
+
+func (h *Host) RequestAttribute(args AttributeArgs, _ *Empty) error {
+	return h.ctrl.Attributes.Request(args.Entity, args.Key, args.Value)
+}
+
+func (h *Host) RegisterTrigger(args domain.Trigger, reply *domain.Trigger) error {
+	err := h.ctrl.Triggers.Register(&args)
+	if err != nil {
+		return err
+	}
+	*reply = args
+	return nil
+}
+
+func (h *Host) Trigger(name string, _ *Empty) error {
+	return h.ctrl.Triggers.Trigger(name)
+}
+
+func (h *Host) RegisterDevice(args domain.Device, reply *domain.Device) error {
+	err := h.ctrl.Devices.Register(&args)
+	if err != nil {
+		return err
+	}
+	*reply = args
+	return nil
+}
+
+func (h *Host) UpdateDevice(args domain.Device, _ *Empty) error {
+	return h.ctrl.Devices.Update(&args)
+}
+
+func (h *Host) FindDevice(id string, reply *domain.Device) error {
+	device, err := h.ctrl.Devices.FindById(id)
+	if err != nil {
+		return err
+	}
+	*reply = *device
+	return nil
+}
+
+func (h *Host) Ping(args PingArgs, _ *Empty) error {
+	return h.ctrl.Devices.Ping(args.Id, args.Latency)
+}
+
+func (h *Host) Utilization(args UtilizationArgs, _ *Empty) error {
+	return h.ctrl.Devices.Utilization(args.Id, args.Utilization)
+}
+
+func (h *Host) RegisterNetwork(args domain.Network, reply *domain.Network) error {
+	err := h.ctrl.Networks.Register(&args)
+	if err != nil {
+		return err
+	}
+	*reply = args
+	return nil
+}
+
+func (h *Host) FindZone(name string, reply *domain.Zone) error {
+	zone, err := h.ctrl.Zones.FindByName(name)
+	if err != nil {
+		return err
+	}
+	*reply = *zone
+	return nil
+}
+
+func (h *Host) FindEndpoint(id string, reply *domain.Endpoint) error {
+	endpoint, err := h.ctrl.Endpoints.FindById(id)
+	if err != nil {
+		return err
+	}
+	*reply = *endpoint
+	return nil
+}
+
+func (h *Host) CreateLog(args domain.Log, _ *Empty) error {
+	return h.ctrl.Logs.Create(&args)
+}
+
+func (h *Host) InitConfig(args ConfigArgs, _ *Empty) error {
+	return h.ctrl.Modules.InitConfig(h.uuid, args.Key, args.Value)
+}
+
+func (h *Host) GetConfig(key string, reply *string) error {
+	value, err := h.ctrl.Modules.GetConfig(h.uuid, key)
+	if err != nil {
+		return err
+	}
+	*reply = value
+	return nil
+}
+
+func (h *Host) SetConfig(args ConfigArgs, _ *Empty) error {
+	return h.ctrl.Modules.SetConfig(h.uuid, args.Key, args.Value)
+}
+
+func (h *Host) Fault(reason string, _ *Empty) error {
+	return h.ctrl.Modules.Fault(h.uuid, reason)
+}
+
+func (h *Host) Subscribe(filter domain.MutationFilter, _ *Empty) error {
+	return h.ctrl.Modules.Subscribe(h.uuid, filter)
+}
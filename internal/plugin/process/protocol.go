@@ -0,0 +1,98 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package process runs modules as child processes. The host and the module process speak JSON-RPC over two
+// connections to a unix socket created by the host: the first carries calls from the module to the host's
+// services, the second carries calls from the host to the module.
+package process
+
+import (
+	"encoding/json"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// Version is the revision of the protocol spoken between the host and module processes. The host refuses
+// modules built against a different revision.
+const Version = 1
+
+// socketEnv names the environment variable that carries the host's socket address to the module process
+const socketEnv = "UDAP_MODULE_SOCKET"
+
+type Empty struct{}
+
+type Handshake struct {
+	Version int    `json:"version"`
+	Pid     int    `json:"pid"`
+	Module  string `json:"module"`
+}
+
+type AttributeArgs struct {
+	Entity string    `json:"entity"`
+	Key    string    `json:"key"`
+	Value  string    `json:"value"`
+	Stamp  time.Time `json:"stamp"`
+}
+
+type EntityArgs struct {
+	Id    string `json:"id"`
+	Value string `json:"value"`
+}
+
+type ConfigArgs struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type PingArgs struct {
+	Id      string        `json:"id"`
+	Latency time.Duration `json:"latency"`
+}
+
+type UtilizationArgs struct {
+	Id          string             `json:"id"`
+	Utilization domain.Utilization `json:"utilization"`
+}
+
+// Emission is a mutation as received by a module process, its body is decoded once the operation is known
+type Emission struct {
+	Status    string          `json:"status"`
+	Operation string          `json:"operation"`
+	Body      json.RawMessage `json:"body"`
+	Id        string          `json:"id"`
+}
+
+// Mutation decodes the body into the type named by the operation
+func (e Emission) Mutation() (domain.Mutation, error) {
+	mutation := domain.Mutation{
+		Status:    e.Status,
+		Operation: e.Operation,
+		Id:        e.Id,
+	}
+	var err error
+	switch e.Operation {
+	case "attribute":
+		body := domain.Attribute{}
+		err = json.Unmarshal(e.Body, &body)
+		mutation.Body = body
+	case "entity":
+		body := domain.Entity{}
+		err = json.Unmarshal(e.Body, &body)
+		mutation.Body = body
+	case "module":
+		body := domain.Module{}
+		err = json.Unmarshal(e.Body, &body)
+		mutation.Body = body
+	case "trigger":
+		body := domain.Trigger{}
+		err = json.Unmarshal(e.Body, &body)
+		mutation.Body = body
+	default:
+		var body any
+		err = json.Unmarshal(e.Body, &body)
+		mutation.Body = body
+	}
+	if err != nil {
+		return domain.Mutation{}, err
+	}
+	return mutation, nil
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/log"
+	"udap/internal/plugin"
+)
+
+const (
+	startTimeout = time.Second * 10
+	callTimeout  = time.Second * 30
+	stopTimeout  = time.Second * 5
+)
+
+// Proxy is the host side of a module process. It implements plugin.ModuleInterface by forwarding each call to
+// the process, so the module operator can treat it like a module loaded from a plugin file.
+type Proxy struct {
+	name     string
+	host     *Host
+	cmd      *exec.Cmd
+	listener net.Listener
+	address  string
+	client   *rpc.Client
+	exited   chan struct{}
+}
+
+// Start launches a module executable and waits for it to connect back to the host
+func Start(name string, uuid string, binary string) (*Proxy, error) {
+	address := filepath.Join(os.TempDir(), fmt.Sprintf("udap-%s.sock", uuid))
+	_ = os.Remove(address)
+
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proxy{
+		name:     name,
+		host:     newHost(name),
+		listener: listener,
+		address:  address,
+		exited:   make(chan struct{}),
+	}
+
+	p.cmd = exec.Command(binary)
+	p.cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", socketEnv, address))
+	stdout, stderr := p.output(), p.output()
+	p.cmd.Stdout = stdout
+	p.cmd.Stderr = stderr
+
+	err = p.cmd.Start()
+	if err != nil {
+		_ = stdout.Close()
+		_ = stderr.Close()
+		p.close()
+		return nil, err
+	}
+
+	go func() {
+		err := p.cmd.Wait()
+		if err != nil {
+			log.Event("Module process '%s' exited: %s", name, err.Error())
+		}
+		_ = stdout.Close()
+		_ = stderr.Close()
+		close(p.exited)
+	}()
+
+	err = p.connect()
+	if err != nil {
+		p.kill()
+		return nil, err
+	}
+
+	go p.forward()
+
+	return p, nil
+}
+
+// connect accepts the two connections made by the process, the first is served by the host
+func (p *Proxy) connect() error {
+	err := p.listener.(*net.UnixListener).SetDeadline(time.Now().Add(startTimeout))
+	if err != nil {
+		return err
+	}
+
+	hostConn, err := p.listener.Accept()
+	if err != nil {
+		return fmt.Errorf("module process '%s' did not connect: %s", p.name, err.Error())
+	}
+
+	server := rpc.NewServer()
+	err = server.RegisterName("Host", p.host)
+	if err != nil {
+		return err
+	}
+	go server.ServeCodec(jsonrpc.NewServerCodec(hostConn))
+
+	select {
+	case err = <-p.host.handshake:
+		if err != nil {
+			return err
+		}
+	case <-p.exited:
+		return fmt.Errorf("module process '%s' exited before connecting", p.name)
+	case <-time.After(startTimeout):
+		return fmt.Errorf("module process '%s' did not complete the handshake", p.name)
+	}
+
+	moduleConn, err := p.listener.Accept()
+	if err != nil {
+		return fmt.Errorf("module process '%s' did not connect: %s", p.name, err.Error())
+	}
+	p.client = jsonrpc.NewClient(moduleConn)
+
+	return nil
+}
+
+// forward relays attribute requests received by the host to the module process
+func (p *Proxy) forward() {
+	for {
+		select {
+		case attribute := <-p.host.requests:
+			err := p.call("Request", attribute, &Empty{}, callTimeout)
+			if err != nil {
+				log.ErrF(err, "module '%s' could not receive request for '%s'", p.name, attribute.Key)
+			}
+		case <-p.exited:
+			return
+		}
+	}
+}
+
+// output writes each line printed by the process to the system log
+func (p *Proxy) output() io.WriteCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			log.Event("%s: %s", p.name, scanner.Text())
+		}
+	}()
+	return writer
+}
+
+func (p *Proxy) call(method string, args any, reply any, timeout time.Duration) error {
+	call := p.client.Go("Module."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case done := <-call.Done:
+		return done.Error
+	case <-p.exited:
+		return fmt.Errorf("module process '%s' has exited", p.name)
+	case <-time.After(timeout):
+		return fmt.Errorf("module process '%s' did not respond to %s within %s", p.name, method,
+			timeout.String())
+	}
+}
+
+func (p *Proxy) Setup() (plugin.Config, error) {
+	config := plugin.Config{}
+	err := p.call("Setup", Empty{}, &config, callTimeout)
+	if err != nil {
+		return plugin.Config{}, err
+	}
+	return config, nil
+}
+
+func (p *Proxy) Connect(ctrl *controller.Controller, uuid string) error {
+	p.host.ctrl = ctrl
+	p.host.uuid = uuid
+	return p.call("Connect", uuid, &Empty{}, callTimeout)
+}
+
+func (p *Proxy) Run() error {
+	return p.call("Run", Empty{}, &Empty{}, callTimeout)
+}
+
+func (p *Proxy) Update() error {
+	return p.call("Update", Empty{}, &Empty{}, callTimeout)
+}
+
+func (p *Proxy) OnEmit(mutation domain.Mutation) error {
+	return p.call("OnEmit", mutation, &Empty{}, callTimeout)
+}
+
+func (p *Proxy) ConfigChanged(key string, value string) error {
+	return p.call("ConfigChanged", ConfigArgs{Key: key, Value: value}, &Empty{}, callTimeout)
+}
+
+// Dispose asks the module to halt, then stops the process
+func (p *Proxy) Dispose() error {
+	err := p.call("Dispose", Empty{}, &Empty{}, stopTimeout)
+	p.kill()
+	return err
+}
+
+// kill interrupts the process and forcibly stops it if it has not exited after the stop timeout
+func (p *Proxy) kill() {
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-p.exited:
+		case <-time.After(stopTimeout):
+			_ = p.cmd.Process.Kill()
+		}
+	}
+	p.close()
+}
+
+func (p *Proxy) close() {
+	if p.client != nil {
+		_ = p.client.Close()
+	}
+	_ = p.listener.Close()
+	_ = os.Remove(p.address)
+}
+
+// Exited is closed once the module process has stopped
+func (p *Proxy) Exited() <-chan struct{} {
+	return p.exited
+}
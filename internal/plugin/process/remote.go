@@ -0,0 +1,242 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package process
+
+import (
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+)
+
+// The services below forward the calls modules make to the host. Each embeds its port's unsupported service, so
+// only the methods implemented here reach the host and any other returns an error.
+
+// controller builds the controller handed to the module in place of the host's own
+func (r *remote) controller() *controller.Controller {
+	return &controller.Controller{
+		Attributes:    &remoteAttributes{unsupportedAttributes{unsupported{"Attributes"}}, r},
+		Devices:       &remoteDevices{unsupportedDevices{unsupported{"Devices"}}, r},
+		Entities:      &remoteEntities{unsupportedEntities{unsupported{"Entities"}}, r},
+		Networks:      &remoteNetworks{unsupportedNetworks{unsupported{"Networks"}}, r},
+		Logs:          &remoteLogs{unsupportedLogs{unsupported{"Logs"}}, r},
+		Notifications: &unsupportedNotifications{unsupported{"Notifications"}},
+		Users:         &unsupportedUsers{unsupported{"Users"}},
+		Zones:         &remoteZones{unsupportedZones{unsupported{"Zones"}}, r},
+		Endpoints:     &remoteEndpoints{unsupportedEndpoints{unsupported{"Endpoints"}}, r},
+		Modules:       &remoteModules{unsupportedModules{unsupported{"Modules"}}, r},
+		Macros:        &unsupportedMacros{unsupported{"Macros"}},
+		Triggers:      &remoteTriggers{unsupportedTriggers{unsupported{"Triggers"}}, r},
+		SubRoutines:   &unsupportedSubRoutines{unsupported{"SubRoutines"}},
+		Actions:       &unsupportedActions{unsupported{"Actions"}},
+		Rules:         &unsupportedRules{unsupported{"Rules"}},
+		Holds:         &unsupportedHolds{unsupported{"Holds"}},
+		Scenes:        &unsupportedScenes{unsupported{"Scenes"}},
+		Invocations:   &unsupportedInvocations{unsupported{"Invocations"}},
+	}
+}
+
+type remoteAttributes struct {
+	unsupportedAttributes
+	remote *remote
+}
+
+// Register keeps the module's channel locally, requests for the attribute are delivered to it by the host
+func (a *remoteAttributes) Register(attribute *domain.Attribute) error {
+	channel := attribute.Channel
+	reply := domain.Attribute{}
+	err := a.remote.call("RegisterAttribute", *attribute, &reply)
+	if err != nil {
+		return err
+	}
+	reply.Channel = channel
+	*attribute = reply
+	a.remote.hook(attribute.Id, channel)
+	return nil
+}
+
+func (a *remoteAttributes) FindByComposite(entity string, key string) (*domain.Attribute, error) {
+	reply := domain.Attribute{}
+	err := a.remote.call("FindAttribute", AttributeArgs{Entity: entity, Key: key}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (a *remoteAttributes) Set(entity string, key string, value string) error {
+	return a.remote.call("SetAttribute", AttributeArgs{Entity: entity, Key: key, Value: value}, &Empty{})
+}
+
+func (a *remoteAttributes) Update(entity string, key string, value string, stamp time.Time) error {
+	return a.remote.call("UpdateAttribute", AttributeArgs{Entity: entity, Key: key, Value: value, Stamp: stamp},
+		&Empty{})
+}
+
+func (a *remoteAttributes) Request(entity string, key string, value string) error {
+	return a.remote.call("RequestAttribute", AttributeArgs{Entity: entity, Key: key, Value: value}, &Empty{})
+}
+
+type remoteEntities struct {
+	unsupportedEntities
+	remote *remote
+}
+
+func (e *remoteEntities) Register(entity *domain.Entity) error {
+	return e.remote.call("RegisterEntity", *entity, entity)
+}
+
+func (e *remoteEntities) FindByName(name string) (*domain.Entity, error) {
+	reply := domain.Entity{}
+	err := e.remote.call("FindEntityByName", name, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (e *remoteEntities) FindAll() (*[]domain.Entity, error) {
+	var reply []domain.Entity
+	err := e.remote.call("FindAllEntities", Empty{}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (e *remoteEntities) FindAllByModule(name string) (*[]domain.Entity, error) {
+	var reply []domain.Entity
+	err := e.remote.call("FindEntitiesByModule", name, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (e *remoteEntities) Config(id string, value string) error {
+	return e.remote.call("ConfigEntity", EntityArgs{Id: id, Value: value}, &Empty{})
+}
+
+func (e *remoteEntities) SetPrediction(id string, prediction string) error {
+	return e.remote.call("SetPrediction", EntityArgs{Id: id, Value: prediction}, &Empty{})
+}
+
+type remoteTriggers struct {
+	unsupportedTriggers
+	remote *remote
+}
+
+func (t *remoteTriggers) Register(trigger *domain.Trigger) error {
+	return t.remote.call("RegisterTrigger", *trigger, trigger)
+}
+
+func (t *remoteTriggers) Trigger(name string) error {
+	return t.remote.call("Trigger", name, &Empty{})
+}
+
+type remoteDevices struct {
+	unsupportedDevices
+	remote *remote
+}
+
+func (d *remoteDevices) Register(device *domain.Device) error {
+	return d.remote.call("RegisterDevice", *device, device)
+}
+
+func (d *remoteDevices) Update(device *domain.Device) error {
+	return d.remote.call("UpdateDevice", *device, &Empty{})
+}
+
+func (d *remoteDevices) FindById(id string) (*domain.Device, error) {
+	reply := domain.Device{}
+	err := d.remote.call("FindDevice", id, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (d *remoteDevices) Ping(id string, latency time.Duration) error {
+	return d.remote.call("Ping", PingArgs{Id: id, Latency: latency}, &Empty{})
+}
+
+func (d *remoteDevices) Utilization(id string, utilization domain.Utilization) error {
+	return d.remote.call("Utilization", UtilizationArgs{Id: id, Utilization: utilization}, &Empty{})
+}
+
+type remoteNetworks struct {
+	unsupportedNetworks
+	remote *remote
+}
+
+func (n *remoteNetworks) Register(network *domain.Network) error {
+	return n.remote.call("RegisterNetwork", *network, network)
+}
+
+type remoteZones struct {
+	unsupportedZones
+	remote *remote
+}
+
+func (z *remoteZones) FindByName(name string) (*domain.Zone, error) {
+	reply := domain.Zone{}
+	err := z.remote.call("FindZone", name, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+type remoteEndpoints struct {
+	unsupportedEndpoints
+	remote *remote
+}
+
+func (e *remoteEndpoints) FindById(id string) (*domain.Endpoint, error) {
+	reply := domain.Endpoint{}
+	err := e.remote.call("FindEndpoint", id, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+type remoteLogs struct {
+	unsupportedLogs
+	remote *remote
+}
+
+func (l *remoteLogs) Create(log *domain.Log) error {
+	return l.remote.call("CreateLog", *log, &Empty{})
+}
+
+type remoteModules struct {
+	unsupportedModules
+	remote *remote
+}
+
+// The host identifies the module by its connection, so the uuid arguments are not sent
+
+func (m *remoteModules) InitConfig(_ string, key string, value string) error {
+	return m.remote.call("InitConfig", ConfigArgs{Key: key, Value: value}, &Empty{})
+}
+
+func (m *remoteModules) GetConfig(_ string, key string) (string, error) {
+	var reply string
+	err := m.remote.call("GetConfig", key, &reply)
+	if err != nil {
+		return "", err
+	}
+	return reply, nil
+}
+
+func (m *remoteModules) SetConfig(_ string, key string, value string) error {
+	return m.remote.call("SetConfig", ConfigArgs{Key: key, Value: value}, &Empty{})
+}
+
+func (m *remoteModules) Fault(_ string, reason string) error {
+	return m.remote.call("Fault", reason, &Empty{})
+}
+
+func (m *remoteModules) Subscribe(_ string, filter domain.MutationFilter) error {
+	return m.remote.call("Subscribe", filter, &Empty{})
+}
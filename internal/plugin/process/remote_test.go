@@ -0,0 +1,21 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package process
+
+import (
+	"testing"
+	"udap/internal/core/domain"
+)
+
+func TestRemote_Unsupported(t *testing.T) {
+	ctrl := (&remote{}).controller()
+
+	err := ctrl.Attributes.Create(&domain.Attribute{})
+	if err == nil {
+		t.Errorf("a method the host does not serve should return an error")
+	}
+	_, err = ctrl.Macros.FindAll()
+	if err == nil {
+		t.Errorf("a service the host does not serve should return an error")
+	}
+}
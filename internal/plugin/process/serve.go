@@ -0,0 +1,157 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package process
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/plugin"
+)
+
+// Serve connects a module to the host that launched it and serves the module until the host disconnects.
+// Modules built for the process runtime call Serve from their main function:
+//
+//	func main() {
+//		process.Serve(&Module)
+//	}
+func Serve(module plugin.ModuleInterface) {
+	address := os.Getenv(socketEnv)
+	if address == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "module processes must be launched by udap")
+		os.Exit(1)
+	}
+	err := serve(module, address)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func serve(module plugin.ModuleInterface, address string) error {
+	hostConn, err := net.Dial("unix", address)
+	if err != nil {
+		return err
+	}
+	client := jsonrpc.NewClient(hostConn)
+	defer client.Close()
+
+	err = client.Call("Host.Handshake", Handshake{
+		Version: Version,
+		Pid:     os.Getpid(),
+	}, &Empty{})
+	if err != nil {
+		return err
+	}
+
+	moduleConn, err := net.Dial("unix", address)
+	if err != nil {
+		return err
+	}
+
+	server := rpc.NewServer()
+	err = server.RegisterName("Module", &moduleServer{
+		module: module,
+		remote: newRemote(client),
+	})
+	if err != nil {
+		return err
+	}
+	// Serve until the host closes the connection
+	server.ServeCodec(jsonrpc.NewServerCodec(moduleConn))
+
+	return nil
+}
+
+// moduleServer receives the host's calls and passes them to the module
+type moduleServer struct {
+	module plugin.ModuleInterface
+	remote *remote
+}
+
+func (s *moduleServer) Connect(uuid string, _ *Empty) error {
+	return s.module.Connect(s.remote.controller(), uuid)
+}
+
+func (s *moduleServer) Setup(_ Empty, reply *plugin.Config) error {
+	config, err := s.module.Setup()
+	if err != nil {
+		return err
+	}
+	*reply = config
+	return nil
+}
+
+func (s *moduleServer) Run(_ Empty, _ *Empty) error {
+	return s.module.Run()
+}
+
+func (s *moduleServer) Update(_ Empty, _ *Empty) error {
+	return s.module.Update()
+}
+
+func (s *moduleServer) OnEmit(emission Emission, _ *Empty) error {
+	mutation, err := emission.Mutation()
+	if err != nil {
+		return err
+	}
+	return s.module.OnEmit(mutation)
+}
+
+func (s *moduleServer) ConfigChanged(args ConfigArgs, _ *Empty) error {
+	return s.module.ConfigChanged(args.Key, args.Value)
+}
+
+func (s *moduleServer) Dispose(_ Empty, _ *Empty) error {
+	return s.module.Dispose()
+}
+
+// Request delivers an attribute request to the channel the module registered the attribute with
+func (s *moduleServer) Request(attribute domain.Attribute, _ *Empty) error {
+	channel, ok := s.remote.channel(attribute.Id)
+	if !ok {
+		return fmt.Errorf("attribute '%s' was not registered by this module", attribute.Key)
+	}
+	select {
+	case channel <- attribute:
+		return nil
+	case <-time.After(time.Second * 5):
+		return fmt.Errorf("module did not accept request for '%s'", attribute.Key)
+	}
+}
+
+// remote holds the module's connection to the host's services
+type remote struct {
+	client *rpc.Client
+	hooks  map[string]chan domain.Attribute
+	mutex  sync.Mutex
+}
+
+func newRemote(client *rpc.Client) *remote {
+	return &remote{
+		client: client,
+		hooks:  map[string]chan domain.Attribute{},
+	}
+}
+
+func (r *remote) call(method string, args any, reply any) error {
+	return r.client.Call("Host."+method, args, reply)
+}
+
+func (r *remote) hook(id string, channel chan domain.Attribute) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.hooks[id] = channel
+}
+
+func (r *remote) channel(id string) (chan domain.Attribute, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	channel, ok := r.hooks[id]
+	return channel, ok
+}
@@ -0,0 +1,1069 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"io"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// The services below implement every method of their ports by refusing it. The remote services embed them and
+// override the methods the host serves, so any other method, including those later added to a port, returns an
+// error in the module process instead of panicking.
+
+// unsupported refuses the methods of a single service
+type unsupported struct {
+	service string
+}
+
+// refuse returns the error for a method the host does not serve
+func (u unsupported) refuse(method string) error {
+	return fmt.Errorf("%s.%s is unsupported over the process runtime", u.service, method)
+}
+
+type unsupportedAttributes struct {
+	unsupported
+}
+
+func (u *unsupportedAttributes) Create(*domain.Attribute) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedAttributes) Delete(*domain.Attribute) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedAttributes) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedAttributes) FindAll() (*[]domain.Attribute, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedAttributes) FindAllByEntity(string) (*[]domain.Attribute, error) {
+	return nil, u.refuse("FindAllByEntity")
+}
+
+func (u *unsupportedAttributes) FindByComposite(string, string) (*domain.Attribute, error) {
+	return nil, u.refuse("FindByComposite")
+}
+
+func (u *unsupportedAttributes) FindById(string) (*domain.Attribute, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedAttributes) Queues() []domain.DispatchQueue {
+	_ = u.refuse("Queues")
+	return nil
+}
+
+func (u *unsupportedAttributes) Register(*domain.Attribute) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedAttributes) Request(string, string, string) error {
+	return u.refuse("Request")
+}
+
+func (u *unsupportedAttributes) RequestContext(context.Context, string, string, string) error {
+	return u.refuse("RequestContext")
+}
+
+func (u *unsupportedAttributes) Set(string, string, string) error {
+	return u.refuse("Set")
+}
+
+func (u *unsupportedAttributes) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedAttributes) Summary(string, int64, int64, int, string) (map[int64]float64, error) {
+	return nil, u.refuse("Summary")
+}
+
+func (u *unsupportedAttributes) Update(string, string, string, time.Time) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedAttributes) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedDevices struct {
+	unsupported
+}
+
+func (u *unsupportedDevices) Create(*domain.Device) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedDevices) Delete(*domain.Device) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedDevices) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedDevices) FindAll() (*[]domain.Device, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedDevices) FindById(string) (*domain.Device, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedDevices) FindOrCreate(*domain.Device) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedDevices) Ping(string, time.Duration) error {
+	return u.refuse("Ping")
+}
+
+func (u *unsupportedDevices) Register(*domain.Device) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedDevices) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedDevices) Update(*domain.Device) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedDevices) Utilization(string, domain.Utilization) error {
+	return u.refuse("Utilization")
+}
+
+func (u *unsupportedDevices) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedEntities struct {
+	unsupported
+}
+
+func (u *unsupportedEntities) ChangeAlias(string, string) error {
+	return u.refuse("ChangeAlias")
+}
+
+func (u *unsupportedEntities) ChangeIcon(string, string) error {
+	return u.refuse("ChangeIcon")
+}
+
+func (u *unsupportedEntities) Config(string, string) error {
+	return u.refuse("Config")
+}
+
+func (u *unsupportedEntities) Create(*domain.Entity) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedEntities) Delete(*domain.Entity) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedEntities) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedEntities) FindAll() (*[]domain.Entity, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedEntities) FindAllByModule(string) (*[]domain.Entity, error) {
+	return nil, u.refuse("FindAllByModule")
+}
+
+func (u *unsupportedEntities) FindById(string) (*domain.Entity, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedEntities) FindByName(string) (*domain.Entity, error) {
+	return nil, u.refuse("FindByName")
+}
+
+func (u *unsupportedEntities) FindOrCreate(*domain.Entity) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedEntities) Register(*domain.Entity) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedEntities) SetPrediction(string, string) error {
+	return u.refuse("SetPrediction")
+}
+
+func (u *unsupportedEntities) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedEntities) Update(*domain.Entity) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedEntities) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedNetworks struct {
+	unsupported
+}
+
+func (u *unsupportedNetworks) Create(*domain.Network) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedNetworks) Delete(*domain.Network) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedNetworks) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedNetworks) FindAll() (*[]domain.Network, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedNetworks) FindById(string) (*domain.Network, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedNetworks) FindOrCreate(*domain.Network) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedNetworks) Register(*domain.Network) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedNetworks) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedNetworks) Update(*domain.Network) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedNetworks) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedLogs struct {
+	unsupported
+}
+
+func (u *unsupportedLogs) Create(*domain.Log) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedLogs) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedLogs) Prune() error {
+	return u.refuse("Prune")
+}
+
+func (u *unsupportedLogs) Query(domain.LogFilter) (*domain.LogPage, error) {
+	return nil, u.refuse("Query")
+}
+
+func (u *unsupportedLogs) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedLogs) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedNotifications struct {
+	unsupported
+}
+
+func (u *unsupportedNotifications) Create(*domain.Notification) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedNotifications) Delete(*domain.Notification) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedNotifications) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedNotifications) FindAll() (*[]domain.Notification, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedNotifications) FindById(string) (*domain.Notification, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedNotifications) FindOrCreate(*domain.Notification) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedNotifications) Register(*domain.Notification) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedNotifications) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedNotifications) Update(*domain.Notification) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedNotifications) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedUsers struct {
+	unsupported
+}
+
+func (u *unsupportedUsers) Authenticate(*domain.User) error {
+	return u.refuse("Authenticate")
+}
+
+func (u *unsupportedUsers) Create(*domain.User) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedUsers) Delete(*domain.User) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedUsers) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedUsers) FindAll() (*[]domain.User, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedUsers) FindById(string) (*domain.User, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedUsers) FindOrCreate(*domain.User) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedUsers) Register(*domain.User) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedUsers) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedUsers) Update(*domain.User) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedUsers) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedZones struct {
+	unsupported
+}
+
+func (u *unsupportedZones) AddEntity(string, string) error {
+	return u.refuse("AddEntity")
+}
+
+func (u *unsupportedZones) Create(*domain.Zone) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedZones) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedZones) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedZones) FindAll() (*[]domain.Zone, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedZones) FindById(string) (*domain.Zone, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedZones) FindByName(string) (*domain.Zone, error) {
+	return nil, u.refuse("FindByName")
+}
+
+func (u *unsupportedZones) FindOrCreate(*domain.Zone) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedZones) Pin(string) error {
+	return u.refuse("Pin")
+}
+
+func (u *unsupportedZones) RemoveEntity(string, string) error {
+	return u.refuse("RemoveEntity")
+}
+
+func (u *unsupportedZones) Restore(string) error {
+	return u.refuse("Restore")
+}
+
+func (u *unsupportedZones) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedZones) Unpin(string) error {
+	return u.refuse("Unpin")
+}
+
+func (u *unsupportedZones) Update(*domain.Zone) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedZones) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedEndpoints struct {
+	unsupported
+}
+
+func (u *unsupportedEndpoints) CloseAll() error {
+	return u.refuse("CloseAll")
+}
+
+func (u *unsupportedEndpoints) Create(*domain.Endpoint) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedEndpoints) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedEndpoints) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedEndpoints) Enroll(string, *websocket.Conn) error {
+	return u.refuse("Enroll")
+}
+
+func (u *unsupportedEndpoints) Execute(string, domain.Command) {
+	_ = u.refuse("Execute")
+}
+
+func (u *unsupportedEndpoints) FindAll() (*[]domain.Endpoint, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedEndpoints) FindById(string) (*domain.Endpoint, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedEndpoints) FindByKey(string) (*domain.Endpoint, error) {
+	return nil, u.refuse("FindByKey")
+}
+
+func (u *unsupportedEndpoints) FindOrCreate(*domain.Endpoint) error {
+	return u.refuse("FindOrCreate")
+}
+
+func (u *unsupportedEndpoints) RegisterPush(string, string) error {
+	return u.refuse("RegisterPush")
+}
+
+func (u *unsupportedEndpoints) Resync() {
+	_ = u.refuse("Resync")
+}
+
+func (u *unsupportedEndpoints) Send(string, string, interface{}) error {
+	return u.refuse("Send")
+}
+
+func (u *unsupportedEndpoints) SendAll(string, string, interface{}) error {
+	return u.refuse("SendAll")
+}
+
+func (u *unsupportedEndpoints) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedEndpoints) Stats() []domain.EndpointStats {
+	_ = u.refuse("Stats")
+	return nil
+}
+
+func (u *unsupportedEndpoints) Subscribe(string, string, domain.Subscription) error {
+	return u.refuse("Subscribe")
+}
+
+func (u *unsupportedEndpoints) Unenroll(string, *websocket.Conn) error {
+	return u.refuse("Unenroll")
+}
+
+func (u *unsupportedEndpoints) Unsubscribe(string, string) error {
+	return u.refuse("Unsubscribe")
+}
+
+func (u *unsupportedEndpoints) Update(*domain.Endpoint) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedEndpoints) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedModules struct {
+	unsupported
+}
+
+func (u *unsupportedModules) Build(string) error {
+	return u.refuse("Build")
+}
+
+func (u *unsupportedModules) BuildAll() error {
+	return u.refuse("BuildAll")
+}
+
+func (u *unsupportedModules) Configure(string, string, string) error {
+	return u.refuse("Configure")
+}
+
+func (u *unsupportedModules) Disable(string) error {
+	return u.refuse("Disable")
+}
+
+func (u *unsupportedModules) Discover() error {
+	return u.refuse("Discover")
+}
+
+func (u *unsupportedModules) Dispose(string) error {
+	return u.refuse("Dispose")
+}
+
+func (u *unsupportedModules) DisposeAll() error {
+	return u.refuse("DisposeAll")
+}
+
+func (u *unsupportedModules) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedModules) Enable(string) error {
+	return u.refuse("Enable")
+}
+
+func (u *unsupportedModules) Fault(string, string) error {
+	return u.refuse("Fault")
+}
+
+func (u *unsupportedModules) FindAll() (*[]domain.Module, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedModules) FindByName(string) (*domain.Module, error) {
+	return nil, u.refuse("FindByName")
+}
+
+func (u *unsupportedModules) GetConfig(string, string) (string, error) {
+	return "", u.refuse("GetConfig")
+}
+
+func (u *unsupportedModules) Halt(string) error {
+	return u.refuse("Halt")
+}
+
+func (u *unsupportedModules) HandleEmits(domain.Mutation) error {
+	return u.refuse("HandleEmits")
+}
+
+func (u *unsupportedModules) Health(string) (domain.ModuleHealth, error) {
+	return domain.ModuleHealth{}, u.refuse("Health")
+}
+
+func (u *unsupportedModules) InitConfig(string, string, string) error {
+	return u.refuse("InitConfig")
+}
+
+func (u *unsupportedModules) Install(string) (*domain.Module, error) {
+	return nil, u.refuse("Install")
+}
+
+func (u *unsupportedModules) Load(string) error {
+	return u.refuse("Load")
+}
+
+func (u *unsupportedModules) LoadAll() error {
+	return u.refuse("LoadAll")
+}
+
+func (u *unsupportedModules) Reload(string) error {
+	return u.refuse("Reload")
+}
+
+func (u *unsupportedModules) Rollback(string) error {
+	return u.refuse("Rollback")
+}
+
+func (u *unsupportedModules) Run(string) error {
+	return u.refuse("Run")
+}
+
+func (u *unsupportedModules) RunAll() error {
+	return u.refuse("RunAll")
+}
+
+func (u *unsupportedModules) Schedule(string) (domain.ModuleSchedule, error) {
+	return domain.ModuleSchedule{}, u.refuse("Schedule")
+}
+
+func (u *unsupportedModules) Schedules() []domain.ModuleSchedule {
+	_ = u.refuse("Schedules")
+	return nil
+}
+
+func (u *unsupportedModules) SetConfig(string, string, string) error {
+	return u.refuse("SetConfig")
+}
+
+func (u *unsupportedModules) SetRuntime(string, string) error {
+	return u.refuse("SetRuntime")
+}
+
+func (u *unsupportedModules) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedModules) Stage(io.Reader) (domain.Manifest, error) {
+	return domain.Manifest{}, u.refuse("Stage")
+}
+
+func (u *unsupportedModules) Subscribe(string, domain.MutationFilter) error {
+	return u.refuse("Subscribe")
+}
+
+func (u *unsupportedModules) Supervise() {
+	_ = u.refuse("Supervise")
+}
+
+func (u *unsupportedModules) Update(string) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedModules) UpdateAll() error {
+	return u.refuse("UpdateAll")
+}
+
+func (u *unsupportedModules) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedMacros struct {
+	unsupported
+}
+
+func (u *unsupportedMacros) Create(*domain.Macro) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedMacros) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedMacros) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedMacros) FindAll() (*[]domain.Macro, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedMacros) FindById(string) (*domain.Macro, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedMacros) Run(string, domain.Source) error {
+	return u.refuse("Run")
+}
+
+func (u *unsupportedMacros) RunAndRevert(string, domain.Source, time.Duration) error {
+	return u.refuse("RunAndRevert")
+}
+
+func (u *unsupportedMacros) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedMacros) Update(*domain.Macro) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedMacros) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedTriggers struct {
+	unsupported
+}
+
+func (u *unsupportedTriggers) Create(*domain.Trigger) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedTriggers) Delete(*domain.Trigger) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedTriggers) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedTriggers) FindAll() (*[]domain.Trigger, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedTriggers) FindById(string) (*domain.Trigger, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedTriggers) Register(*domain.Trigger) error {
+	return u.refuse("Register")
+}
+
+func (u *unsupportedTriggers) Schedule(string, domain.Schedule) error {
+	return u.refuse("Schedule")
+}
+
+func (u *unsupportedTriggers) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedTriggers) StartSchedules() error {
+	return u.refuse("StartSchedules")
+}
+
+func (u *unsupportedTriggers) Trigger(string) error {
+	return u.refuse("Trigger")
+}
+
+func (u *unsupportedTriggers) TriggerCustom(string, string, string) error {
+	return u.refuse("TriggerCustom")
+}
+
+func (u *unsupportedTriggers) TriggerFrom(string, domain.Source) error {
+	return u.refuse("TriggerFrom")
+}
+
+func (u *unsupportedTriggers) Update(*domain.Trigger) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedTriggers) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedSubRoutines struct {
+	unsupported
+}
+
+func (u *unsupportedSubRoutines) AddMacro(string, string) error {
+	return u.refuse("AddMacro")
+}
+
+func (u *unsupportedSubRoutines) AddScene(string, string) error {
+	return u.refuse("AddScene")
+}
+
+func (u *unsupportedSubRoutines) Create(*domain.SubRoutine) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedSubRoutines) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedSubRoutines) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedSubRoutines) FindById(string) (*domain.SubRoutine, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedSubRoutines) RemoveMacro(string, string) error {
+	return u.refuse("RemoveMacro")
+}
+
+func (u *unsupportedSubRoutines) RemoveScene(string, string) error {
+	return u.refuse("RemoveScene")
+}
+
+func (u *unsupportedSubRoutines) Run(string, domain.Source) error {
+	return u.refuse("Run")
+}
+
+func (u *unsupportedSubRoutines) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedSubRoutines) TriggerById(string, domain.Source) error {
+	return u.refuse("TriggerById")
+}
+
+func (u *unsupportedSubRoutines) Update(*domain.SubRoutine) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedSubRoutines) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedActions struct {
+	unsupported
+}
+
+func (u *unsupportedActions) Create(*domain.Action) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedActions) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedActions) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedActions) ExecuteById(string, domain.Source) error {
+	return u.refuse("ExecuteById")
+}
+
+func (u *unsupportedActions) ExecuteCustomById(string, string, string, domain.Source) error {
+	return u.refuse("ExecuteCustomById")
+}
+
+func (u *unsupportedActions) FindAll() (*[]domain.Action, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedActions) FindById(string) (*domain.Action, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedActions) FindByTriggerId(string) (*[]domain.Action, error) {
+	return nil, u.refuse("FindByTriggerId")
+}
+
+func (u *unsupportedActions) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedActions) Update(*domain.Action) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedActions) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedRules struct {
+	unsupported
+}
+
+func (u *unsupportedRules) Create(*domain.Rule) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedRules) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedRules) Disable(string) error {
+	return u.refuse("Disable")
+}
+
+func (u *unsupportedRules) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedRules) Enable(string) error {
+	return u.refuse("Enable")
+}
+
+func (u *unsupportedRules) FindAll() (*[]domain.Rule, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedRules) FindById(string) (*domain.Rule, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedRules) HandleMutation(domain.Mutation) error {
+	return u.refuse("HandleMutation")
+}
+
+func (u *unsupportedRules) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedRules) Update(*domain.Rule) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedRules) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedHolds struct {
+	unsupported
+}
+
+func (u *unsupportedHolds) Cancel(string) error {
+	return u.refuse("Cancel")
+}
+
+func (u *unsupportedHolds) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedHolds) FindAll() (*[]domain.Hold, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedHolds) FindById(string) (*domain.Hold, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedHolds) HandleMutation(domain.Mutation) error {
+	return u.refuse("HandleMutation")
+}
+
+func (u *unsupportedHolds) Hold(string, string, domain.Attribute, string, time.Duration) error {
+	return u.refuse("Hold")
+}
+
+func (u *unsupportedHolds) Release(string) error {
+	return u.refuse("Release")
+}
+
+func (u *unsupportedHolds) Resume() error {
+	return u.refuse("Resume")
+}
+
+func (u *unsupportedHolds) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedHolds) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedScenes struct {
+	unsupported
+}
+
+func (u *unsupportedScenes) Apply(string, domain.Source) error {
+	return u.refuse("Apply")
+}
+
+func (u *unsupportedScenes) ApplyWithTransition(string, time.Duration, domain.Source) error {
+	return u.refuse("ApplyWithTransition")
+}
+
+func (u *unsupportedScenes) Capture(string, string, []string) (*domain.Scene, error) {
+	return nil, u.refuse("Capture")
+}
+
+func (u *unsupportedScenes) Create(*domain.Scene) error {
+	return u.refuse("Create")
+}
+
+func (u *unsupportedScenes) Delete(string) error {
+	return u.refuse("Delete")
+}
+
+func (u *unsupportedScenes) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedScenes) FindAll() (*[]domain.Scene, error) {
+	return nil, u.refuse("FindAll")
+}
+
+func (u *unsupportedScenes) FindById(string) (*domain.Scene, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedScenes) Recapture(string) error {
+	return u.refuse("Recapture")
+}
+
+func (u *unsupportedScenes) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedScenes) Update(*domain.Scene) error {
+	return u.refuse("Update")
+}
+
+func (u *unsupportedScenes) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
+
+type unsupportedInvocations struct {
+	unsupported
+}
+
+func (u *unsupportedInvocations) Begin(string, string, string, domain.Source) (*domain.Invocation, error) {
+	return nil, u.refuse("Begin")
+}
+
+func (u *unsupportedInvocations) EmitAll() error {
+	return u.refuse("EmitAll")
+}
+
+func (u *unsupportedInvocations) FindById(string) (*domain.Invocation, error) {
+	return nil, u.refuse("FindById")
+}
+
+func (u *unsupportedInvocations) Finish(*domain.Invocation, error) error {
+	return u.refuse("Finish")
+}
+
+func (u *unsupportedInvocations) Query(domain.InvocationFilter) (*domain.InvocationPage, error) {
+	return nil, u.refuse("Query")
+}
+
+func (u *unsupportedInvocations) Record(*domain.Invocation) error {
+	return u.refuse("Record")
+}
+
+func (u *unsupportedInvocations) Request(string, string, string, string, error) error {
+	return u.refuse("Request")
+}
+
+func (u *unsupportedInvocations) Snapshot() ([]domain.Mutation, error) {
+	return nil, u.refuse("Snapshot")
+}
+
+func (u *unsupportedInvocations) Watch(chan<- domain.Mutation) {
+	_ = u.refuse("Watch")
+}
@@ -0,0 +1,1069 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package scope
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"io"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// The services below implement every method of their ports by refusing it. The services a module may use embed
+// them and override the methods the module is permitted to call, so any other method, including those later added
+// to a port, is refused and logged against the module instead of reaching the system's services.
+
+// denied refuses the methods of a single service
+type denied struct {
+	scope   *Scope
+	service string
+}
+
+// deny logs the call as a violation and returns the error given to the module
+func (d denied) deny(method string) error {
+	return d.scope.violation("call %s.%s", d.service, method)
+}
+
+type deniedAttributes struct {
+	denied
+}
+
+func (d *deniedAttributes) Create(*domain.Attribute) error {
+	return d.deny("Create")
+}
+
+func (d *deniedAttributes) Delete(*domain.Attribute) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedAttributes) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedAttributes) FindAll() (*[]domain.Attribute, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedAttributes) FindAllByEntity(string) (*[]domain.Attribute, error) {
+	return nil, d.deny("FindAllByEntity")
+}
+
+func (d *deniedAttributes) FindByComposite(string, string) (*domain.Attribute, error) {
+	return nil, d.deny("FindByComposite")
+}
+
+func (d *deniedAttributes) FindById(string) (*domain.Attribute, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedAttributes) Queues() []domain.DispatchQueue {
+	_ = d.deny("Queues")
+	return nil
+}
+
+func (d *deniedAttributes) Register(*domain.Attribute) error {
+	return d.deny("Register")
+}
+
+func (d *deniedAttributes) Request(string, string, string) error {
+	return d.deny("Request")
+}
+
+func (d *deniedAttributes) RequestContext(context.Context, string, string, string) error {
+	return d.deny("RequestContext")
+}
+
+func (d *deniedAttributes) Set(string, string, string) error {
+	return d.deny("Set")
+}
+
+func (d *deniedAttributes) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedAttributes) Summary(string, int64, int64, int, string) (map[int64]float64, error) {
+	return nil, d.deny("Summary")
+}
+
+func (d *deniedAttributes) Update(string, string, string, time.Time) error {
+	return d.deny("Update")
+}
+
+func (d *deniedAttributes) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedDevices struct {
+	denied
+}
+
+func (d *deniedDevices) Create(*domain.Device) error {
+	return d.deny("Create")
+}
+
+func (d *deniedDevices) Delete(*domain.Device) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedDevices) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedDevices) FindAll() (*[]domain.Device, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedDevices) FindById(string) (*domain.Device, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedDevices) FindOrCreate(*domain.Device) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedDevices) Ping(string, time.Duration) error {
+	return d.deny("Ping")
+}
+
+func (d *deniedDevices) Register(*domain.Device) error {
+	return d.deny("Register")
+}
+
+func (d *deniedDevices) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedDevices) Update(*domain.Device) error {
+	return d.deny("Update")
+}
+
+func (d *deniedDevices) Utilization(string, domain.Utilization) error {
+	return d.deny("Utilization")
+}
+
+func (d *deniedDevices) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedEntities struct {
+	denied
+}
+
+func (d *deniedEntities) ChangeAlias(string, string) error {
+	return d.deny("ChangeAlias")
+}
+
+func (d *deniedEntities) ChangeIcon(string, string) error {
+	return d.deny("ChangeIcon")
+}
+
+func (d *deniedEntities) Config(string, string) error {
+	return d.deny("Config")
+}
+
+func (d *deniedEntities) Create(*domain.Entity) error {
+	return d.deny("Create")
+}
+
+func (d *deniedEntities) Delete(*domain.Entity) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedEntities) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedEntities) FindAll() (*[]domain.Entity, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedEntities) FindAllByModule(string) (*[]domain.Entity, error) {
+	return nil, d.deny("FindAllByModule")
+}
+
+func (d *deniedEntities) FindById(string) (*domain.Entity, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedEntities) FindByName(string) (*domain.Entity, error) {
+	return nil, d.deny("FindByName")
+}
+
+func (d *deniedEntities) FindOrCreate(*domain.Entity) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedEntities) Register(*domain.Entity) error {
+	return d.deny("Register")
+}
+
+func (d *deniedEntities) SetPrediction(string, string) error {
+	return d.deny("SetPrediction")
+}
+
+func (d *deniedEntities) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedEntities) Update(*domain.Entity) error {
+	return d.deny("Update")
+}
+
+func (d *deniedEntities) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedNetworks struct {
+	denied
+}
+
+func (d *deniedNetworks) Create(*domain.Network) error {
+	return d.deny("Create")
+}
+
+func (d *deniedNetworks) Delete(*domain.Network) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedNetworks) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedNetworks) FindAll() (*[]domain.Network, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedNetworks) FindById(string) (*domain.Network, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedNetworks) FindOrCreate(*domain.Network) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedNetworks) Register(*domain.Network) error {
+	return d.deny("Register")
+}
+
+func (d *deniedNetworks) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedNetworks) Update(*domain.Network) error {
+	return d.deny("Update")
+}
+
+func (d *deniedNetworks) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedLogs struct {
+	denied
+}
+
+func (d *deniedLogs) Create(*domain.Log) error {
+	return d.deny("Create")
+}
+
+func (d *deniedLogs) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedLogs) Prune() error {
+	return d.deny("Prune")
+}
+
+func (d *deniedLogs) Query(domain.LogFilter) (*domain.LogPage, error) {
+	return nil, d.deny("Query")
+}
+
+func (d *deniedLogs) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedLogs) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedNotifications struct {
+	denied
+}
+
+func (d *deniedNotifications) Create(*domain.Notification) error {
+	return d.deny("Create")
+}
+
+func (d *deniedNotifications) Delete(*domain.Notification) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedNotifications) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedNotifications) FindAll() (*[]domain.Notification, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedNotifications) FindById(string) (*domain.Notification, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedNotifications) FindOrCreate(*domain.Notification) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedNotifications) Register(*domain.Notification) error {
+	return d.deny("Register")
+}
+
+func (d *deniedNotifications) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedNotifications) Update(*domain.Notification) error {
+	return d.deny("Update")
+}
+
+func (d *deniedNotifications) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedUsers struct {
+	denied
+}
+
+func (d *deniedUsers) Authenticate(*domain.User) error {
+	return d.deny("Authenticate")
+}
+
+func (d *deniedUsers) Create(*domain.User) error {
+	return d.deny("Create")
+}
+
+func (d *deniedUsers) Delete(*domain.User) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedUsers) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedUsers) FindAll() (*[]domain.User, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedUsers) FindById(string) (*domain.User, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedUsers) FindOrCreate(*domain.User) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedUsers) Register(*domain.User) error {
+	return d.deny("Register")
+}
+
+func (d *deniedUsers) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedUsers) Update(*domain.User) error {
+	return d.deny("Update")
+}
+
+func (d *deniedUsers) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedZones struct {
+	denied
+}
+
+func (d *deniedZones) AddEntity(string, string) error {
+	return d.deny("AddEntity")
+}
+
+func (d *deniedZones) Create(*domain.Zone) error {
+	return d.deny("Create")
+}
+
+func (d *deniedZones) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedZones) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedZones) FindAll() (*[]domain.Zone, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedZones) FindById(string) (*domain.Zone, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedZones) FindByName(string) (*domain.Zone, error) {
+	return nil, d.deny("FindByName")
+}
+
+func (d *deniedZones) FindOrCreate(*domain.Zone) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedZones) Pin(string) error {
+	return d.deny("Pin")
+}
+
+func (d *deniedZones) RemoveEntity(string, string) error {
+	return d.deny("RemoveEntity")
+}
+
+func (d *deniedZones) Restore(string) error {
+	return d.deny("Restore")
+}
+
+func (d *deniedZones) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedZones) Unpin(string) error {
+	return d.deny("Unpin")
+}
+
+func (d *deniedZones) Update(*domain.Zone) error {
+	return d.deny("Update")
+}
+
+func (d *deniedZones) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedEndpoints struct {
+	denied
+}
+
+func (d *deniedEndpoints) CloseAll() error {
+	return d.deny("CloseAll")
+}
+
+func (d *deniedEndpoints) Create(*domain.Endpoint) error {
+	return d.deny("Create")
+}
+
+func (d *deniedEndpoints) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedEndpoints) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedEndpoints) Enroll(string, *websocket.Conn) error {
+	return d.deny("Enroll")
+}
+
+func (d *deniedEndpoints) Execute(string, domain.Command) {
+	_ = d.deny("Execute")
+}
+
+func (d *deniedEndpoints) FindAll() (*[]domain.Endpoint, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedEndpoints) FindById(string) (*domain.Endpoint, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedEndpoints) FindByKey(string) (*domain.Endpoint, error) {
+	return nil, d.deny("FindByKey")
+}
+
+func (d *deniedEndpoints) FindOrCreate(*domain.Endpoint) error {
+	return d.deny("FindOrCreate")
+}
+
+func (d *deniedEndpoints) RegisterPush(string, string) error {
+	return d.deny("RegisterPush")
+}
+
+func (d *deniedEndpoints) Resync() {
+	_ = d.deny("Resync")
+}
+
+func (d *deniedEndpoints) Send(string, string, interface{}) error {
+	return d.deny("Send")
+}
+
+func (d *deniedEndpoints) SendAll(string, string, interface{}) error {
+	return d.deny("SendAll")
+}
+
+func (d *deniedEndpoints) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedEndpoints) Stats() []domain.EndpointStats {
+	_ = d.deny("Stats")
+	return nil
+}
+
+func (d *deniedEndpoints) Subscribe(string, string, domain.Subscription) error {
+	return d.deny("Subscribe")
+}
+
+func (d *deniedEndpoints) Unenroll(string, *websocket.Conn) error {
+	return d.deny("Unenroll")
+}
+
+func (d *deniedEndpoints) Unsubscribe(string, string) error {
+	return d.deny("Unsubscribe")
+}
+
+func (d *deniedEndpoints) Update(*domain.Endpoint) error {
+	return d.deny("Update")
+}
+
+func (d *deniedEndpoints) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedModules struct {
+	denied
+}
+
+func (d *deniedModules) Build(string) error {
+	return d.deny("Build")
+}
+
+func (d *deniedModules) BuildAll() error {
+	return d.deny("BuildAll")
+}
+
+func (d *deniedModules) Configure(string, string, string) error {
+	return d.deny("Configure")
+}
+
+func (d *deniedModules) Disable(string) error {
+	return d.deny("Disable")
+}
+
+func (d *deniedModules) Discover() error {
+	return d.deny("Discover")
+}
+
+func (d *deniedModules) Dispose(string) error {
+	return d.deny("Dispose")
+}
+
+func (d *deniedModules) DisposeAll() error {
+	return d.deny("DisposeAll")
+}
+
+func (d *deniedModules) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedModules) Enable(string) error {
+	return d.deny("Enable")
+}
+
+func (d *deniedModules) Fault(string, string) error {
+	return d.deny("Fault")
+}
+
+func (d *deniedModules) FindAll() (*[]domain.Module, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedModules) FindByName(string) (*domain.Module, error) {
+	return nil, d.deny("FindByName")
+}
+
+func (d *deniedModules) GetConfig(string, string) (string, error) {
+	return "", d.deny("GetConfig")
+}
+
+func (d *deniedModules) Halt(string) error {
+	return d.deny("Halt")
+}
+
+func (d *deniedModules) HandleEmits(domain.Mutation) error {
+	return d.deny("HandleEmits")
+}
+
+func (d *deniedModules) Health(string) (domain.ModuleHealth, error) {
+	return domain.ModuleHealth{}, d.deny("Health")
+}
+
+func (d *deniedModules) InitConfig(string, string, string) error {
+	return d.deny("InitConfig")
+}
+
+func (d *deniedModules) Install(string) (*domain.Module, error) {
+	return nil, d.deny("Install")
+}
+
+func (d *deniedModules) Load(string) error {
+	return d.deny("Load")
+}
+
+func (d *deniedModules) LoadAll() error {
+	return d.deny("LoadAll")
+}
+
+func (d *deniedModules) Reload(string) error {
+	return d.deny("Reload")
+}
+
+func (d *deniedModules) Rollback(string) error {
+	return d.deny("Rollback")
+}
+
+func (d *deniedModules) Run(string) error {
+	return d.deny("Run")
+}
+
+func (d *deniedModules) RunAll() error {
+	return d.deny("RunAll")
+}
+
+func (d *deniedModules) Schedule(string) (domain.ModuleSchedule, error) {
+	return domain.ModuleSchedule{}, d.deny("Schedule")
+}
+
+func (d *deniedModules) Schedules() []domain.ModuleSchedule {
+	_ = d.deny("Schedules")
+	return nil
+}
+
+func (d *deniedModules) SetConfig(string, string, string) error {
+	return d.deny("SetConfig")
+}
+
+func (d *deniedModules) SetRuntime(string, string) error {
+	return d.deny("SetRuntime")
+}
+
+func (d *deniedModules) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedModules) Stage(io.Reader) (domain.Manifest, error) {
+	return domain.Manifest{}, d.deny("Stage")
+}
+
+func (d *deniedModules) Subscribe(string, domain.MutationFilter) error {
+	return d.deny("Subscribe")
+}
+
+func (d *deniedModules) Supervise() {
+	_ = d.deny("Supervise")
+}
+
+func (d *deniedModules) Update(string) error {
+	return d.deny("Update")
+}
+
+func (d *deniedModules) UpdateAll() error {
+	return d.deny("UpdateAll")
+}
+
+func (d *deniedModules) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedMacros struct {
+	denied
+}
+
+func (d *deniedMacros) Create(*domain.Macro) error {
+	return d.deny("Create")
+}
+
+func (d *deniedMacros) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedMacros) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedMacros) FindAll() (*[]domain.Macro, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedMacros) FindById(string) (*domain.Macro, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedMacros) Run(string, domain.Source) error {
+	return d.deny("Run")
+}
+
+func (d *deniedMacros) RunAndRevert(string, domain.Source, time.Duration) error {
+	return d.deny("RunAndRevert")
+}
+
+func (d *deniedMacros) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedMacros) Update(*domain.Macro) error {
+	return d.deny("Update")
+}
+
+func (d *deniedMacros) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedTriggers struct {
+	denied
+}
+
+func (d *deniedTriggers) Create(*domain.Trigger) error {
+	return d.deny("Create")
+}
+
+func (d *deniedTriggers) Delete(*domain.Trigger) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedTriggers) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedTriggers) FindAll() (*[]domain.Trigger, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedTriggers) FindById(string) (*domain.Trigger, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedTriggers) Register(*domain.Trigger) error {
+	return d.deny("Register")
+}
+
+func (d *deniedTriggers) Schedule(string, domain.Schedule) error {
+	return d.deny("Schedule")
+}
+
+func (d *deniedTriggers) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedTriggers) StartSchedules() error {
+	return d.deny("StartSchedules")
+}
+
+func (d *deniedTriggers) Trigger(string) error {
+	return d.deny("Trigger")
+}
+
+func (d *deniedTriggers) TriggerCustom(string, string, string) error {
+	return d.deny("TriggerCustom")
+}
+
+func (d *deniedTriggers) TriggerFrom(string, domain.Source) error {
+	return d.deny("TriggerFrom")
+}
+
+func (d *deniedTriggers) Update(*domain.Trigger) error {
+	return d.deny("Update")
+}
+
+func (d *deniedTriggers) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedSubRoutines struct {
+	denied
+}
+
+func (d *deniedSubRoutines) AddMacro(string, string) error {
+	return d.deny("AddMacro")
+}
+
+func (d *deniedSubRoutines) AddScene(string, string) error {
+	return d.deny("AddScene")
+}
+
+func (d *deniedSubRoutines) Create(*domain.SubRoutine) error {
+	return d.deny("Create")
+}
+
+func (d *deniedSubRoutines) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedSubRoutines) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedSubRoutines) FindById(string) (*domain.SubRoutine, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedSubRoutines) RemoveMacro(string, string) error {
+	return d.deny("RemoveMacro")
+}
+
+func (d *deniedSubRoutines) RemoveScene(string, string) error {
+	return d.deny("RemoveScene")
+}
+
+func (d *deniedSubRoutines) Run(string, domain.Source) error {
+	return d.deny("Run")
+}
+
+func (d *deniedSubRoutines) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedSubRoutines) TriggerById(string, domain.Source) error {
+	return d.deny("TriggerById")
+}
+
+func (d *deniedSubRoutines) Update(*domain.SubRoutine) error {
+	return d.deny("Update")
+}
+
+func (d *deniedSubRoutines) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedActions struct {
+	denied
+}
+
+func (d *deniedActions) Create(*domain.Action) error {
+	return d.deny("Create")
+}
+
+func (d *deniedActions) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedActions) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedActions) ExecuteById(string, domain.Source) error {
+	return d.deny("ExecuteById")
+}
+
+func (d *deniedActions) ExecuteCustomById(string, string, string, domain.Source) error {
+	return d.deny("ExecuteCustomById")
+}
+
+func (d *deniedActions) FindAll() (*[]domain.Action, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedActions) FindById(string) (*domain.Action, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedActions) FindByTriggerId(string) (*[]domain.Action, error) {
+	return nil, d.deny("FindByTriggerId")
+}
+
+func (d *deniedActions) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedActions) Update(*domain.Action) error {
+	return d.deny("Update")
+}
+
+func (d *deniedActions) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedRules struct {
+	denied
+}
+
+func (d *deniedRules) Create(*domain.Rule) error {
+	return d.deny("Create")
+}
+
+func (d *deniedRules) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedRules) Disable(string) error {
+	return d.deny("Disable")
+}
+
+func (d *deniedRules) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedRules) Enable(string) error {
+	return d.deny("Enable")
+}
+
+func (d *deniedRules) FindAll() (*[]domain.Rule, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedRules) FindById(string) (*domain.Rule, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedRules) HandleMutation(domain.Mutation) error {
+	return d.deny("HandleMutation")
+}
+
+func (d *deniedRules) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedRules) Update(*domain.Rule) error {
+	return d.deny("Update")
+}
+
+func (d *deniedRules) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedHolds struct {
+	denied
+}
+
+func (d *deniedHolds) Cancel(string) error {
+	return d.deny("Cancel")
+}
+
+func (d *deniedHolds) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedHolds) FindAll() (*[]domain.Hold, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedHolds) FindById(string) (*domain.Hold, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedHolds) HandleMutation(domain.Mutation) error {
+	return d.deny("HandleMutation")
+}
+
+func (d *deniedHolds) Hold(string, string, domain.Attribute, string, time.Duration) error {
+	return d.deny("Hold")
+}
+
+func (d *deniedHolds) Release(string) error {
+	return d.deny("Release")
+}
+
+func (d *deniedHolds) Resume() error {
+	return d.deny("Resume")
+}
+
+func (d *deniedHolds) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedHolds) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedScenes struct {
+	denied
+}
+
+func (d *deniedScenes) Apply(string, domain.Source) error {
+	return d.deny("Apply")
+}
+
+func (d *deniedScenes) ApplyWithTransition(string, time.Duration, domain.Source) error {
+	return d.deny("ApplyWithTransition")
+}
+
+func (d *deniedScenes) Capture(string, string, []string) (*domain.Scene, error) {
+	return nil, d.deny("Capture")
+}
+
+func (d *deniedScenes) Create(*domain.Scene) error {
+	return d.deny("Create")
+}
+
+func (d *deniedScenes) Delete(string) error {
+	return d.deny("Delete")
+}
+
+func (d *deniedScenes) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedScenes) FindAll() (*[]domain.Scene, error) {
+	return nil, d.deny("FindAll")
+}
+
+func (d *deniedScenes) FindById(string) (*domain.Scene, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedScenes) Recapture(string) error {
+	return d.deny("Recapture")
+}
+
+func (d *deniedScenes) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedScenes) Update(*domain.Scene) error {
+	return d.deny("Update")
+}
+
+func (d *deniedScenes) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
+
+type deniedInvocations struct {
+	denied
+}
+
+func (d *deniedInvocations) Begin(string, string, string, domain.Source) (*domain.Invocation, error) {
+	return nil, d.deny("Begin")
+}
+
+func (d *deniedInvocations) EmitAll() error {
+	return d.deny("EmitAll")
+}
+
+func (d *deniedInvocations) FindById(string) (*domain.Invocation, error) {
+	return nil, d.deny("FindById")
+}
+
+func (d *deniedInvocations) Finish(*domain.Invocation, error) error {
+	return d.deny("Finish")
+}
+
+func (d *deniedInvocations) Query(domain.InvocationFilter) (*domain.InvocationPage, error) {
+	return nil, d.deny("Query")
+}
+
+func (d *deniedInvocations) Record(*domain.Invocation) error {
+	return d.deny("Record")
+}
+
+func (d *deniedInvocations) Request(string, string, string, string, error) error {
+	return d.deny("Request")
+}
+
+func (d *deniedInvocations) Snapshot() ([]domain.Mutation, error) {
+	return nil, d.deny("Snapshot")
+}
+
+func (d *deniedInvocations) Watch(chan<- domain.Mutation) {
+	_ = d.deny("Watch")
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package scope limits what a module can do through its controller. A module may register and update its own
+// entities and attributes, read the rest of the system, and fire the triggers it registered. Anything further
+// must be granted in the module's config. Attempts to go beyond the module's scope are refused and logged
+// against the module.
+package scope
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/log"
+)
+
+// owner identifies the module responsible for an entity
+type owner struct {
+	name   string
+	module string
+}
+
+// Scope holds the grants of a single module and the entities and triggers it owns
+type Scope struct {
+	ctrl     *controller.Controller
+	module   string
+	uuid     string
+	grants   []domain.Grant
+	entities map[string]owner
+	triggers map[string]bool
+	mutex    sync.RWMutex
+}
+
+func New(ctrl *controller.Controller, module string, uuid string) *Scope {
+	return &Scope{
+		ctrl:     ctrl,
+		module:   module,
+		uuid:     uuid,
+		grants:   []domain.Grant{},
+		entities: map[string]owner{},
+		triggers: map[string]bool{},
+		mutex:    sync.RWMutex{},
+	}
+}
+
+// Grant sets the capabilities the module declared in its config
+func (s *Scope) Grant(grants []domain.Grant) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.grants = grants
+}
+
+func (s *Scope) granted(capability string, targets ...string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return domain.Granted(s.grants, capability, targets...)
+}
+
+// Controller builds the controller handed to the module. Every service is provided, but only the methods
+// implemented in services.go are permitted, any other call is refused as a violation.
+func (s *Scope) Controller() *controller.Controller {
+	return &controller.Controller{
+		Attributes:    &attributes{deniedAttributes{s.refuse("Attributes")}},
+		Devices:       &devices{deniedDevices{s.refuse("Devices")}},
+		Entities:      &entities{deniedEntities{s.refuse("Entities")}},
+		Networks:      &networks{deniedNetworks{s.refuse("Networks")}},
+		Logs:          &logs{deniedLogs{s.refuse("Logs")}},
+		Notifications: &deniedNotifications{s.refuse("Notifications")},
+		Users:         &deniedUsers{s.refuse("Users")},
+		Zones:         &zones{deniedZones{s.refuse("Zones")}},
+		Endpoints:     &endpoints{deniedEndpoints{s.refuse("Endpoints")}},
+		Modules:       &modules{deniedModules{s.refuse("Modules")}},
+		Macros:        &deniedMacros{s.refuse("Macros")},
+		Triggers:      &triggers{deniedTriggers{s.refuse("Triggers")}},
+		SubRoutines:   &deniedSubRoutines{s.refuse("SubRoutines")},
+		Actions:       &deniedActions{s.refuse("Actions")},
+		Rules:         &deniedRules{s.refuse("Rules")},
+		Holds:         &deniedHolds{s.refuse("Holds")},
+		Scenes:        &deniedScenes{s.refuse("Scenes")},
+		Invocations:   &deniedInvocations{s.refuse("Invocations")},
+	}
+}
+
+// refuse builds the part of a denied service that reports its violations against the module
+func (s *Scope) refuse(service string) denied {
+	return denied{scope: s, service: service}
+}
+
+// violation logs an action the module is not permitted to take and returns the error given to the module
+func (s *Scope) violation(format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	log.Event("Module '%s' violation: %s", s.module, message)
+	err := s.ctrl.Logs.Create(&domain.Log{
+		Group:   "module",
+		Event:   "violation",
+		Level:   domain.WARN,
+		Module:  s.module,
+		Time:    time.Now(),
+		Message: message,
+	})
+	if err != nil {
+		log.Err(err)
+	}
+	return fmt.Errorf("module '%s' is not permitted to %s", s.module, message)
+}
+
+// own records an entity registered by the module
+func (s *Scope) own(entity domain.Entity) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entities[entity.Id] = owner{name: entity.Name, module: entity.Module}
+}
+
+// owner finds the module responsible for an entity, entities are looked up once
+func (s *Scope) owner(id string) (owner, error) {
+	s.mutex.RLock()
+	o, ok := s.entities[id]
+	s.mutex.RUnlock()
+	if ok {
+		return o, nil
+	}
+	entity, err := s.ctrl.Entities.FindById(id)
+	if err != nil {
+		return owner{}, err
+	}
+	o = owner{name: entity.Name, module: entity.Module}
+	s.mutex.Lock()
+	s.entities[id] = o
+	s.mutex.Unlock()
+	return o, nil
+}
+
+// owns reports whether the module registered an entity
+func (s *Scope) owns(id string) (bool, error) {
+	o, err := s.owner(id)
+	if err != nil {
+		return false, err
+	}
+	return o.module == s.module, nil
+}
+
+// control permits changes to the attributes of entities the module owns, or was granted control of
+func (s *Scope) control(id string, key string, action string) error {
+	o, err := s.owner(id)
+	if err != nil {
+		return err
+	}
+	if o.module == s.module || s.granted(domain.CONTROL, o.name, o.module) {
+		return nil
+	}
+	return s.violation("%s '%s' of entity '%s' owned by '%s'", action, key, o.name, o.module)
+}
+
+// fire permits the triggers the module registered, or was granted
+func (s *Scope) fire(name string) error {
+	s.mutex.RLock()
+	registered := s.triggers[name]
+	s.mutex.RUnlock()
+	if registered || s.granted(domain.FIRE, name) {
+		return nil
+	}
+	return s.violation("fire trigger '%s'", name)
+}
+
+// self permits calls concerning the module itself
+func (s *Scope) self(uuid string, action string) error {
+	if uuid == s.uuid {
+		return nil
+	}
+	return s.violation("%s of another module", action)
+}
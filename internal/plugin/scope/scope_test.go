@@ -0,0 +1,199 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package scope
+
+import (
+	"testing"
+	"udap/internal/controller"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type scopedEntities struct {
+	ports.EntityService
+	entities map[string]domain.Entity
+}
+
+func (e *scopedEntities) FindById(id string) (*domain.Entity, error) {
+	entity := e.entities[id]
+	return &entity, nil
+}
+
+func (e *scopedEntities) Register(entity *domain.Entity) error {
+	entity.Id = entity.Name
+	e.entities[entity.Id] = *entity
+	return nil
+}
+
+type scopedAttributes struct {
+	ports.AttributeService
+	set []string
+}
+
+func (a *scopedAttributes) Set(entity string, key string, _ string) error {
+	a.set = append(a.set, entity+"."+key)
+	return nil
+}
+
+type scopedTriggers struct {
+	ports.TriggerService
+	registered map[string]domain.Trigger
+	fired      []string
+}
+
+// Register finds or creates a trigger by name like the repository does
+func (t *scopedTriggers) Register(trigger *domain.Trigger) error {
+	if existing, ok := t.registered[trigger.Name]; ok {
+		*trigger = existing
+		return nil
+	}
+	t.registered[trigger.Name] = *trigger
+	return nil
+}
+
+func (t *scopedTriggers) TriggerFrom(name string, source domain.Source) error {
+	t.fired = append(t.fired, name+" from "+source.Id)
+	return nil
+}
+
+type scopedLogs struct {
+	ports.LogService
+	violations int
+}
+
+func (l *scopedLogs) Create(entry *domain.Log) error {
+	if entry.Event == "violation" {
+		l.violations++
+	}
+	return nil
+}
+
+func newScope() (*Scope, *scopedAttributes, *scopedTriggers, *scopedLogs) {
+	attributes := &scopedAttributes{}
+	triggers := &scopedTriggers{registered: map[string]domain.Trigger{}}
+	logs := &scopedLogs{}
+	entities := &scopedEntities{entities: map[string]domain.Entity{}}
+	entities.entities["strip"] = domain.Entity{Name: "strip", Module: "govee"}
+	ctrl := &controller.Controller{
+		Attributes: attributes,
+		Entities:   entities,
+		Triggers:   triggers,
+		Logs:       logs,
+	}
+	return New(ctrl, "hs100", "uuid"), attributes, triggers, logs
+}
+
+func TestScope_Control(t *testing.T) {
+	s, attributes, _, logs := newScope()
+	ctrl := s.Controller()
+
+	err := ctrl.Entities.Register(&domain.Entity{Name: "outlet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctrl.Attributes.Set("outlet", "on", "true")
+	if err != nil {
+		t.Errorf("setting an attribute of its own entity was refused: %s", err)
+	}
+
+	err = ctrl.Attributes.Set("strip", "on", "true")
+	if err == nil {
+		t.Errorf("setting an attribute of another module's entity was permitted")
+	}
+	if logs.violations != 1 {
+		t.Errorf("%d violations were logged, want 1", logs.violations)
+	}
+
+	s.Grant([]domain.Grant{{Capability: domain.CONTROL, Targets: []string{"govee"}}})
+	err = ctrl.Attributes.Set("strip", "on", "true")
+	if err != nil {
+		t.Errorf("setting an attribute of a granted module's entity was refused: %s", err)
+	}
+
+	if len(attributes.set) != 2 {
+		t.Errorf("attributes set were %v, want outlet.on and strip.on", attributes.set)
+	}
+}
+
+func TestScope_Fire(t *testing.T) {
+	s, _, triggers, logs := newScope()
+	ctrl := s.Controller()
+
+	err := ctrl.Triggers.Trigger("motion")
+	if err == nil {
+		t.Errorf("firing a trigger the module did not register was permitted")
+	}
+
+	err = ctrl.Triggers.Register(&domain.Trigger{Name: "motion", Type: domain.MODULE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctrl.Triggers.Trigger("motion")
+	if err != nil {
+		t.Errorf("firing a registered trigger was refused: %s", err)
+	}
+
+	s.Grant([]domain.Grant{{Capability: domain.FIRE, Targets: []string{"doorbell"}}})
+	err = ctrl.Triggers.Trigger("doorbell")
+	if err != nil {
+		t.Errorf("firing a granted trigger was refused: %s", err)
+	}
+
+	if len(triggers.fired) != 2 || triggers.fired[0] != "motion from hs100" {
+		t.Errorf("fired triggers were %v", triggers.fired)
+	}
+	if logs.violations != 1 {
+		t.Errorf("%d violations were logged, want 1", logs.violations)
+	}
+}
+
+func TestScope_FireOwned(t *testing.T) {
+	s, _, triggers, logs := newScope()
+	owner := New(s.ctrl, "govee", "uuid-govee").Controller()
+	err := owner.Triggers.Register(&domain.Trigger{Name: "motion", Type: domain.MODULE})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctrl := s.Controller()
+	trigger := &domain.Trigger{Name: "motion", Type: domain.MODULE}
+	err = ctrl.Triggers.Register(trigger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trigger.Module != "govee" {
+		t.Errorf("registering changed the owner of the trigger to '%s'", trigger.Module)
+	}
+	err = ctrl.Triggers.Trigger("motion")
+	if err == nil {
+		t.Errorf("firing another module's trigger was permitted")
+	}
+
+	err = owner.Triggers.Trigger("motion")
+	if err != nil {
+		t.Errorf("firing its own trigger was refused: %s", err)
+	}
+	if len(triggers.fired) != 1 || triggers.fired[0] != "motion from govee" {
+		t.Errorf("fired triggers were %v", triggers.fired)
+	}
+	if logs.violations != 1 {
+		t.Errorf("%d violations were logged, want 1", logs.violations)
+	}
+}
+
+func TestScope_Denied(t *testing.T) {
+	s, _, _, logs := newScope()
+	ctrl := s.Controller()
+
+	err := ctrl.Users.Delete(&domain.User{})
+	if err == nil {
+		t.Errorf("deleting a user was permitted")
+	}
+	err = ctrl.Attributes.Create(&domain.Attribute{})
+	if err == nil {
+		t.Errorf("creating an attribute directly was permitted")
+	}
+	if logs.violations != 2 {
+		t.Errorf("%d violations were logged, want 2", logs.violations)
+	}
+}
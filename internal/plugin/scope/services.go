@@ -0,0 +1,333 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package scope
+
+import (
+	"context"
+	"time"
+	"udap/internal/core/domain"
+)
+
+// The services below stand between a module and the system's services. Each embeds the service that refuses every
+// method of its port, and overrides the methods modules are permitted to call.
+
+type attributes struct {
+	deniedAttributes
+}
+
+// Register adds an attribute to one of the module's own entities
+func (a *attributes) Register(attribute *domain.Attribute) error {
+	owns, err := a.scope.owns(attribute.Entity)
+	if err != nil {
+		return err
+	}
+	if !owns {
+		return a.scope.violation("register attribute '%s' on an entity it does not own", attribute.Key)
+	}
+	return a.scope.ctrl.Attributes.Register(attribute)
+}
+
+func (a *attributes) FindAll() (*[]domain.Attribute, error) {
+	return a.scope.ctrl.Attributes.FindAll()
+}
+
+func (a *attributes) FindById(id string) (*domain.Attribute, error) {
+	return a.scope.ctrl.Attributes.FindById(id)
+}
+
+func (a *attributes) FindByComposite(entity string, key string) (*domain.Attribute, error) {
+	return a.scope.ctrl.Attributes.FindByComposite(entity, key)
+}
+
+func (a *attributes) FindAllByEntity(entity string) (*[]domain.Attribute, error) {
+	return a.scope.ctrl.Attributes.FindAllByEntity(entity)
+}
+
+func (a *attributes) Summary(key string, start int64, stop int64, window int, mode string) (map[int64]float64,
+	error) {
+	return a.scope.ctrl.Attributes.Summary(key, start, stop, window, mode)
+}
+
+func (a *attributes) Request(entity string, key string, value string) error {
+	err := a.scope.control(entity, key, "request")
+	if err != nil {
+		return err
+	}
+	return a.scope.ctrl.Attributes.Request(entity, key, value)
+}
+
+func (a *attributes) RequestContext(ctx context.Context, entity string, key string, value string) error {
+	err := a.scope.control(entity, key, "request")
+	if err != nil {
+		return err
+	}
+	return a.scope.ctrl.Attributes.RequestContext(ctx, entity, key, value)
+}
+
+func (a *attributes) Set(entity string, key string, value string) error {
+	err := a.scope.control(entity, key, "set")
+	if err != nil {
+		return err
+	}
+	return a.scope.ctrl.Attributes.Set(entity, key, value)
+}
+
+func (a *attributes) Update(entity string, key string, value string, stamp time.Time) error {
+	err := a.scope.control(entity, key, "update")
+	if err != nil {
+		return err
+	}
+	return a.scope.ctrl.Attributes.Update(entity, key, value, stamp)
+}
+
+type entities struct {
+	deniedEntities
+}
+
+// Register creates or finds an entity belonging to the module
+func (e *entities) Register(entity *domain.Entity) error {
+	if entity.Module == "" {
+		entity.Module = e.scope.module
+	}
+	if entity.Module != e.scope.module {
+		return e.scope.violation("register entity '%s' for module '%s'", entity.Name, entity.Module)
+	}
+	if entity.Id != "" {
+		owns, err := e.scope.owns(entity.Id)
+		if err != nil {
+			return err
+		}
+		if !owns {
+			return e.scope.violation("register over entity '%s'", entity.Name)
+		}
+	}
+	err := e.scope.ctrl.Entities.Register(entity)
+	if err != nil {
+		return err
+	}
+	e.scope.own(*entity)
+	return nil
+}
+
+func (e *entities) FindAll() (*[]domain.Entity, error) {
+	return e.scope.ctrl.Entities.FindAll()
+}
+
+func (e *entities) FindById(id string) (*domain.Entity, error) {
+	return e.scope.ctrl.Entities.FindById(id)
+}
+
+func (e *entities) FindByName(name string) (*domain.Entity, error) {
+	return e.scope.ctrl.Entities.FindByName(name)
+}
+
+func (e *entities) FindAllByModule(name string) (*[]domain.Entity, error) {
+	return e.scope.ctrl.Entities.FindAllByModule(name)
+}
+
+// modify permits changes to the module's own entities
+func (e *entities) modify(id string, action string) error {
+	owns, err := e.scope.owns(id)
+	if err != nil {
+		return err
+	}
+	if !owns {
+		return e.scope.violation("%s of an entity it does not own", action)
+	}
+	return nil
+}
+
+func (e *entities) Config(id string, value string) error {
+	err := e.modify(id, "change the config")
+	if err != nil {
+		return err
+	}
+	return e.scope.ctrl.Entities.Config(id, value)
+}
+
+func (e *entities) SetPrediction(id string, prediction string) error {
+	err := e.modify(id, "set the prediction")
+	if err != nil {
+		return err
+	}
+	return e.scope.ctrl.Entities.SetPrediction(id, prediction)
+}
+
+type triggers struct {
+	deniedTriggers
+}
+
+// Register creates or finds a trigger. The module owns the module triggers it creates and may fire them, finding a
+// trigger another module owns does not permit firing it.
+func (t *triggers) Register(trigger *domain.Trigger) error {
+	trigger.Module = t.scope.module
+	err := t.scope.ctrl.Triggers.Register(trigger)
+	if err != nil {
+		return err
+	}
+	if trigger.Type == domain.MODULE && trigger.Module == t.scope.module {
+		t.scope.mutex.Lock()
+		t.scope.triggers[trigger.Name] = true
+		t.scope.mutex.Unlock()
+	}
+	return nil
+}
+
+func (t *triggers) FindAll() (*[]domain.Trigger, error) {
+	return t.scope.ctrl.Triggers.FindAll()
+}
+
+func (t *triggers) FindById(id string) (*domain.Trigger, error) {
+	return t.scope.ctrl.Triggers.FindById(id)
+}
+
+func (t *triggers) Trigger(name string) error {
+	return t.TriggerFrom(name, domain.Source{})
+}
+
+// TriggerFrom fires a trigger, the source is always attributed to the module
+func (t *triggers) TriggerFrom(name string, _ domain.Source) error {
+	err := t.scope.fire(name)
+	if err != nil {
+		return err
+	}
+	return t.scope.ctrl.Triggers.TriggerFrom(name, domain.Source{Kind: domain.MODULE, Id: t.scope.module})
+}
+
+func (t *triggers) TriggerCustom(name string, key string, value string) error {
+	err := t.scope.fire(name)
+	if err != nil {
+		return err
+	}
+	return t.scope.ctrl.Triggers.TriggerCustom(name, key, value)
+}
+
+type devices struct {
+	deniedDevices
+}
+
+func (d *devices) FindAll() (*[]domain.Device, error) {
+	return d.scope.ctrl.Devices.FindAll()
+}
+
+func (d *devices) FindById(id string) (*domain.Device, error) {
+	return d.scope.ctrl.Devices.FindById(id)
+}
+
+func (d *devices) Register(device *domain.Device) error {
+	return d.scope.ctrl.Devices.Register(device)
+}
+
+func (d *devices) FindOrCreate(device *domain.Device) error {
+	return d.scope.ctrl.Devices.FindOrCreate(device)
+}
+
+func (d *devices) Update(device *domain.Device) error {
+	return d.scope.ctrl.Devices.Update(device)
+}
+
+func (d *devices) Ping(id string, latency time.Duration) error {
+	return d.scope.ctrl.Devices.Ping(id, latency)
+}
+
+func (d *devices) Utilization(id string, utilization domain.Utilization) error {
+	return d.scope.ctrl.Devices.Utilization(id, utilization)
+}
+
+type networks struct {
+	deniedNetworks
+}
+
+func (n *networks) FindAll() (*[]domain.Network, error) {
+	return n.scope.ctrl.Networks.FindAll()
+}
+
+func (n *networks) FindById(id string) (*domain.Network, error) {
+	return n.scope.ctrl.Networks.FindById(id)
+}
+
+func (n *networks) Register(network *domain.Network) error {
+	return n.scope.ctrl.Networks.Register(network)
+}
+
+type zones struct {
+	deniedZones
+}
+
+func (z *zones) FindAll() (*[]domain.Zone, error) {
+	return z.scope.ctrl.Zones.FindAll()
+}
+
+func (z *zones) FindById(id string) (*domain.Zone, error) {
+	return z.scope.ctrl.Zones.FindById(id)
+}
+
+func (z *zones) FindByName(name string) (*domain.Zone, error) {
+	return z.scope.ctrl.Zones.FindByName(name)
+}
+
+type endpoints struct {
+	deniedEndpoints
+}
+
+// FindById reads an endpoint, endpoints belong to users so reading them must be granted
+func (e *endpoints) FindById(id string) (*domain.Endpoint, error) {
+	if !e.scope.granted(domain.ENDPOINTS, id) {
+		return nil, e.scope.violation("read endpoint '%s'", id)
+	}
+	return e.scope.ctrl.Endpoints.FindById(id)
+}
+
+type logs struct {
+	deniedLogs
+}
+
+// Create writes a log entry, entries are always attributed to the module
+func (l *logs) Create(entry *domain.Log) error {
+	entry.Module = l.scope.module
+	return l.scope.ctrl.Logs.Create(entry)
+}
+
+type modules struct {
+	deniedModules
+}
+
+func (m *modules) InitConfig(uuid string, key string, value string) error {
+	err := m.scope.self(uuid, "initialize the config")
+	if err != nil {
+		return err
+	}
+	return m.scope.ctrl.Modules.InitConfig(uuid, key, value)
+}
+
+func (m *modules) GetConfig(uuid string, key string) (string, error) {
+	err := m.scope.self(uuid, "read the config")
+	if err != nil {
+		return "", err
+	}
+	return m.scope.ctrl.Modules.GetConfig(uuid, key)
+}
+
+func (m *modules) SetConfig(uuid string, key string, value string) error {
+	err := m.scope.self(uuid, "change the config")
+	if err != nil {
+		return err
+	}
+	return m.scope.ctrl.Modules.SetConfig(uuid, key, value)
+}
+
+func (m *modules) Fault(uuid string, reason string) error {
+	err := m.scope.self(uuid, "report a fault")
+	if err != nil {
+		return err
+	}
+	return m.scope.ctrl.Modules.Fault(uuid, reason)
+}
+
+func (m *modules) Subscribe(uuid string, filter domain.MutationFilter) error {
+	err := m.scope.self(uuid, "subscribe")
+	if err != nil {
+		return err
+	}
+	return m.scope.ctrl.Modules.Subscribe(uuid, filter)
+}
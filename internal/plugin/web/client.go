@@ -0,0 +1,356 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package web is the http client shared by modules. Requests that fail with a network error, a rate limit or an
+// unavailable server are retried with backoff, rate limits reported by a host are respected for every later
+// request to that host, and non-2xx responses are returned as a *StatusError. Each call is timed with pulse.
+//
+// Responses can be recorded to fixtures and replayed, so modules can be tested without a network:
+//
+//	client := web.New("weather")
+//	client.Replay("testdata")
+//	err := client.Get("https://api.weather.gov/points/37,-122").JSON(&forecast)
+package web
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"udap/internal/pulse"
+)
+
+const (
+	// defaultTimeout bounds each attempt of a request
+	defaultTimeout = time.Second * 5
+	// defaultRetries is the number of times a failed request is retried
+	defaultRetries = 2
+	// defaultBackoff is the delay before the first retry, it doubles with each retry
+	defaultBackoff = time.Millisecond * 250
+	// maxWait is the longest a request waits for a retry or a rate limit to reset
+	maxWait = time.Second * 30
+)
+
+// StatusError is returned for responses with a non-2xx status code
+type StatusError struct {
+	Method string
+	URL    string
+	Status int
+	Body   []byte
+}
+
+func (e *StatusError) Error() string {
+	body := strings.TrimSpace(string(e.Body))
+	if len(body) > 128 {
+		body = body[:128] + "..."
+	}
+	return fmt.Sprintf("%s %s: %d %s %s", e.Method, e.URL, e.Status, http.StatusText(e.Status), body)
+}
+
+// Response is a completed response with its body read
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the body of the response
+func (r *Response) JSON(output any) error {
+	return json.Unmarshal(r.Body, output)
+}
+
+// Client makes requests on behalf of a module
+type Client struct {
+	name     string
+	client   *http.Client
+	timeout  time.Duration
+	retries  int
+	backoff  time.Duration
+	hosts    map[string]*host
+	fixtures *fixtures
+	mutex    sync.Mutex
+}
+
+// New creates a client for a module, the name is used to time the module's calls
+func New(name string) *Client {
+	return &Client{
+		name:    name,
+		client:  &http.Client{},
+		timeout: defaultTimeout,
+		retries: defaultRetries,
+		backoff: defaultBackoff,
+		hosts:   map[string]*host{},
+	}
+}
+
+// SetTimeout changes the default timeout of each attempt
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.timeout = timeout
+}
+
+// SetRetries changes the number of times a failed request is retried and the delay before the first retry
+func (c *Client) SetRetries(retries int, backoff time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.retries = retries
+	c.backoff = backoff
+}
+
+// Get prepares a GET request
+func (c *Client) Get(url string) *Request {
+	return c.New(http.MethodGet, url, nil)
+}
+
+// Head prepares a HEAD request
+func (c *Client) Head(url string) *Request {
+	return c.New(http.MethodHead, url, nil)
+}
+
+// Post prepares a POST request, the body is sent as json unless it is a []byte or string
+func (c *Client) Post(url string, body any) *Request {
+	return c.New(http.MethodPost, url, body)
+}
+
+// Put prepares a PUT request, the body is sent as json unless it is a []byte or string
+func (c *Client) Put(url string, body any) *Request {
+	return c.New(http.MethodPut, url, body)
+}
+
+// Patch prepares a PATCH request, the body is sent as json unless it is a []byte or string
+func (c *Client) Patch(url string, body any) *Request {
+	return c.New(http.MethodPatch, url, body)
+}
+
+// Delete prepares a DELETE request
+func (c *Client) Delete(url string) *Request {
+	return c.New(http.MethodDelete, url, nil)
+}
+
+// New prepares a request with any method, the body is sent as json unless it is a []byte or string
+func (c *Client) New(method string, url string, body any) *Request {
+	r := &Request{
+		client: c,
+		method: method,
+		url:    url,
+		header: http.Header{},
+		query:  map[string][]string{},
+	}
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		r.body = b
+	case string:
+		r.body = []byte(b)
+	case json.RawMessage:
+		r.body = b
+		r.header.Set("Content-Type", "application/json")
+	default:
+		r.body, r.err = json.Marshal(b)
+		r.header.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+// Request is a request being prepared, its methods may be chained
+type Request struct {
+	client  *Client
+	method  string
+	url     string
+	header  http.Header
+	query   map[string][]string
+	body    []byte
+	timeout time.Duration
+	err     error
+}
+
+// Header sets a request header
+func (r *Request) Header(key string, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query adds a query parameter to the url
+func (r *Request) Query(key string, value string) *Request {
+	r.query[key] = append(r.query[key], value)
+	return r
+}
+
+// BasicAuth sets the request's basic authentication
+func (r *Request) BasicAuth(username string, password string) *Request {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return r.Authorization("Basic", credentials)
+}
+
+// Authorization sets the authorization header, such as Authorization("Bearer", token)
+func (r *Request) Authorization(style string, token string) *Request {
+	r.header.Set("Authorization", fmt.Sprintf("%s %s", style, token))
+	return r
+}
+
+// Timeout changes the timeout of each attempt of this request
+func (r *Request) Timeout(timeout time.Duration) *Request {
+	r.timeout = timeout
+	return r
+}
+
+// JSON sends the request and decodes the response body into output, output may be nil
+func (r *Request) JSON(output any) error {
+	response, err := r.Do()
+	if err != nil {
+		return err
+	}
+	if output == nil || len(response.Body) == 0 {
+		return nil
+	}
+	return response.JSON(output)
+}
+
+// Do sends the request, retrying it if it fails in a way that may succeed later. Responses with a non-2xx
+// status are returned with a *StatusError.
+func (r *Request) Do() (*Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	target, err := r.target()
+	if err != nil {
+		return nil, err
+	}
+	c := r.client
+	c.mutex.Lock()
+	timeout, retries, backoff, fixtures := c.timeout, c.retries, c.backoff, c.fixtures
+	c.mutex.Unlock()
+	if r.timeout > 0 {
+		timeout = r.timeout
+	}
+	var response *Response
+	if fixtures != nil && fixtures.replay {
+		response, err = fixtures.load(r.method, target.String(), r.body)
+	} else {
+		response, err = r.exchange(target, timeout, retries, backoff)
+		if err == nil && fixtures != nil {
+			err = fixtures.save(r.method, target.String(), r.body, response)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Status < 200 || response.Status > 299 {
+		return response, &StatusError{Method: r.method, URL: target.String(), Status: response.Status,
+			Body: response.Body}
+	}
+	return response, nil
+}
+
+// exchange sends the request to the host, retrying while it fails in a way that may succeed later
+func (r *Request) exchange(target *url.URL, timeout time.Duration, retries int, backoff time.Duration) (*Response,
+	error) {
+	limits := r.client.host(target.Host)
+	addr := fmt.Sprintf("module.%s.http.%s", r.client.name, target.Host)
+	for attempt := 0; ; attempt++ {
+		err := limits.wait()
+		if err != nil {
+			return nil, err
+		}
+		pulse.Begin(addr)
+		response, err := r.send(target, timeout)
+		pulse.End(addr)
+		if response != nil {
+			limits.observe(response)
+		}
+		delay, retry := r.retry(response, err, backoff<<attempt)
+		if !retry || attempt >= retries || delay > maxWait {
+			return response, err
+		}
+		time.Sleep(delay)
+	}
+}
+
+// target provides the url of the request with its query parameters
+func (r *Request) target() (*url.URL, error) {
+	target, err := url.Parse(r.url)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.query) > 0 {
+		values := target.Query()
+		for key, value := range r.query {
+			values[key] = append(values[key], value...)
+		}
+		target.RawQuery = values.Encode()
+	}
+	return target, nil
+}
+
+// send makes a single attempt of the request
+func (r *Request) send(target *url.URL, timeout time.Duration) (*Response, error) {
+	request, err := http.NewRequest(r.method, target.String(), bytes.NewReader(r.body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header = r.header.Clone()
+	client := *r.client.client
+	client.Timeout = timeout
+	do, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer do.Body.Close()
+	body, err := io.ReadAll(do.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Status: do.StatusCode, Header: do.Header, Body: body}, nil
+}
+
+// retry decides whether an attempt should be retried and how long to wait first. Rate limited requests and
+// unavailable servers are retried, as are network errors for requests that can safely be repeated.
+func (r *Request) retry(response *Response, err error, backoff time.Duration) (time.Duration, bool) {
+	// Add up to 25% jitter so clients that failed together do not retry together
+	delay := backoff + time.Duration(rand.Int63n(int64(backoff)/4+1))
+	if err != nil {
+		return delay, idempotent(r.method)
+	}
+	switch response.Status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if after, ok := retryAfter(response.Header); ok {
+			return after, true
+		}
+		return delay, true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return delay, idempotent(r.method)
+	}
+	return 0, false
+}
+
+// idempotent reports whether a request with the method can be repeated without changing its outcome
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+	return 0, false
+}
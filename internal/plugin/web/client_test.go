@@ -0,0 +1,90 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type forecast struct {
+	High int `json:"high"`
+}
+
+func TestClient(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/forecast":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("X-RateLimit-Limit", "60")
+			w.Header().Set("X-RateLimit-Remaining", "59")
+			_, _ = w.Write([]byte(`{"high": 72}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	client := New("weather")
+	client.SetRetries(2, time.Millisecond)
+	fixtures := t.TempDir()
+	client.Record(fixtures)
+
+	out := forecast{}
+	err := client.Get(server.URL + "/forecast").JSON(&out)
+	if err != nil || out.High != 72 {
+		t.Fatalf("JSON() = %v, %v, want the forecast after a retry", out, err)
+	}
+	if calls != 2 {
+		t.Errorf("server was called %d times, want 2", calls)
+	}
+	host, _ := url.Parse(server.URL)
+	if limit, ok := client.Limit(host.Host); !ok || limit.Remaining != 59 || limit.Limit != 60 {
+		t.Errorf("Limit() = %v, %v, want 59 of 60 remaining", limit, ok)
+	}
+
+	_, err = client.Post(server.URL+"/missing", map[string]int{"high": 72}).Do()
+	status := &StatusError{}
+	if !errors.As(err, &status) || status.Status != http.StatusNotFound {
+		t.Errorf("Do() = %v, want a 404 status error", err)
+	}
+
+	// Recorded responses are replayed without the server
+	server.Close()
+	client.Replay(fixtures)
+	out = forecast{}
+	err = client.Get(server.URL + "/forecast").JSON(&out)
+	if err != nil || out.High != 72 {
+		t.Errorf("replayed JSON() = %v, %v, want the recorded forecast", out, err)
+	}
+	if client.Get(server.URL+"/elsewhere").JSON(nil) == nil {
+		t.Errorf("requests without a fixture should fail when replaying")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-RateLimit-Remaining", "0")
+		w.Header().Set("API-RateLimit-Reset", "120")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := New("govee")
+	_, err := client.Get(server.URL).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The host has no requests remaining for two minutes, so the next request is refused rather than held
+	_, err = client.Get(server.URL).Do()
+	if err == nil {
+		t.Errorf("requests should be refused until the rate limit resets")
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fixture is a recorded exchange, request headers are not recorded so credentials stay out of fixtures
+type fixture struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Request  string      `json:"request,omitempty"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header,omitempty"`
+	Response string      `json:"response"`
+}
+
+// fixtures records responses to, or replays responses from, a directory
+type fixtures struct {
+	dir    string
+	replay bool
+}
+
+// Record writes each response the client receives to a fixture in the directory
+func (c *Client) Record(dir string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fixtures = &fixtures{dir: dir}
+}
+
+// Replay answers each request from the fixtures in the directory instead of the network, requests without a
+// fixture fail
+func (c *Client) Replay(dir string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fixtures = &fixtures{dir: dir, replay: true}
+}
+
+// path provides the fixture file of a request, requests are identified by method, url and body
+func (f *fixtures) path(method string, url string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + url + "\n"))
+	hash.Write(body)
+	sum := hex.EncodeToString(hash.Sum(nil))
+	return filepath.Join(f.dir, fmt.Sprintf("%s-%s.json", strings.ToLower(method), sum[:16]))
+}
+
+func (f *fixtures) load(method string, url string, body []byte) (*Response, error) {
+	data, err := os.ReadFile(f.path(method, url, body))
+	if err != nil {
+		return nil, fmt.Errorf("no fixture recorded for %s %s", method, url)
+	}
+	recorded := fixture{}
+	err = json.Unmarshal(data, &recorded)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Status: recorded.Status, Header: recorded.Header, Body: []byte(recorded.Response)}, nil
+}
+
+func (f *fixtures) save(method string, url string, body []byte, response *Response) error {
+	header := response.Header.Clone()
+	header.Del("Set-Cookie")
+	header.Del("Date")
+	marshal, err := json.MarshalIndent(fixture{
+		Method:   method,
+		URL:      url,
+		Request:  string(body),
+		Status:   response.Status,
+		Header:   header,
+		Response: string(response.Body),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(f.dir, 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path(method, url, body), marshal, 0644)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// limitPrefixes are the header prefixes hosts use to report rate limits, such as X-RateLimit-Remaining
+var limitPrefixes = []string{"X-RateLimit-", "RateLimit-", "API-RateLimit-", "X-Rate-Limit-"}
+
+// Limit is the rate limit last reported by a host
+type Limit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// host holds back requests to a host that has reported a rate limit, or that has a minimum interval set
+type host struct {
+	name     string
+	limit    Limit
+	known    bool
+	until    time.Time
+	interval time.Duration
+	next     time.Time
+	mutex    sync.Mutex
+}
+
+// host provides the limits of a host
+func (c *Client) host(name string) *host {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	h, ok := c.hosts[name]
+	if !ok {
+		h = &host{name: name}
+		c.hosts[name] = h
+	}
+	return h
+}
+
+// SetRate spaces requests to a host at least interval apart
+func (c *Client) SetRate(host string, interval time.Duration) {
+	h := c.host(host)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.interval = interval
+}
+
+// Limit reports the rate limit last reported by a host, if it has reported one
+func (c *Client) Limit(host string) (Limit, bool) {
+	h := c.host(host)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.limit, h.known
+}
+
+// wait blocks until a request may be sent to the host, requests that would wait too long are refused
+func (h *host) wait() error {
+	h.mutex.Lock()
+	now := time.Now()
+	wake := now
+	if h.until.After(wake) {
+		wake = h.until
+	}
+	if h.next.After(wake) {
+		wake = h.next
+	}
+	if wake.Sub(now) > maxWait {
+		h.mutex.Unlock()
+		return fmt.Errorf("rate limit of '%s' resets in %s", h.name, wake.Sub(now).Round(time.Second))
+	}
+	// Reserve the slot before waiting so concurrent requests are spaced apart
+	h.next = wake.Add(h.interval)
+	h.mutex.Unlock()
+	time.Sleep(wake.Sub(now))
+	return nil
+}
+
+// observe reads the rate limit headers of a response
+func (h *host) observe(response *Response) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if response.Status == http.StatusTooManyRequests || response.Status == http.StatusServiceUnavailable {
+		if after, ok := retryAfter(response.Header); ok {
+			h.until = time.Now().Add(after)
+		}
+	}
+	for _, prefix := range limitPrefixes {
+		remaining, err := strconv.Atoi(response.Header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+		limit, _ := strconv.Atoi(response.Header.Get(prefix + "Limit"))
+		h.limit = Limit{Limit: limit, Remaining: remaining, Reset: reset(response.Header.Get(prefix + "Reset"))}
+		h.known = true
+		if remaining <= 0 && h.limit.Reset.After(h.until) {
+			h.until = h.limit.Reset
+		}
+		return
+	}
+}
+
+// reset reads a rate limit reset given as a unix time or as seconds from now
+func reset(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	if seconds > 1_000_000_000 {
+		return time.Unix(seconds, 0)
+	}
+	return time.Now().Add(time.Duration(seconds) * time.Second)
+}
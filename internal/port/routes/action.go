@@ -0,0 +1,142 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type actionRouter struct {
+	service ports.ActionService
+}
+
+func NewActionRouter(service ports.ActionService) Routable {
+	return &actionRouter{
+		service: service,
+	}
+}
+
+func (r *actionRouter) RouteInternal(router chi.Router) {
+	router.Post("/actions/create", r.create)
+	router.Route("/actions/{id}", func(local chi.Router) {
+		local.Post("/run", r.run)
+		local.Post("/update", r.update)
+		local.Post("/delete", r.delete)
+	})
+}
+
+func (r *actionRouter) RouteExternal(_ chi.Router) {
+
+}
+
+func (r *actionRouter) create(w http.ResponseWriter, req *http.Request) {
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read action", 400)
+		return
+	}
+
+	action := domain.Action{}
+	err = json.Unmarshal(buf.Bytes(), &action)
+	if err != nil {
+		http.Error(w, "could not parse action", 400)
+		return
+	}
+
+	err = r.service.Create(&action)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *actionRouter) run(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "action id not provided", 401)
+		return
+	}
+
+	query := req.URL.Query()
+	err := r.service.ExecuteCustomById(id, query.Get("key"), query.Get("value"), requester(req))
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *actionRouter) update(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "action id not provided", 401)
+		return
+	}
+
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read action", 400)
+		return
+	}
+
+	delta := domain.Action{}
+	err = json.Unmarshal(buf.Bytes(), &delta)
+	if err != nil {
+		http.Error(w, "could not parse action", 400)
+		return
+	}
+
+	action, err := r.service.FindById(id)
+	if err != nil {
+		http.Error(w, "action not found", 404)
+		return
+	}
+
+	action.Name = delta.Name
+	action.TriggerId = delta.TriggerId
+	action.Kind = delta.Kind
+	action.Entities = delta.Entities
+	action.Attribute = delta.Attribute
+	action.Request = delta.Request
+	action.Target = delta.Target
+	action.Method = delta.Method
+	action.Url = delta.Url
+	action.Headers = delta.Headers
+	action.Body = delta.Body
+
+	err = r.service.Update(action)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *actionRouter) delete(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "action id not provided", 401)
+		return
+	}
+
+	err := r.service.Delete(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
@@ -5,19 +5,25 @@ package routes
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"udap/internal/core/domain"
 	"udap/internal/core/ports"
 )
 
 type attributeRouter struct {
-	service ports.AttributeService
+	service  ports.AttributeService
+	entities ports.EntityService
 }
 
 func (r *attributeRouter) RouteInternal(router chi.Router) {
 	router.Post("/entities/{id}/attributes/{key}/request", r.request)
 	router.Post("/attribute/{id}/delete", r.delete)
 	router.Post("/attribute/summary", r.summary)
+	router.Get("/entities/{id}/attributes/{key}/schema", r.schema)
+	router.Get("/attributes/schemas", r.schemas)
+	router.Get("/attributes/queues", r.queues)
 
 }
 
@@ -25,9 +31,10 @@ func (r *attributeRouter) RouteExternal(_ chi.Router) {
 
 }
 
-func NewAttributeRouter(service ports.AttributeService) Routable {
+func NewAttributeRouter(service ports.AttributeService, entities ports.EntityService) Routable {
 	return &attributeRouter{
-		service: service,
+		service:  service,
+		entities: entities,
 	}
 }
 
@@ -74,7 +81,16 @@ func (r *attributeRouter) request(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 	if id != "" && key != "" {
-		err = r.service.Request(id, key, buf.String())
+		err = r.service.RequestContext(req.Context(), id, key, buf.String())
+		var invalid *domain.ValidationError
+		if errors.As(err, &invalid) {
+			writeValidationError(w, invalid)
+			return
+		}
+		if errors.Is(err, domain.ErrQueueFull) || errors.Is(err, domain.ErrNotConsuming) {
+			http.Error(w, err.Error(), 503)
+			return
+		}
 		if err != nil {
 			w.Write([]byte(err.Error()))
 			//w.WriteHeader(500)
@@ -85,6 +101,73 @@ func (r *attributeRouter) request(w http.ResponseWriter, req *http.Request) {
 	w.Write([]byte("OK"))
 }
 
+func writeValidationError(w http.ResponseWriter, invalid *domain.ValidationError) {
+	marshal, err := json.Marshal(invalid)
+	if err != nil {
+		http.Error(w, invalid.Error(), 400)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+	_, _ = w.Write(marshal)
+}
+
+func (r *attributeRouter) schema(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	key := chi.URLParam(req, "key")
+	if id == "" || key == "" {
+		http.Error(w, "attribute not provided", 400)
+		return
+	}
+
+	attribute, err := r.service.FindByComposite(id, key)
+	if err != nil {
+		http.Error(w, "attribute not found", 404)
+		return
+	}
+
+	marshal, err := json.Marshal(attribute.Schema)
+	if err != nil {
+		w.WriteHeader(500)
+		return
+	}
+
+	_, _ = w.Write(marshal)
+}
+
+func (r *attributeRouter) schemas(w http.ResponseWriter, _ *http.Request) {
+	marshal, err := json.Marshal(domain.DefaultSchemas())
+	if err != nil {
+		w.WriteHeader(500)
+		return
+	}
+
+	_, _ = w.Write(marshal)
+}
+
+func (r *attributeRouter) queues(w http.ResponseWriter, _ *http.Request) {
+	queues := r.service.Queues()
+	for i, queue := range queues {
+		if len(queue.Entities) == 0 {
+			continue
+		}
+		// Label each queue with the module that owns its entities
+		entity, err := r.entities.FindById(queue.Entities[0])
+		if err != nil {
+			continue
+		}
+		queues[i].Module = entity.Module
+	}
+
+	marshal, err := json.Marshal(queues)
+	if err != nil {
+		w.WriteHeader(500)
+		return
+	}
+
+	_, _ = w.Write(marshal)
+}
+
 type SummaryRequest struct {
 	Key    string `json:"key"`
 	To     int64  `json:"to"`
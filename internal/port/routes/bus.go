@@ -0,0 +1,39 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"udap/internal/core/bus"
+)
+
+type busRouter struct {
+	bus *bus.Bus
+}
+
+func NewBusRouter(b *bus.Bus) Routable {
+	return &busRouter{
+		bus: b,
+	}
+}
+
+func (r *busRouter) RouteInternal(router chi.Router) {
+	router.Get("/bus/stats", r.stats)
+}
+
+func (r *busRouter) RouteExternal(_ chi.Router) {
+
+}
+
+// stats reports the queue depth and the delivered, dropped and coalesced mutations of each bus subscriber
+func (r *busRouter) stats(w http.ResponseWriter, _ *http.Request) {
+	marshal, err := json.Marshal(r.bus.Stats())
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
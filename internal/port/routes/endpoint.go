@@ -32,7 +32,37 @@ func (r *endpointRouter) RouteExternal(router chi.Router) {
 }
 
 func (r *endpointRouter) RouteInternal(router chi.Router) {
+	router.Get("/endpoints/stats", r.stats)
+	router.Get("/endpoints/{id}/stats", r.endpointStats)
+}
 
+// stats reports the queued, sent and dropped messages of each enrolled endpoint and when it was last seen
+func (r *endpointRouter) stats(w http.ResponseWriter, _ *http.Request) {
+	marshal, err := json.Marshal(r.service.Stats())
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+func (r *endpointRouter) endpointStats(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	for _, stats := range r.service.Stats() {
+		if stats.Id != id {
+			continue
+		}
+		marshal, err := json.Marshal(stats)
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(marshal)
+		return
+	}
+	http.Error(w, "endpoint is not enrolled", 404)
 }
 
 func (r *endpointRouter) registerPush(w http.ResponseWriter, req *http.Request) {
@@ -145,19 +175,28 @@ func (r *endpointRouter) enroll(w http.ResponseWriter, req *http.Request) {
 	err = r.service.Enroll(id, conn)
 	if err != nil {
 		log.Err(err)
+		_ = conn.Close()
+		return
 	}
 
 	done := make(chan bool)
 	go func() {
-		_, _, err = conn.ReadMessage()
-		if err != nil {
-			done <- true
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				done <- true
+				return
+			}
+			err = r.handleRequest(id, message)
+			if err != nil {
+				log.Err(err)
+			}
 		}
 	}()
 
 	defer func() {
 		_ = conn.Close()
-		err = r.service.Unenroll(id)
+		err = r.service.Unenroll(id, conn)
 		if err != nil {
 			log.Err(err)
 		}
@@ -166,3 +205,14 @@ func (r *endpointRouter) enroll(w http.ResponseWriter, req *http.Request) {
 	<-done
 
 }
+
+// handleRequest runs a command sent by an endpoint, the command is acknowledged over the websocket
+func (r *endpointRouter) handleRequest(endpoint string, message []byte) error {
+	command := domain.Command{}
+	err := json.Unmarshal(message, &command)
+	if err != nil {
+		return err
+	}
+	r.service.Execute(endpoint, command)
+	return nil
+}
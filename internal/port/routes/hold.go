@@ -0,0 +1,62 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"udap/internal/core/ports"
+)
+
+type holdRouter struct {
+	service ports.HoldService
+}
+
+func NewHoldRouter(service ports.HoldService) Routable {
+	return &holdRouter{
+		service: service,
+	}
+}
+
+func (r *holdRouter) RouteInternal(router chi.Router) {
+	router.Route("/holds/{id}", func(local chi.Router) {
+		local.Post("/release", r.release)
+		local.Post("/cancel", r.cancel)
+	})
+}
+
+func (r *holdRouter) RouteExternal(_ chi.Router) {
+
+}
+
+func (r *holdRouter) release(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "hold id not provided", 401)
+		return
+	}
+
+	err := r.service.Release(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *holdRouter) cancel(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "hold id not provided", 401)
+		return
+	}
+
+	err := r.service.Cancel(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type invocationRouter struct {
+	service ports.InvocationService
+}
+
+func NewInvocationRouter(service ports.InvocationService) Routable {
+	return &invocationRouter{
+		service: service,
+	}
+}
+
+func (r *invocationRouter) RouteInternal(router chi.Router) {
+	router.Get("/invocations", r.query)
+	router.Get("/invocations/{id}", r.findById)
+}
+
+func (r *invocationRouter) RouteExternal(_ chi.Router) {
+
+}
+
+// query returns a page of the invocation history. Filters are read from the query string, such as
+// ?kind=trigger&source=rule&failed=true&since=2022-06-01T00:00:00Z&offset=50&limit=50
+func (r *invocationRouter) query(w http.ResponseWriter, req *http.Request) {
+	values := req.URL.Query()
+	filter := domain.InvocationFilter{
+		Kind:   values.Get("kind"),
+		Target: values.Get("target"),
+		Source: values.Get("source"),
+		Parent: values.Get("parent"),
+		Failed: values.Get("failed") == "true",
+	}
+
+	var err error
+	if value := values.Get("since"); value != "" {
+		filter.Since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "invalid since", 400)
+			return
+		}
+	}
+	if value := values.Get("until"); value != "" {
+		filter.Until, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "invalid until", 400)
+			return
+		}
+	}
+	if value := values.Get("offset"); value != "" {
+		filter.Offset, err = strconv.Atoi(value)
+		if err != nil || filter.Offset < 0 {
+			http.Error(w, "invalid offset", 400)
+			return
+		}
+	}
+	if value := values.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "invalid limit", 400)
+			return
+		}
+	}
+
+	page, err := r.service.Query(filter)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	marshal, err := json.Marshal(page)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+func (r *invocationRouter) findById(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "invocation id not provided", 401)
+		return
+	}
+
+	invocation, err := r.service.FindById(id)
+	if err != nil {
+		http.Error(w, "invocation not found", 404)
+		return
+	}
+
+	marshal, err := json.Marshal(invocation)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
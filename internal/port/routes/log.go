@@ -0,0 +1,89 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type logRouter struct {
+	service ports.LogService
+}
+
+func NewLogRouter(service ports.LogService) Routable {
+	return &logRouter{
+		service: service,
+	}
+}
+
+func (r *logRouter) RouteInternal(router chi.Router) {
+	router.Get("/logs", r.query)
+}
+
+func (r *logRouter) RouteExternal(_ chi.Router) {
+
+}
+
+// query returns a page of logs. Filters are read from the query string, such as
+// ?level=error&module=govee&search=timeout&since=2022-06-01T00:00:00Z&limit=100
+func (r *logRouter) query(w http.ResponseWriter, req *http.Request) {
+	values := req.URL.Query()
+	filter := domain.LogFilter{
+		Level:       values.Get("level"),
+		Module:      values.Get("module"),
+		Entity:      values.Get("entity"),
+		Correlation: values.Get("correlation"),
+		Search:      values.Get("search"),
+	}
+
+	var err error
+	if value := values.Get("since"); value != "" {
+		filter.Since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "invalid since", 400)
+			return
+		}
+	}
+	if value := values.Get("until"); value != "" {
+		filter.Until, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "invalid until", 400)
+			return
+		}
+	}
+	if value := values.Get("offset"); value != "" {
+		filter.Offset, err = strconv.Atoi(value)
+		if err != nil || filter.Offset < 0 {
+			http.Error(w, "invalid offset", 400)
+			return
+		}
+	}
+	if value := values.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, "invalid limit", 400)
+			return
+		}
+	}
+
+	page, err := r.service.Query(filter)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	marshal, err := json.Marshal(page)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
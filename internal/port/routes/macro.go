@@ -153,7 +153,7 @@ func (r macroRouter) run(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err := r.service.Run(key)
+	err := r.service.Run(key, requester(req))
 	if err != nil {
 		http.Error(w, "could not run macro", 500)
 		return
@@ -3,6 +3,8 @@
 package routes
 
 import (
+	"bytes"
+	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"net/http"
 	"udap/internal/core/ports"
@@ -20,12 +22,20 @@ func NewModuleRouter(service ports.ModuleService) Routable {
 }
 
 func (r *moduleRouter) RouteInternal(router chi.Router) {
+	router.Get("/modules/schedules", r.schedules)
+	router.Post("/modules/install", r.install)
 	router.Route("/modules/{id}", func(local chi.Router) {
+		local.Get("/", r.status)
 		local.Post("/reload", r.reload)
 		local.Post("/build", r.build)
 		local.Post("/disable", r.disable)
 		local.Post("/enable", r.enable)
 		local.Post("/halt", r.halt)
+		local.Post("/runtime/{runtime}", r.runtime)
+		local.Post("/config", r.config)
+		local.Post("/rollback", r.rollback)
+		local.Get("/health", r.health)
+		local.Get("/schedule", r.schedule)
 	})
 }
 
@@ -93,3 +103,183 @@ func (r *moduleRouter) halt(w http.ResponseWriter, req *http.Request) {
 	}
 	w.WriteHeader(200)
 }
+
+// runtime moves a module between the plugin and process runtimes
+func (r *moduleRouter) runtime(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	runtime := chi.URLParam(req, "runtime")
+	if id == "" || runtime == "" {
+		http.Error(w, "module and runtime must be provided", 400)
+		return
+	}
+	err := r.service.SetRuntime(id, runtime)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	w.WriteHeader(200)
+}
+
+type configRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// config changes one of the module's variables, the running module is notified of the change
+func (r *moduleRouter) config(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "module not provided", 400)
+		return
+	}
+	var buf bytes.Buffer
+	_, err := buf.ReadFrom(req.Body)
+	if err != nil {
+		http.Error(w, "could not parse config", 400)
+		return
+	}
+	ref := configRequest{}
+	err = json.Unmarshal(buf.Bytes(), &ref)
+	if err != nil {
+		http.Error(w, "could not parse config", 400)
+		return
+	}
+	err = r.service.Configure(id, ref.Key, ref.Value)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	w.WriteHeader(200)
+}
+
+// maxPackage is the largest module package accepted for install
+const maxPackage = 64 << 20
+
+// install stages the module package in the request body and installs it in the background, the outcome is
+// reported through the module's state
+func (r *moduleRouter) install(w http.ResponseWriter, req *http.Request) {
+	manifest, err := r.service.Stage(http.MaxBytesReader(w, req.Body, maxPackage))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	go func() {
+		_, err := r.service.Install(manifest.Name)
+		if err != nil {
+			log.Err(err)
+		}
+	}()
+	marshal, err := json.Marshal(manifest)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(202)
+	_, _ = w.Write(marshal)
+}
+
+type moduleStatus struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Previous string `json:"previous"`
+	State    string `json:"state"`
+	Running  bool   `json:"running"`
+}
+
+// status reports the installed version and state of a module
+func (r *moduleRouter) status(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	module, err := r.service.FindByName(id)
+	if err != nil {
+		http.Error(w, "module not found", 404)
+		return
+	}
+	marshal, err := json.Marshal(moduleStatus{
+		Name:     module.Name,
+		Version:  module.Version,
+		Previous: module.Previous,
+		State:    module.State,
+		Running:  module.Running,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+// rollback restores the version of the module installed before the current one in the background, the outcome
+// is reported through the module's state
+func (r *moduleRouter) rollback(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	module, err := r.service.FindByName(id)
+	if err != nil {
+		http.Error(w, "module not found", 404)
+		return
+	}
+	if module.Previous == "" {
+		http.Error(w, "module has no previous version", 400)
+		return
+	}
+	go func() {
+		err := r.service.Rollback(id)
+		if err != nil {
+			log.Err(err)
+		}
+	}()
+	w.WriteHeader(202)
+}
+
+// health reports the module's health and restart history
+func (r *moduleRouter) health(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "module not provided", 400)
+		return
+	}
+	health, err := r.service.Health(id)
+	if err != nil {
+		http.Error(w, "module not found", 404)
+		return
+	}
+	marshal, err := json.Marshal(health)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+// schedule reports when the module last updated and how late its updates are
+func (r *moduleRouter) schedule(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "module not provided", 400)
+		return
+	}
+	schedule, err := r.service.Schedule(id)
+	if err != nil {
+		http.Error(w, err.Error(), 404)
+		return
+	}
+	marshal, err := json.Marshal(schedule)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+func (r *moduleRouter) schedules(w http.ResponseWriter, req *http.Request) {
+	marshal, err := json.Marshal(r.service.Schedules())
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"io"
 	"net/http"
+	"udap/internal/core/domain"
+	"udap/platform/jwt"
 )
 
 type Routable interface {
@@ -15,6 +17,14 @@ type Routable interface {
 	RouteExternal(router chi.Router)
 }
 
+// requester describes the authenticated user making a request as an invocation source
+func requester(req *http.Request) domain.Source {
+	return domain.Source{
+		Kind: domain.USER,
+		Id:   jwt.Identity(req),
+	}
+}
+
 type PersistentRoute interface {
 	create(w http.ResponseWriter, req *http.Request)
 	update(w http.ResponseWriter, req *http.Request)
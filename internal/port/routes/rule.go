@@ -0,0 +1,158 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type ruleRouter struct {
+	service ports.RuleService
+}
+
+func NewRuleRouter(service ports.RuleService) Routable {
+	return &ruleRouter{
+		service: service,
+	}
+}
+
+func (r *ruleRouter) RouteInternal(router chi.Router) {
+	router.Post("/rules/create", r.create)
+	router.Route("/rules/{id}", func(local chi.Router) {
+		local.Post("/update", r.update)
+		local.Post("/delete", r.delete)
+		local.Post("/enable", r.enable)
+		local.Post("/disable", r.disable)
+	})
+}
+
+func (r *ruleRouter) RouteExternal(_ chi.Router) {
+
+}
+
+func (r *ruleRouter) create(w http.ResponseWriter, req *http.Request) {
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read rule", 400)
+		return
+	}
+
+	rule := domain.Rule{}
+	err = json.Unmarshal(buf.Bytes(), &rule)
+	if err != nil {
+		http.Error(w, "could not parse rule", 400)
+		return
+	}
+
+	err = r.service.Create(&rule)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *ruleRouter) update(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "rule id not provided", 401)
+		return
+	}
+
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read rule", 400)
+		return
+	}
+
+	delta := domain.Rule{}
+	err = json.Unmarshal(buf.Bytes(), &delta)
+	if err != nil {
+		http.Error(w, "could not parse rule", 400)
+		return
+	}
+
+	rule, err := r.service.FindById(id)
+	if err != nil {
+		http.Error(w, "rule not found", 404)
+		return
+	}
+
+	rule.Name = delta.Name
+	rule.Description = delta.Description
+	rule.Entity = delta.Entity
+	rule.Key = delta.Key
+	rule.Mode = delta.Mode
+	rule.Value = delta.Value
+	rule.Conditions = delta.Conditions
+	rule.After = delta.After
+	rule.Before = delta.Before
+	rule.Target = delta.Target
+	rule.TargetId = delta.TargetId
+
+	err = r.service.Update(rule)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *ruleRouter) delete(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "rule id not provided", 401)
+		return
+	}
+
+	err := r.service.Delete(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *ruleRouter) enable(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "rule id not provided", 401)
+		return
+	}
+
+	err := r.service.Enable(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *ruleRouter) disable(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "rule id not provided", 401)
+		return
+	}
+
+	err := r.service.Disable(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
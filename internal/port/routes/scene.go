@@ -0,0 +1,208 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+	"udap/internal/core/domain"
+	"udap/internal/core/ports"
+)
+
+type sceneRouter struct {
+	service ports.SceneService
+}
+
+func NewSceneRouter(service ports.SceneService) Routable {
+	return &sceneRouter{
+		service: service,
+	}
+}
+
+func (r *sceneRouter) RouteInternal(router chi.Router) {
+	router.Post("/scenes/create", r.create)
+	router.Post("/scenes/capture", r.capture)
+	router.Route("/scenes/{id}", func(local chi.Router) {
+		local.Post("/apply", r.apply)
+		local.Post("/recapture", r.recapture)
+		local.Post("/update", r.update)
+		local.Post("/delete", r.delete)
+	})
+}
+
+func (r *sceneRouter) RouteExternal(_ chi.Router) {
+
+}
+
+func (r *sceneRouter) create(w http.ResponseWriter, req *http.Request) {
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read scene", 400)
+		return
+	}
+
+	scene := domain.Scene{}
+	err = json.Unmarshal(buf.Bytes(), &scene)
+	if err != nil {
+		http.Error(w, "could not parse scene", 400)
+		return
+	}
+
+	err = r.service.Create(&scene)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+type captureRequest struct {
+	Name string   `json:"name"`
+	Zone string   `json:"zone"`
+	Keys []string `json:"keys"`
+}
+
+func (r *sceneRouter) capture(w http.ResponseWriter, req *http.Request) {
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read capture", 400)
+		return
+	}
+
+	capture := captureRequest{}
+	err = json.Unmarshal(buf.Bytes(), &capture)
+	if err != nil {
+		http.Error(w, "could not parse capture", 400)
+		return
+	}
+
+	if capture.Zone == "" {
+		http.Error(w, "zone id not provided", 400)
+		return
+	}
+
+	scene, err := r.service.Capture(capture.Name, capture.Zone, capture.Keys)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	marshal, err := json.Marshal(scene)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+// apply restores the scene, an optional transition such as ?transition=3s overrides the scene's own
+func (r *sceneRouter) apply(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "scene id not provided", 401)
+		return
+	}
+
+	var err error
+	if value := req.URL.Query().Get("transition"); value != "" {
+		var transition time.Duration
+		transition, err = time.ParseDuration(value)
+		if err != nil {
+			http.Error(w, "invalid transition", 400)
+			return
+		}
+		err = r.service.ApplyWithTransition(id, transition, requester(req))
+	} else {
+		err = r.service.Apply(id, requester(req))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *sceneRouter) recapture(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "scene id not provided", 401)
+		return
+	}
+
+	err := r.service.Recapture(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *sceneRouter) update(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "scene id not provided", 401)
+		return
+	}
+
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read scene", 400)
+		return
+	}
+
+	delta := domain.Scene{}
+	err = json.Unmarshal(buf.Bytes(), &delta)
+	if err != nil {
+		http.Error(w, "could not parse scene", 400)
+		return
+	}
+
+	scene, err := r.service.FindById(id)
+	if err != nil {
+		http.Error(w, "scene not found", 404)
+		return
+	}
+
+	scene.Name = delta.Name
+	scene.Description = delta.Description
+	scene.Values = delta.Values
+	scene.Transition = delta.Transition
+
+	err = r.service.Update(scene)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
+func (r *sceneRouter) delete(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "scene id not provided", 401)
+		return
+	}
+
+	err := r.service.Delete(id)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.WriteHeader(200)
+}
@@ -22,6 +22,8 @@ func (r *subroutineRouter) RouteInternal(router chi.Router) {
 	router.Post("/subroutines/{id}/update", r.update)
 	router.Post("/subroutines/{id}/macros/{macro}/add", r.addMacro)
 	router.Post("/subroutines/{id}/macros/{macro}/remove", r.removeMacro)
+	router.Post("/subroutines/{id}/scenes/{scene}/add", r.addScene)
+	router.Post("/subroutines/{id}/scenes/{scene}/remove", r.removeScene)
 }
 
 func (r *subroutineRouter) RouteExternal(_ chi.Router) {
@@ -71,6 +73,48 @@ func (r *subroutineRouter) addMacro(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(200)
 }
 
+func (r *subroutineRouter) addScene(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "subroutine key not provided", 401)
+		return
+	}
+
+	scene := chi.URLParam(req, "scene")
+	if scene == "" {
+		http.Error(w, "scene key not provided", 401)
+		return
+	}
+
+	err := r.service.AddScene(id, scene)
+	if err != nil {
+		http.Error(w, "failed to create association", 401)
+		return
+	}
+	w.WriteHeader(200)
+}
+
+func (r *subroutineRouter) removeScene(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if id == "" {
+		http.Error(w, "subroutine key not provided", 401)
+		return
+	}
+
+	scene := chi.URLParam(req, "scene")
+	if scene == "" {
+		http.Error(w, "scene key not provided", 401)
+		return
+	}
+
+	err := r.service.RemoveScene(id, scene)
+	if err != nil {
+		http.Error(w, "failed to delete association", 401)
+		return
+	}
+	w.WriteHeader(200)
+}
+
 func (r *subroutineRouter) update(w http.ResponseWriter, req *http.Request) {
 	idNum := chi.URLParam(req, "id")
 	if idNum == "" {
@@ -111,6 +155,9 @@ func (r *subroutineRouter) update(w http.ResponseWriter, req *http.Request) {
 	id.Group = sr.Group
 	id.TriggerId = sr.TriggerId
 	id.Macros = sr.Macros
+	id.Steps = sr.Steps
+	id.Policy = sr.Policy
+	id.Retries = sr.Retries
 
 	err = r.service.Update(id)
 	if err != nil {
@@ -134,7 +181,7 @@ func (r *subroutineRouter) run(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, "access key not provided", 401)
 		return
 	}
-	err := r.service.Run(key)
+	err := r.service.Run(key, requester(req))
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
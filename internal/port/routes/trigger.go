@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"time"
 	"udap/internal/core/domain"
 	"udap/internal/core/ports"
 )
@@ -18,6 +19,8 @@ type triggerRouter struct {
 func (r *triggerRouter) RouteInternal(router chi.Router) {
 	router.Post("/triggers/create", r.create)
 	router.Post("/triggers/{triggerId}/invoke", r.invoke)
+	router.Get("/triggers/{triggerId}/schedule", r.nextFire)
+	router.Post("/triggers/{triggerId}/schedule", r.schedule)
 
 }
 
@@ -40,7 +43,7 @@ func (r *triggerRouter) invoke(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err = r.service.Trigger(byId.Name)
+	err = r.service.TriggerFrom(byId.Name, requester(req))
 	if err != nil {
 		return
 	}
@@ -48,6 +51,60 @@ func (r *triggerRouter) invoke(w http.ResponseWriter, req *http.Request) {
 	w.WriteHeader(200)
 }
 
+type triggerSchedule struct {
+	Schedule domain.Schedule `json:"schedule"`
+	NextFire time.Time       `json:"nextFire"`
+}
+
+func (r *triggerRouter) nextFire(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "triggerId")
+
+	trigger, err := r.service.FindById(id)
+	if err != nil {
+		http.Error(w, "trigger not found", 404)
+		return
+	}
+
+	marshal, err := json.Marshal(triggerSchedule{
+		Schedule: trigger.Schedule,
+		NextFire: trigger.NextFire,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshal)
+}
+
+func (r *triggerRouter) schedule(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "triggerId")
+
+	buf := bytes.Buffer{}
+	_, err := buf.ReadFrom(req.Body)
+	defer req.Body.Close()
+	if err != nil {
+		http.Error(w, "could not read schedule", 400)
+		return
+	}
+
+	schedule := domain.Schedule{}
+	err = json.Unmarshal(buf.Bytes(), &schedule)
+	if err != nil {
+		http.Error(w, "could not parse schedule", 400)
+		return
+	}
+
+	err = r.service.Schedule(id, schedule)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	w.WriteHeader(200)
+}
+
 func (r *triggerRouter) create(w http.ResponseWriter, req *http.Request) {
 
 	buf := bytes.Buffer{}
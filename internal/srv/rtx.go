@@ -5,6 +5,7 @@ package srv
 import (
 	"gorm.io/gorm"
 	"udap/internal/controller"
+	"udap/internal/core/bus"
 	"udap/internal/core/domain"
 	"udap/internal/port/routes"
 	"udap/internal/srv/store"
@@ -44,10 +45,12 @@ type sys struct {
 	loaded bool
 }
 
+// WithWatch gives a service the controller's bus and publishes its current state, services publish later changes
+// to the bus as they happen
 func (r *sys) WithWatch(mutation Watch) {
-
-	mutation.Watch(r.ctrl.RX)
-
+	if b, ok := mutation.(interface{ UseBus(events *bus.Bus) }); ok {
+		b.UseBus(r.ctrl.Bus)
+	}
 	err := mutation.EmitAll()
 	if err != nil {
 		return
@@ -6,6 +6,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Acap
@@ -26,6 +27,11 @@ func init() {
 	}
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (a *Acap) Setup() (plugin.Config, error) {
 	err := a.UpdateInterval(time.Minute * 5)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 	"udap/platform/atlas"
 )
 
@@ -76,6 +77,10 @@ func init() {
 		Description: "General AI",
 		Version:     "0.0.1",
 		Author:      "Braden Nicholson",
+		Grants: []plugin.Grant{
+			// Atlas speaks through the sentry and controls the entities of the zones it is asked about
+			{Capability: domain.CONTROL},
+		},
 	}
 	Module.Config = config
 	Module.responses = map[string][]string{
@@ -136,6 +141,11 @@ func init() {
 	Module.voice = "default"
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (w *Atlas) chooseRandom(response string) error {
 	i := w.responses[response]
 	if i == nil {
@@ -757,7 +767,7 @@ func (w *Atlas) register() error {
 	w.recognizerStatusChannel = make(chan string, 12)
 
 	// Begin listening on the new channels
-	go w.listen()
+	w.Go(w.listen)
 
 	err = w.Attributes.Register(&bufferAttribute)
 	if err != nil {
@@ -821,12 +831,12 @@ func (w *Atlas) Run() error {
 	w.status.Recognizer = "offline"
 	w.status.Synthesizer = "idle"
 
-	go func() {
+	w.Go(func() {
 		err = http.ListenAndServe("10.0.1.2:5055", r)
 		if err != nil {
 			w.ErrF("Atlas endpoint terminated")
 		}
-	}()
+	})
 
 	return nil
 
@@ -12,6 +12,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module CalDav
@@ -90,6 +91,7 @@ func init() {
 		{
 			Name:    "server",
 			Default: "https://example.com",
+			Type:    domain.URL,
 			Description: "The module will poll calendar info from any CalDAV server. " +
 				"Ensure the address begins with 'https://'.",
 		},
@@ -107,6 +109,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 type Event struct {
 	Description string    `json:"description"`
 	Summary     string    `json:"summary"`
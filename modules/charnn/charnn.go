@@ -13,6 +13,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module CharNN
@@ -359,9 +360,9 @@ func (c *CharNN) Run() error {
 		return nil
 	}
 	c.receiver = make(chan domain.Attribute, 8)
-	go func() {
+	c.Go(func() {
 		c.mux()
-	}()
+	})
 
 	entity := domain.Entity{
 		Name:   "charnn",
@@ -438,3 +439,8 @@ func init() {
 
 	Module.Config = config
 }
+
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Chrono
@@ -34,6 +35,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (c *Chrono) mux() {
 	for attribute := range c.request {
 		c.handleRequest(attribute)
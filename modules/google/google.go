@@ -3,12 +3,10 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -18,6 +16,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Google
@@ -49,18 +48,49 @@ type ModeSet struct {
 }
 
 func init() {
-
+	configVariables := []plugin.Variable{
+		{
+			Name:        "auth",
+			Type:        domain.SECRET,
+			Default:     "unset",
+			Description: "The authorization code of the linked Google account, set by the module.",
+		},
+		{
+			Name:        "token",
+			Type:        domain.SECRET,
+			Default:     "unset",
+			Description: "The access token of the linked Google account, set by the module.",
+		},
+		{
+			Name:        "refresh",
+			Type:        domain.SECRET,
+			Default:     "unset",
+			Description: "The refresh token of the linked Google account, set by the module.",
+		},
+		{
+			Name:        "expires",
+			Type:        domain.INT,
+			Default:     "0",
+			Description: "When the access token expires, in unix seconds.",
+		},
+	}
 	config := plugin.Config{
 		Name:        "googleHome",
 		Type:        "module",
 		Description: "Google Smart Home products",
 		Version:     "0.0.1",
 		Author:      "Braden Nicholson",
+		Variables:   configVariables,
 	}
 
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (c *Google) mux() {
 	for attribute := range c.request {
 		c.handleRequest(attribute)
@@ -317,7 +347,7 @@ func (c *Google) runOAuth() error {
 	})
 	srv.Handler = router
 
-	go func() {
+	c.Go(func() {
 		srv.Addr = ":8976"
 
 		err = srv.ListenAndServe()
@@ -325,7 +355,7 @@ func (c *Google) runOAuth() error {
 			log.Err(err)
 		}
 		fmt.Println("Server closed.")
-	}()
+	})
 
 	// Wait for the auth code
 	auth := <-authCode
@@ -348,27 +378,11 @@ func (c *Google) fetchToken() error {
 	if err != nil {
 		return err
 	}
-	// Print out that URL for reference
-	fmt.Println(url)
-
-	cli := http.Client{}
-	// Send the request
-	post, err := cli.Post(url, "application/json", nil)
-	if err != nil {
-		return err
-	}
-	// Read the stream to an array of bytes
-	all, err := io.ReadAll(post.Body)
-	if err != nil {
-		return err
-	}
-	// Close the stream
-	defer post.Body.Close()
 
 	// Prepare the response object
 	rt := TokenResponse{}
-	// Read the response into the struct
-	err = json.Unmarshal(all, &rt)
+	// Send the request and read the response into the struct
+	err = c.Web().Post(url, nil).Header("Content-Type", "application/json").JSON(&rt)
 	if err != nil {
 		return err
 	}
@@ -431,38 +445,19 @@ func (c *Google) sendAuthenticatedRequest(data []byte) ([]byte, error) {
 		}
 	}
 
-	var buf bytes.Buffer
-	buf.Write(data)
-
-	request, err := http.NewRequest("POST", fmt.Sprintf(commandURL, c.deviceId), &buf)
-	if err != nil {
-		return []byte{}, err
-	}
-
 	token, err := c.GetConfig("token")
 	if err != nil {
 		log.Err(err)
 		return []byte{}, err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-
-	client := http.Client{}
-	defer client.CloseIdleConnections()
-	response, err := client.Do(request)
+	response, err := c.Web().Post(fmt.Sprintf(commandURL, c.deviceId), json.RawMessage(data)).
+		Authorization("Bearer", token).Do()
 	if err != nil {
 		return []byte{}, err
 	}
 
-	var res bytes.Buffer
-
-	_, err = res.ReadFrom(response.Body)
-	if err != nil {
-		return []byte{}, err
-	}
-	_ = response.Body.Close()
-	return res.Bytes(), nil
+	return response.Body, nil
 }
 
 func (c *Google) sendAPIRequest() ([]byte, error) {
@@ -473,44 +468,24 @@ func (c *Google) sendAPIRequest() ([]byte, error) {
 		}
 	}
 
-	var buf bytes.Buffer
-	buf.Write([]byte(""))
 	project, foundProjectId := os.LookupEnv("googleProjectId")
 	if !foundProjectId {
 		return []byte{}, fmt.Errorf("project id environment variable not set")
 	}
 
-	request, err := http.NewRequest("GET", fmt.Sprintf(apiURL, project), nil)
-	if err != nil {
-		return []byte{}, err
-	}
-
 	token, err := c.GetConfig("token")
 	if err != nil {
 		log.Err(err)
 		return []byte{}, err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	//fmt.Println(token)
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-
-	client := http.Client{}
-	defer client.CloseIdleConnections()
-	response, err := client.Do(request)
+	response, err := c.Web().Get(fmt.Sprintf(apiURL, project)).Header("Content-Type", "application/json").
+		Authorization("Bearer", token).Do()
 	if err != nil {
 		return []byte{}, err
 	}
 
-	var res bytes.Buffer
-
-	_, err = res.ReadFrom(response.Body)
-	if err != nil {
-		return []byte{}, err
-	}
-	//fmt.Println(res.String())
-	_ = response.Body.Close()
-	return res.Bytes(), nil
+	return response.Body, nil
 }
 
 type Device struct {
@@ -818,26 +793,6 @@ func (c *Google) initDevices() error {
 }
 func (c *Google) Run() error {
 
-	err := c.InitConfig("auth", "unset")
-	if err != nil {
-		return err
-	}
-
-	err = c.InitConfig("token", "unset")
-	if err != nil {
-		return err
-	}
-
-	err = c.InitConfig("refresh", "unset")
-	if err != nil {
-		return err
-	}
-
-	err = c.InitConfig("expires", "0")
-	if err != nil {
-		return err
-	}
-
 	c.request = make(chan domain.Attribute)
 	c.oauth = sync.Mutex{}
 
@@ -851,17 +806,17 @@ func (c *Google) Run() error {
 		return err
 	}
 
-	go c.mux()
+	c.Go(c.mux)
 
 	if auth == "unset" || token == "unset" {
-		go func() {
+		c.Go(func() {
 			errf := c.connectAccount()
 			if errf != nil {
 				log.Err(errf)
 			}
-		}()
+		})
 	} else {
-		log.Event("Google Auth Code: %s", auth)
+		log.Event("Google account is linked.")
 		if c.shouldRefresh() {
 			err = c.refresh()
 			if err != nil {
@@ -883,14 +838,7 @@ func (c *Google) connectAccount() error {
 		return err
 	}
 
-	// Get the auth code
-	auth, err := c.GetConfig("auth")
-	if err != nil {
-		return err
-	}
-
-	// Print out the results
-	log.Event("Code: %s", auth)
+	log.Event("Google account authorized.")
 
 	// Try to get a token
 	err = c.fetchToken()
@@ -934,28 +882,8 @@ func (c *Google) refresh() error {
 		return fmt.Errorf("client id environment variable not set")
 	}
 
-	request, err := http.NewRequest("POST", fmt.Sprintf(refreshURL, clientId, secret, refresh), nil)
-	if err != nil {
-		return err
-	}
-
-	//fmt.Println(fmt.Sprintf(refreshURL, clientId, secret, refresh))
-
-	client := http.Client{}
-	defer client.CloseIdleConnections()
-
-	response, err := client.Do(request)
-	if err != nil {
-		return err
-	}
-
-	all, err := io.ReadAll(response.Body)
-	if err != nil {
-		return err
-	}
 	rc := RefreshClass{}
-
-	err = json.Unmarshal(all, &rc)
+	err = c.Web().Post(fmt.Sprintf(refreshURL, clientId, secret, refresh), nil).JSON(&rc)
 	if err != nil {
 		return err
 	}
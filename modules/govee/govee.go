@@ -3,12 +3,10 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/gerow/go-color"
 	_ "github.com/gerow/go-color"
-	"net/http"
 	"os"
 	"strconv"
 	"sync"
@@ -16,8 +14,11 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
+const apiHost = "developer-api.govee.com"
+
 type Color struct {
 	R int `json:"r"`
 	B int `json:"b"`
@@ -82,30 +83,45 @@ type Govee struct {
 	mutable   chan domain.Attribute
 	immutable chan domain.Attribute
 	done      chan bool
+	key       string
+	keyMutex  sync.RWMutex
 }
 
 func init() {
+	configVariables := []plugin.Variable{
+		{
+			Name:        "key",
+			Type:        domain.SECRET,
+			Description: "A Govee developer api key, the 'goveeApi' environment variable is used if it is not set.",
+		},
+	}
 	config := plugin.Config{
 		Name:        "govee",
 		Type:        "module",
 		Description: "Govee Light Controller",
 		Version:     "0.1.1",
 		Author:      "Braden Nicholson",
+		Variables:   configVariables,
 	}
 
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (g *Govee) listen() {
 	for {
 		select {
 		case attribute := <-g.mutable:
-			go func() {
+			g.Go(func() {
 				err := g.setState(attribute)
 				if err != nil {
 					g.Err(err)
 				}
-			}()
+			})
 		case attribute := <-g.immutable:
 			g.WarnF("Attribute '%s' is immutable", attribute.Key)
 		case <-g.done:
@@ -149,102 +165,47 @@ func (g *Govee) fetchDevices() ([]Device, error) {
 func (g *Govee) sendApiDeviceRequest(id string, method string, path string, body json.RawMessage) (json.RawMessage,
 	error) {
 
-	var buf bytes.Buffer
-	buf.Write(body)
-
-	c := http.Client{}
-	defer c.CloseIdleConnections()
-	c.Timeout = time.Millisecond * 2000
-	p := fmt.Sprintf("https://developer-api.govee.com/v1/devices%s", path)
-	request, err := http.NewRequest(method, p, &buf)
-	if err != nil {
-		return nil, err //
-	}
-
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Govee-API-Key", os.Getenv("goveeApi"))
-	do, err := c.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	buf = bytes.Buffer{}
-	_, err = buf.ReadFrom(do.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	r := Response{}
-	err = json.Unmarshal(buf.Bytes(), &r)
-	if err != nil {
-		return nil, err
-	}
+	data, err := g.request(method, path, body, time.Millisecond*2000)
 
-	rl := RateLimit{
-		Remaining: do.Header.Get("API-RateLimit-Remaining"),
-		Limit:     do.Header.Get("API-RateLimit-Limit"),
-		Reset:     do.Header.Get("API-RateLimit-Reset"),
-	}
-
-	marshal, err := json.Marshal(rl)
-	if err != nil {
-		return nil, err
-	}
-
-	err = g.Attributes.Update(id, "api", string(marshal), time.Now())
-	if err != nil {
-		return nil, err
+	// Report the rate limit the api returned, even if the request failed
+	limit, ok := g.Web().Limit(apiHost)
+	if ok {
+		rl := RateLimit{
+			Remaining: strconv.Itoa(limit.Remaining),
+			Limit:     strconv.Itoa(limit.Limit),
+			Reset:     strconv.FormatInt(limit.Reset.Unix(), 10),
+		}
+		marshal, err := json.Marshal(rl)
+		if err != nil {
+			return nil, err
+		}
+		err = g.Attributes.Update(id, "api", string(marshal), time.Now())
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	_ = do.Body.Close()
-
-	if r.Code != 200 {
-		g.WarnF("API Request failed: %s", fmt.Errorf("update failed '%s'", r.Message))
-		return nil, fmt.Errorf("update failed '%s'", r.Message)
-	}
-	return r.Data, nil
+	return data, err
 }
 
 func (g *Govee) sendApiRequest(method string, path string, body json.RawMessage) (json.RawMessage, error) {
+	return g.request(method, path, body, time.Millisecond*3000)
+}
 
-	var buf bytes.Buffer
-	buf.Write(body)
-
-	c := http.Client{}
-	defer c.CloseIdleConnections()
-	c.Timeout = time.Millisecond * 3000
-	p := fmt.Sprintf("https://developer-api.govee.com/v1/devices%s", path)
-	request, err := http.NewRequest(method, p, &buf)
-	if err != nil {
-		return nil, err //
-	}
-
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Govee-API-Key", os.Getenv("goveeApi"))
-	do, err := c.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	buf = bytes.Buffer{}
-	_, err = buf.ReadFrom(do.Body)
-	if err != nil {
-		return nil, err
-	}
-
+// request calls the govee api, the shared client retries and holds back requests when the rate limit is reached
+func (g *Govee) request(method string, path string, body json.RawMessage, timeout time.Duration) (json.RawMessage,
+	error) {
 	r := Response{}
-	err = json.Unmarshal(buf.Bytes(), &r)
+	p := fmt.Sprintf("https://%s/v1/devices%s", apiHost, path)
+	err := g.Web().New(method, p, body).Header("Govee-API-Key", g.apiKey()).Timeout(timeout).JSON(&r)
 	if err != nil {
+		g.WarnF("API Request failed: %s", err)
 		return nil, err
 	}
-
-	_ = do.Body.Close()
-
 	if r.Code != 200 {
 		g.WarnF("API Request failed: %s", fmt.Errorf("update failed '%s'", r.Message))
 		return nil, fmt.Errorf("update failed '%s'", r.Message)
 	}
-
 	return r.Data, nil
 }
 
@@ -500,6 +461,7 @@ func (g *Govee) generateAttributes(id string) []*domain.Attribute {
 		Type:    "range",
 		Order:   1,
 		Entity:  id,
+		Schema:  domain.RangeSchema(0, 100, 1, "%"),
 		Channel: g.mutable,
 	}
 	cct := domain.Attribute{
@@ -509,6 +471,7 @@ func (g *Govee) generateAttributes(id string) []*domain.Attribute {
 		Type:    "range",
 		Order:   3,
 		Entity:  id,
+		Schema:  domain.RangeSchema(2000, 9000, 1, "K"),
 		Channel: g.mutable,
 	}
 	hue := domain.Attribute{
@@ -518,6 +481,7 @@ func (g *Govee) generateAttributes(id string) []*domain.Attribute {
 		Type:    "range",
 		Order:   4,
 		Entity:  id,
+		Schema:  domain.RangeSchema(0, 360, 1, "°"),
 		Channel: g.mutable,
 	}
 	// Immutable
@@ -555,13 +519,14 @@ func (g *Govee) push() error {
 	wg := sync.WaitGroup{}
 	wg.Add(len(g.devices))
 	for s, d := range g.devices {
-		go func(device Device, id string) {
+		device, id := d, s
+		g.Go(func() {
 			defer wg.Done()
 			_, err := g.getAllStates(device, id)
 			if err != nil {
 				log.Err(err)
 			}
-		}(d, s)
+		})
 	}
 	wg.Wait()
 
@@ -575,8 +540,37 @@ func (g *Govee) Update() error {
 	return nil
 }
 
+// apiKey returns the configured api key, or the key from the environment if none is configured
+func (g *Govee) apiKey() string {
+	g.keyMutex.RLock()
+	defer g.keyMutex.RUnlock()
+	if g.key == "" {
+		return os.Getenv("goveeApi")
+	}
+	return g.key
+}
+
+// placeholderKey was stored as the api key by earlier versions of the module until a key was configured
+const placeholderKey = "<api key>"
+
+// ConfigChanged applies a new api key without reloading the module
+func (g *Govee) ConfigChanged(key string, value string) error {
+	if key != "key" {
+		return nil
+	}
+	if value == placeholderKey {
+		value = ""
+	}
+	g.keyMutex.Lock()
+	g.key = value
+	g.keyMutex.Unlock()
+	return nil
+}
+
 func (g *Govee) Run() error {
-	err := g.InitConfig("key", "<api key>")
+	// The key is not set until it is configured
+	key, _ := g.GetConfig("key")
+	err := g.ConfigChanged("key", key)
 	if err != nil {
 		return err
 	}
@@ -589,7 +583,7 @@ func (g *Govee) Run() error {
 	g.immutable = make(chan domain.Attribute)
 	g.mutable = make(chan domain.Attribute)
 	g.done = make(chan bool)
-	go g.listen()
+	g.Go(g.listen)
 	for _, device := range devices {
 
 		s := &domain.Entity{
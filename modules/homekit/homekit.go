@@ -9,10 +9,13 @@ import (
 	"github.com/brutella/hc/characteristic"
 	"github.com/brutella/hc/service"
 	"os"
+	"sync"
 	"time"
+	"udap/internal/core/domain"
 	"udap/internal/core/ports"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Homekit
@@ -23,6 +26,9 @@ type Homekit struct {
 	transport hc.Transport
 	config    hc.Config
 	devices   map[string]*service.Service
+	switches  map[string]*service.Switch
+	lights    map[string]*spectrum
+	mutex     sync.Mutex
 }
 
 func init() {
@@ -36,8 +42,15 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (h *Homekit) Setup() (plugin.Config, error) {
 	h.devices = map[string]*service.Service{}
+	h.switches = map[string]*service.Switch{}
+	h.lights = map[string]*spectrum{}
 	h.bridge = accessory.NewBridge(accessory.Info{
 		Name:             "udap",
 		ID:               1,
@@ -87,6 +100,10 @@ func (h *Homekit) Host() {
 				return
 			}
 
+			h.mutex.Lock()
+			h.lights[entity.Id] = device.spectrum
+			h.mutex.Unlock()
+
 			accessories = append(accessories, device.Accessory)
 		case "switch":
 			info := accessory.Info{
@@ -100,6 +117,10 @@ func (h *Homekit) Host() {
 			device := accessory.NewSwitch(info)
 			syncSwitch(device.Switch, h.Attributes, entity.Id)
 
+			h.mutex.Lock()
+			h.switches[entity.Id] = device.Switch
+			h.mutex.Unlock()
+
 			accessories = append(accessories, device.Accessory)
 		default:
 
@@ -116,12 +137,41 @@ func (h *Homekit) Host() {
 
 }
 
-func (h *Homekit) Run() error {
+// OnEmit pushes attribute changes to the accessories bridged to HomeKit
+func (h *Homekit) OnEmit(mutation domain.Mutation) error {
+	attribute, ok := mutation.Body.(domain.Attribute)
+	if !ok {
+		return nil
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if device, found := h.switches[attribute.Entity]; found && attribute.Key == "on" {
+		device.On.SetValue(attribute.AsBool())
+	}
+	if light, found := h.lights[attribute.Entity]; found {
+		switch attribute.Key {
+		case "on":
+			light.On.SetValue(attribute.AsBool())
+		case "dim":
+			light.Dim.SetValue(attribute.AsInt())
+		}
+	}
+	return nil
+}
 
+func (h *Homekit) Run() error {
+	// Receive changes to the attributes shown in HomeKit
+	err := h.Subscribe(domain.MutationFilter{
+		Operations: []string{"attribute"},
+		Keys:       []string{"on", "dim"},
+	})
+	if err != nil {
+		return err
+	}
 	// Wait for modules to load
 	time.Sleep(time.Second * 5)
 	// Begin hosting in a new thead
-	go h.Host()
+	h.Go(h.Host)
 
 	return nil
 }
@@ -150,13 +200,6 @@ func syncSwitch(p *service.Switch, a ports.AttributeService, id string) {
 		}
 		return composite.AsBool()
 	})
-
-	// a.WatchSingle(fmt.Sprintf("%s.%s", id, "on"), func(data interface{}) error {
-	// 	attr := *data.(*models.Attribute)
-	// 	p.On.UpdateValue(attr.Request)
-	// 	return nil
-	// })
-
 }
 
 type spectrumLight struct {
@@ -200,18 +243,6 @@ func (s *spectrumLight) syncAttributes(a ports.AttributeService, id string) erro
 		return composite.AsInt()
 	})
 
-	// a.WatchSingle(fmt.Sprintf("%s.%s", id, "on"), func(data interface{}) error {
-	// 	attr := *data.(*models.Attribute)
-	// 	s.spectrum.On.UpdateValue(attr.Request)
-	// 	return nil
-	// })
-	//
-	// a.WatchSingle(fmt.Sprintf("%s.%s", id, "dim"), func(data interface{}) error {
-	// 	attr := *data.(*models.Attribute)
-	// 	s.spectrum.Dim.UpdateValue(attr.Request)
-	// 	return nil
-	// })
-
 	return nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module HS100
@@ -35,6 +36,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (h *HS100) findDevices() error {
 	devices, err := hs100.Discover("10.0.2.0/24", configuration.Default().WithTimeout(time.Second*5))
 	if err != nil {
@@ -151,13 +157,13 @@ func (h *HS100) Update() error {
 
 	h.mod = (h.mod + 1) % 48
 	if h.mod == 0 {
-		go func() {
+		h.Go(func() {
 			err := h.findDevices()
 			if err != nil {
 				h.LogF("Error: %s", err.Error())
 				return
 			}
-		}()
+		})
 	}
 	return h.pull()
 }
@@ -165,7 +171,7 @@ func (h *HS100) Update() error {
 // Run is called after Setup, concurrent with Update
 func (h *HS100) Run() (err error) {
 	h.mux = make(chan domain.Attribute, 8)
-	go h.muxLoop()
+	h.Go(h.muxLoop)
 	err = h.findDevices()
 	if err != nil {
 		return err
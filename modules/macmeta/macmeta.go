@@ -9,6 +9,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module MacMeta
@@ -34,6 +35,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (v *MacMeta) requestState(state bool) {
 	select {
 	case v.request <- state:
@@ -127,7 +133,7 @@ func (v *MacMeta) createDisplaySwitch() error {
 		Entity:  v.terminalId,
 	}
 
-	go func() {
+	v.Go(func() {
 		for {
 			select {
 			case attribute := <-on.Channel:
@@ -158,7 +164,7 @@ func (v *MacMeta) createDisplaySwitch() error {
 				}
 			}
 		}
-	}()
+	})
 
 	err = v.Attributes.Register(&on)
 	if err != nil {
@@ -217,7 +223,7 @@ func (v *MacMeta) Setup() (plugin.Config, error) {
 	if err != nil {
 		return plugin.Config{}, err
 	}
-	go v.listen()
+	v.Go(v.listen)
 	return v.Config, nil
 }
 
@@ -11,6 +11,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Midi
@@ -35,11 +36,19 @@ func init() {
 		Description: "A midi module...",
 		Version:     "0.0.1",
 		Author:      "Braden Nicholson",
+		Grants: []plugin.Grant{
+			{Capability: domain.CONTROL},
+		},
 	}
 
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (c *Midi) mux() {
 	for attribute := range c.request {
 		c.handleRequest(attribute)
@@ -121,13 +130,13 @@ func (c *Midi) Listen() error {
 func (c *Midi) Run() error {
 	c.request = make(chan domain.Attribute)
 
-	go func() {
+	c.Go(func() {
 		err := c.Listen()
 		if err != nil {
 			log.Err(err)
 			return
 		}
-	}()
+	})
 
 	return nil
 }
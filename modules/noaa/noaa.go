@@ -12,6 +12,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module NOAA
@@ -32,6 +33,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 const Geomagnetic = "https://nomads.ncep.noaa.gov/pub/data/nccf/com/swmf/prod/swmf.20220930/IMF.dat"
 
 func (n *NOAA) Setup() (plugin.Config, error) {
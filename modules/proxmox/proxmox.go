@@ -3,14 +3,13 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Proxmox
@@ -21,16 +20,39 @@ type Proxmox struct {
 }
 
 func init() {
+	configVariables := []plugin.Variable{
+		{
+			Name:        "hostname",
+			Type:        domain.STRING,
+			Description: "The proxmox host, the 'proxmoxHostname' environment variable is used if it is not set.",
+		},
+		{
+			Name:        "username",
+			Type:        domain.STRING,
+			Description: "The api token id, the 'proxmoxUsername' environment variable is used if it is not set.",
+		},
+		{
+			Name:        "token",
+			Type:        domain.SECRET,
+			Description: "The api token secret, the 'proxmoxToken' environment variable is used if it is not set.",
+		},
+	}
 	config := plugin.Config{
 		Name:        "proxmox",
 		Type:        "module",
 		Description: "A simple proxmox API module",
 		Version:     "0.0.1",
 		Author:      "Braden Nicholson",
+		Variables:   configVariables,
 	}
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 type Network struct {
 	Netout int64 `json:"netout"`
 
@@ -169,46 +191,42 @@ func (p *Proxmox) fetchProxmoxData() (ProxmoxData, error) {
 	return pmd, nil
 }
 
-func (p *Proxmox) authenticatedRequest(endpoint string) ([]byte, error) {
-	hostname := ""
-	found := false
-	if hostname, found = os.LookupEnv("proxmoxHostname"); !found {
-		return []byte{}, fmt.Errorf("hostname env not found")
+// setting returns a configured variable, or the environment variable it replaces if it is not configured
+func (p *Proxmox) setting(key string, env string) (string, error) {
+	value, _ := p.GetConfig(key)
+	if value == "" {
+		value = os.Getenv(env)
 	}
-	baseUrl := fmt.Sprintf(APIFormat, hostname, endpoint)
-	cli, err := http.NewRequest("GET", baseUrl, nil)
+	if value == "" {
+		return "", fmt.Errorf("proxmox %s is not configured", key)
+	}
+	return value, nil
+}
+
+func (p *Proxmox) authenticatedRequest(endpoint string) ([]byte, error) {
+	hostname, err := p.setting("hostname", "proxmoxHostname")
 	if err != nil {
 		return []byte{}, err
 	}
+	baseUrl := fmt.Sprintf(APIFormat, hostname, endpoint)
 
-	username := ""
-	token := ""
-	if username, found = os.LookupEnv("proxmoxUsername"); !found {
-		return []byte{}, fmt.Errorf("hostname env not found")
-	}
-	if token, found = os.LookupEnv("proxmoxToken"); !found {
-		return []byte{}, fmt.Errorf("hostname env not found")
+	username, err := p.setting("username", "proxmoxUsername")
+	if err != nil {
+		return []byte{}, err
 	}
-
-	cli.Header.Set("Authorization", fmt.Sprintf(Authentication, username, token))
-
-	client := http.Client{}
-	defer client.CloseIdleConnections()
-	response, err := client.Do(cli)
-
+	token, err := p.setting("token", "proxmoxToken")
 	if err != nil {
 		return []byte{}, err
 	}
 
-	var res bytes.Buffer
-	_, err = res.ReadFrom(response.Body)
-
+	response, err := p.Web().Get(baseUrl).Header("Authorization", fmt.Sprintf(Authentication, username,
+		token)).Do()
 	if err != nil {
 		return []byte{}, err
 	}
 
-	_ = response.Body.Close()
-	return res.Bytes(), nil
+	return response.Body, nil
+
 }
 
 func (p *Proxmox) Update() error {
@@ -238,11 +256,11 @@ func (p *Proxmox) Run() error {
 
 	mux := make(chan domain.Attribute, 10)
 
-	go func() {
+	p.Go(func() {
 		for range mux {
 
 		}
-	}()
+	})
 
 	err = p.Attributes.Register(&domain.Attribute{
 		Updated:   time.Time{},
@@ -14,6 +14,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 	"udap/internal/pulse"
 )
 
@@ -72,6 +73,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (v *Sentry) connect() error {
 	u := url.URL{Scheme: "ws", Host: sentryUrl, Path: "/ws"}
 
@@ -87,7 +93,7 @@ func (v *Sentry) connect() error {
 		return nil
 	})
 
-	go func() {
+	v.Go(func() {
 		for {
 			if v.session == nil {
 				return
@@ -104,7 +110,7 @@ func (v *Sentry) connect() error {
 				continue
 			}
 		}
-	}()
+	})
 
 	return nil
 }
@@ -396,7 +402,7 @@ func (v *Sentry) Run() error {
 
 	v.eId = e.Id
 
-	go func() {
+	v.Go(func() {
 		for {
 			err = v.listen()
 			if err != nil {
@@ -408,7 +414,7 @@ func (v *Sentry) Run() error {
 				continue
 			}
 		}
-	}()
+	})
 
 	err = v.Attributes.Register(position)
 	if err != nil {
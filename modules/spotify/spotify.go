@@ -3,8 +3,6 @@
 package main
 
 import (
-	"bytes"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi/v5"
@@ -17,6 +15,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Spotify
@@ -29,16 +28,34 @@ type Spotify struct {
 }
 
 func init() {
+	configVariables := []plugin.Variable{
+		{
+			Name:        "client",
+			Type:        domain.STRING,
+			Description: "The client id, the 'spotifyClient' environment variable is used if it is not set.",
+		},
+		{
+			Name:        "secret",
+			Type:        domain.SECRET,
+			Description: "The client secret, the 'spotifySecret' environment variable is used if it is not set.",
+		},
+	}
 	config := plugin.Config{
 		Name:        "spotify",
 		Type:        "module",
 		Description: "Single instance spotify controller",
 		Version:     "2.0.1",
 		Author:      "Braden Nicholson",
+		Variables:   configVariables,
 	}
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (s *Spotify) PutAttribute(key string) func(str string) error {
 	return func(str string) error {
 		switch key {
@@ -326,7 +343,7 @@ func (s *Spotify) Run() error {
 		Entity:  e.Id,
 		Channel: make(chan domain.Attribute),
 	}
-	go func() {
+	s.Go(func() {
 		for attribute := range current.Channel {
 			err := s.PutAttribute(current.Key)(attribute.Request)
 			if err != nil {
@@ -334,7 +351,7 @@ func (s *Spotify) Run() error {
 				return
 			}
 		}
-	}()
+	})
 	err = s.Attributes.Register(current)
 	if err != nil {
 		return err
@@ -347,7 +364,7 @@ func (s *Spotify) Run() error {
 		Entity:  e.Id,
 		Channel: make(chan domain.Attribute),
 	}
-	go func() {
+	s.Go(func() {
 		for attribute := range playing.Channel {
 			err := s.PutAttribute(playing.Key)(attribute.Request)
 			if err != nil {
@@ -355,7 +372,7 @@ func (s *Spotify) Run() error {
 				return
 			}
 		}
-	}()
+	})
 
 	err = s.Attributes.Register(playing)
 	if err != nil {
@@ -369,7 +386,7 @@ func (s *Spotify) Run() error {
 		Entity:  e.Id,
 		Channel: make(chan domain.Attribute),
 	}
-	go func() {
+	s.Go(func() {
 		for attribute := range cmd.Channel {
 			err := s.PutAttribute(cmd.Key)(attribute.Request)
 			if err != nil {
@@ -377,7 +394,7 @@ func (s *Spotify) Run() error {
 				return
 			}
 		}
-	}()
+	})
 
 	err = s.Attributes.Register(cmd)
 	if err != nil {
@@ -446,23 +463,11 @@ func (s *SpotifyApi) analyzeTrack(trackId string) (string, error) {
 func (s *SpotifyApi) authenticatedRequest(method string, path string) (string, error) {
 	access, _ := s.GetToken()
 	urlString := fmt.Sprintf("https://api.spotify.com/v1/%s", path)
-	request, _ := http.NewRequest(method, urlString, nil)
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access))
-	client := http.Client{}
-	defer client.CloseIdleConnections()
-	response, err := client.Do(request)
+	response, err := Module.Web().New(method, urlString, nil).Authorization("Bearer", access).Do()
 	if err != nil {
 		return "", err
 	}
-
-	var buf bytes.Buffer
-	_, err = buf.ReadFrom(response.Body)
-	if err != nil {
-		return "", err
-	}
-
-	_ = response.Body.Close()
-	return buf.String(), nil
+	return string(response.Body), nil
 }
 
 type SpotifyCallback struct {
@@ -489,12 +494,12 @@ func (s *SpotifyApi) Authenticate() {
 		log.Critical("ACTION REQUIRED: %s", s.loginURL())
 		done := make(chan bool)
 		e := make(chan string)
-		go func() {
+		Module.Go(func() {
 			defer func() {
 				done <- true
 			}()
 			s.beginListening(e)
-		}()
+		})
 		msg := <-e
 		s.requestToken(msg)
 		<-done
@@ -540,10 +545,19 @@ func (s *SpotifyApi) beginListening(e chan string) {
 	}
 }
 
+// setting returns a configured variable, or the environment variable it replaces if it is not configured
+func (s *Spotify) setting(key string, env string) string {
+	value, _ := s.GetConfig(key)
+	if value == "" {
+		return os.Getenv(env)
+	}
+	return value
+}
+
 func (s *SpotifyApi) loginURL() string {
 
 	scope := "user-modify-playback-state user-read-currently-playing user-read-playback-state"
-	clientId := os.Getenv("spotifyClient")
+	clientId := Module.setting("client", "spotifyClient")
 
 	urlString := fmt.Sprintf("https://accounts.spotify."+
 		"com/authorize?response_type=%s&client_id=%s&scope=%s&redirect_uri=%s",
@@ -603,40 +617,15 @@ type SpotifyRefresh struct {
 
 func (s *SpotifyApi) basicRequest(path string, values url.Values) (string, error) {
 
-	id := os.Getenv("spotifyClient")
-	secret := os.Getenv("spotifySecret")
-
-	base := fmt.Sprintf("%s:%s", id, secret)
-	encoded := base64.StdEncoding.EncodeToString([]byte(base))
+	id := Module.setting("client", "spotifyClient")
+	secret := Module.setting("secret", "spotifySecret")
 
-	var buf bytes.Buffer
-	body := values.Encode()
-	buf.Write([]byte(body))
-
-	request, err := http.NewRequest("POST", path, &buf)
+	response, err := Module.Web().Post(path, values.Encode()).
+		Header("Content-Type", "application/x-www-form-urlencoded").
+		BasicAuth(id, secret).Do()
 	if err != nil {
 		return "", err
 	}
 
-	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	request.Header.Set("Authorization", fmt.Sprintf("Basic %s", encoded))
-
-	request.PostForm = values
-	client := http.Client{}
-	defer client.CloseIdleConnections()
-	response, err := client.Do(request)
-	if err != nil {
-		return "", err
-	}
-
-	var res bytes.Buffer
-
-	_, err = res.ReadFrom(response.Body)
-	if err != nil {
-		return res.String(), err
-	}
-
-	_ = response.Body.Close()
-
-	return res.String(), nil
+	return string(response.Body), nil
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Squid
@@ -57,6 +58,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (s *Squid) setChannelValue(channel int, value int) (err error) {
 	if value > 100 || value < 0 {
 		return fmt.Errorf("desired value '%d' is invalid", value)
@@ -313,7 +319,7 @@ func (s *Squid) Run() (err error) {
 	if err != nil {
 		return err
 	}
-	go s.mux()
+	s.Go(s.mux)
 	s.connected = true
 	return nil
 }
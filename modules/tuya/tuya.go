@@ -21,6 +21,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Tuya
@@ -680,22 +681,22 @@ func (t *Tuya) Scan() error {
 
 	defer pc.Close()
 
-	go func() {
+	t.Go(func() {
 		err = t.lightMux()
 		if err != nil {
 			return
 		}
-	}()
+	})
 
 	buf := make([]byte, 256)
-	go func() {
+	t.Go(func() {
 		<-t.done
 		err = pc.Close()
 		if err != nil {
 			return
 		}
 		close(t.ping)
-	}()
+	})
 	/*	err = pc.SetReadDeadline(time.Now().Add(timeout))
 		if err != nil {
 			return err
@@ -759,6 +760,11 @@ func init() {
 	}
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (t *Tuya) Setup() (plugin.Config, error) {
 	err := t.UpdateInterval(1000 * 5)
 	if err != nil {
@@ -831,13 +837,14 @@ func (t *Tuya) updateAll() error {
 	wg := sync.WaitGroup{}
 	wg.Add(len(t.Lights))
 	for _, l := range t.Lights {
-		go func(light *Light) {
+		light := l
+		t.Go(func() {
 			defer wg.Done()
 			err := t.updateOne(light)
 			if err != nil {
 				return
 			}
-		}(l)
+		})
 	}
 
 	wg.Wait()
@@ -1017,12 +1024,12 @@ func (t *Tuya) mux(index int) error {
 		}
 		select {
 		case attr := <-t.receiver:
-			go func() {
+			t.Go(func() {
 				err := t.handleRequest(attr)
 				if err != nil {
 					log.Err(err)
 				}
-			}()
+			})
 			break
 		}
 	}
@@ -1114,12 +1121,12 @@ func (t *Tuya) registerLight(light *Light) error {
 
 func (t *Tuya) runScan() error {
 
-	go func() {
+	t.Go(func() {
 		err := t.Scan()
 		if err != nil {
 			log.Err(err)
 		}
-	}()
+	})
 
 	t.ready = true
 	return nil
@@ -1130,7 +1137,7 @@ func (t *Tuya) Run() error {
 
 	t.receiver = make(chan domain.Attribute, 8)
 
-	go func() {
+	t.Go(func() {
 		for {
 			err := t.mux(0)
 			if err != nil {
@@ -1139,14 +1146,14 @@ func (t *Tuya) Run() error {
 				continue
 			}
 		}
-	}()
+	})
 
-	go func() {
+	t.Go(func() {
 		err := t.runScan()
 		if err != nil {
 			return
 		}
-	}()
+	})
 
 	return nil
 }
@@ -21,6 +21,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Vyos
@@ -91,12 +92,12 @@ func (r *RemoteSocket) connect(w http.ResponseWriter, req *http.Request) {
 }
 
 func (r *RemoteSocket) Run() error {
-	go func() {
+	Module.Go(func() {
 		err := r.server.ListenAndServe()
 		if err != nil {
 			return
 		}
-	}()
+	})
 
 	for {
 		select {
@@ -130,6 +131,7 @@ func init() {
 			Name:        "router",
 			Default:     "10.0.1.1",
 			Description: "The address to a vyOS router with api access enabled.",
+			Required:    true,
 		},
 	}
 	config := plugin.Config{
@@ -143,6 +145,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (v *Vyos) sendPing(ip string) error {
 	// Resolve any DNS (if used) and get the real IP of the target
 	dst, err := net.ResolveIPAddr("ip4", ip)
@@ -622,14 +629,14 @@ func (v *Vyos) fetchNetworks() error {
 		}
 		v.networks[network.Id] = network
 		wg.Add(1)
-		go func() {
+		v.Go(func() {
 			defer wg.Done()
 
 			err = v.arpScan(network)
 			if err != nil {
 				log.Err(err)
 			}
-		}()
+		})
 
 	}
 
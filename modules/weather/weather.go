@@ -3,14 +3,13 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Weather
@@ -108,6 +107,11 @@ func init() {
 	Module.eId = ""
 	Module.Config = config
 }
+
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
 func (v *Weather) currentBuffer() (string, error) {
 
 	index := time.Now().Hour()
@@ -155,36 +159,14 @@ func (v *Weather) fetchWeather() error {
 		return fmt.Errorf("WEATHER: env weatherLat not set")
 	}
 
-	request, err := http.NewRequest("GET", fmt.Sprintf(weatherUrl, lat, lon), nil)
-	if err != nil {
-		return err
-	}
-
-	cli := http.Client{}
-	cli.Timeout = time.Second * 5
-	defer cli.CloseIdleConnections()
-
-	do, err := cli.Do(request)
-	if err != nil {
-		return err
-	}
-
-	var buf bytes.Buffer
-	_, err = buf.ReadFrom(do.Body)
-	if err != nil {
-		return err
-	}
-
 	w := WeatherAPI{}
-	err = json.Unmarshal(buf.Bytes(), &w)
+	err := v.Web().Get(fmt.Sprintf(weatherUrl, lat, lon)).Timeout(time.Second * 5).JSON(&w)
 	if err != nil {
 		return err
 	}
 
 	v.forecast = w
 
-	_ = do.Body.Close()
-
 	return nil
 
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 	"udap/internal/core/domain"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module WebPush
@@ -22,10 +23,18 @@ func init() {
 		Version:     "1.0.0",
 		Author:      "Braden Nicholson",
 		Interval:    time.Second * 5,
+		Grants: []plugin.Grant{
+			{Capability: domain.ENDPOINTS},
+		},
 	}
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 type WebPush struct {
 	plugin.Module
 	entityId string
@@ -106,7 +115,9 @@ func (w *WebPush) handleMux() {
 	for {
 		select {
 		case attribute := <-w.mux:
-			go w.handleNotifyRequest(attribute)
+			w.Go(func() {
+				w.handleNotifyRequest(attribute)
+			})
 		}
 	}
 }
@@ -114,7 +125,7 @@ func (w *WebPush) handleMux() {
 func (w *WebPush) Run() error {
 	w.mux = make(chan domain.Attribute, 10)
 
-	go w.handleMux()
+	w.Go(w.handleMux)
 
 	entity := domain.Entity{
 		Name:   "webpush",
@@ -5,6 +5,7 @@ package main
 import (
 	"time"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module WebStats
@@ -25,6 +26,11 @@ func init() {
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (w *WebStats) Setup() (plugin.Config, error) {
 	err := w.UpdateInterval(2000)
 	if err != nil {
@@ -14,6 +14,7 @@ import (
 	"udap/internal/core/domain"
 	"udap/internal/log"
 	"udap/internal/plugin"
+	"udap/internal/plugin/process"
 )
 
 var Module Worldspace
@@ -68,10 +69,19 @@ func init() {
 		Version:     "0.1.2",
 		Author:      "Braden Nicholson",
 		Interval:    time.Second,
+		Grants: []plugin.Grant{
+			// Triggers are fired by name from requests made to the worldspace server
+			{Capability: domain.FIRE},
+		},
 	}
 	Module.Config = config
 }
 
+// main serves the module when it is run with the process runtime
+func main() {
+	process.Serve(&Module)
+}
+
 func (w *Worldspace) Setup() (plugin.Config, error) {
 	err := w.UpdateInterval(10000)
 	if err != nil {
@@ -288,7 +298,7 @@ func (w *Worldspace) endpointTrigger(router chi.Router, name string, desc string
 
 	w.timers[fmt.Sprintf("ws-%s", name)] = time.NewTimer(time.Hour * 128)
 
-	go func() {
+	w.Go(func() {
 		for {
 			select {
 			case <-w.timers[fmt.Sprintf("ws-%s", name)].C:
@@ -297,7 +307,7 @@ func (w *Worldspace) endpointTrigger(router chi.Router, name string, desc string
 				}
 			}
 		}
-	}()
+	})
 
 	err := w.Triggers.Register(&domain.Trigger{
 		Name:        fmt.Sprintf("ws-%s", name),
@@ -407,7 +417,7 @@ func (w *Worldspace) Run() error {
 		Channel: path,
 	}
 
-	go func() {
+	w.Go(func() {
 		for attribute := range path {
 			parseInt, err := strconv.Atoi(attribute.Request)
 			if err != nil {
@@ -430,18 +440,18 @@ func (w *Worldspace) Run() error {
 				continue
 			}
 		}
-	}()
+	})
 
 	err = w.Attributes.Register(dim)
 	if err != nil {
 		return err
 	}
-	go func() {
+	w.Go(func() {
 		err = w.server.ListenAndServe()
 		if err != nil {
 			return
 		}
-	}()
+	})
 	return nil
 }
 
@@ -52,6 +52,19 @@ func AuthToken(token string) (string, error) {
 	return s, nil
 }
 
+// Identity returns the id signed into the token of an authenticated request
+func Identity(r *http.Request) string {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return ""
+	}
+	id, ok := claims["id"].(string)
+	if !ok {
+		return ""
+	}
+	return id
+}
+
 func VerifyToken() func(http.Handler) http.Handler {
 	return jwtauth.Verifier(tokenAuth)
 }
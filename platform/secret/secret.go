@@ -0,0 +1,89 @@
+// Copyright (c) 2022 Braden Nicholson
+
+// Package secret encrypts values that must not be stored in plaintext, such as the api keys held in module
+// configuration.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// prefix marks a value as sealed
+const prefix = "sealed:"
+
+// key derives the encryption key from the 'secretKey' environment variable, falling back to the jwt key
+func key() ([]byte, error) {
+	material := os.Getenv("secretKey")
+	if material == "" {
+		material = os.Getenv("private")
+	}
+	if material == "" {
+		return nil, fmt.Errorf("no key is configured for secrets")
+	}
+	sum := sha256.Sum256([]byte(material))
+	return sum[:], nil
+}
+
+func aead() (cipher.AEAD, error) {
+	k, err := key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Sealed reports whether a value was produced by Seal
+func Sealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Seal encrypts a value
+func Seal(value string) (string, error) {
+	gcm, err := aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(value), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value, values that are not sealed are returned as they are
+func Open(value string) (string, error) {
+	if !Sealed(value) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", err
+	}
+	gcm, err := aead()
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("sealed value is malformed")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("sealed value could not be opened: %s", err.Error())
+	}
+	return string(plain), nil
+}
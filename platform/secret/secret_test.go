@@ -0,0 +1,30 @@
+// Copyright (c) 2022 Braden Nicholson
+
+package secret
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	_ = os.Setenv("secretKey", "test")
+	sealed, err := Seal("api-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Sealed(sealed) || sealed == "api-key" {
+		t.Errorf("value was not sealed: %s", sealed)
+	}
+	opened, err := Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened != "api-key" {
+		t.Errorf("Open() = %s, want api-key", opened)
+	}
+	plain, err := Open("plain")
+	if err != nil || plain != "plain" {
+		t.Errorf("Open() should return unsealed values as they are")
+	}
+}